@@ -0,0 +1,233 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "sync"
+
+// Key for the original timestamp of entries replayed by Breadcrumbs.
+const _breadcrumbTimeKey = "breadcrumbTime"
+
+// Breadcrumbs wraps a logger so that entries below the supplied level are held
+// in memory rather than written, and are only written if an Error-level (or
+// higher) entry follows: that is, when there's a failure for them to explain.
+// Entries at or above the level, but below Error, are written immediately.
+// For example, wrapping a Debug-level logger with InfoLevel writes Info and
+// Warn entries as usual, but writes the Debug entries leading up to each
+// error just before the error itself.
+//
+// The held entries are kept in a ring buffer of the supplied size, so only the
+// most recent entries are written; a size of zero or less keeps none. Entries
+// that the underlying logger wouldn't write aren't held. When an Error-level
+// entry flushes the buffer, the held entries are written in order, each with
+// its original time under the "breadcrumbTime" key, and the buffer is
+// emptied.
+//
+// Since Go doesn't expose goroutine identity, buffers are scoped to loggers:
+// the returned logger and all the children created with its With method
+// share one buffer, and an error logged by any of them flushes the entries
+// logged by all of them. To keep each request's breadcrumbs separate, wrap
+// the logger once per request (e.g., in middleware), and discard it when the
+// request completes.
+//
+// Panic and Fatal always panic and exit, after flushing the buffer.
+func Breadcrumbs(log Logger, lvl Level, size int) Logger {
+	return &breadcrumbLogger{
+		log:    log,
+		lvl:    lvl,
+		crumbs: newCrumbBuffer(size),
+	}
+}
+
+type breadcrumb struct {
+	log    Logger
+	lvl    Level
+	msg    string
+	fields []Field
+	time   Field
+}
+
+// crumbBuffer is a fixed-size ring buffer of breadcrumbs.
+type crumbBuffer struct {
+	sync.Mutex
+	crumbs []breadcrumb
+	// Index of the oldest breadcrumb, and the number held.
+	head, n int
+}
+
+func newCrumbBuffer(size int) *crumbBuffer {
+	if size < 0 {
+		size = 0
+	}
+	return &crumbBuffer{crumbs: make([]breadcrumb, size)}
+}
+
+func (b *crumbBuffer) add(c breadcrumb) {
+	if len(b.crumbs) == 0 {
+		return
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.n < len(b.crumbs) {
+		b.crumbs[(b.head+b.n)%len(b.crumbs)] = c
+		b.n++
+		return
+	}
+	// Overwrite the oldest breadcrumb.
+	b.crumbs[b.head] = c
+	b.head = (b.head + 1) % len(b.crumbs)
+}
+
+// drain empties the buffer, returning the held breadcrumbs from oldest to
+// newest.
+func (b *crumbBuffer) drain() []breadcrumb {
+	if len(b.crumbs) == 0 {
+		return nil
+	}
+	b.Lock()
+	defer b.Unlock()
+	drained := make([]breadcrumb, 0, b.n)
+	for i := 0; i < b.n; i++ {
+		idx := (b.head + i) % len(b.crumbs)
+		drained = append(drained, b.crumbs[idx])
+		// Don't retain loggers and fields after they're written.
+		b.crumbs[idx] = breadcrumb{}
+	}
+	b.head, b.n = 0, 0
+	return drained
+}
+
+type breadcrumbLogger struct {
+	log    Logger
+	lvl    Level
+	crumbs *crumbBuffer
+}
+
+func (b *breadcrumbLogger) With(fields ...Field) Logger {
+	return &breadcrumbLogger{
+		log:    b.log.With(fields...),
+		lvl:    b.lvl,
+		crumbs: b.crumbs,
+	}
+}
+
+func (b *breadcrumbLogger) Check(lvl Level, msg string) *CheckedMessage {
+	switch lvl {
+	case PanicLevel, FatalLevel:
+		// Like Tee, make sure that Write calls our Panic and Fatal methods.
+		return NewCheckedMessage(b, lvl, msg)
+	}
+	switch {
+	case lvl < b.lvl:
+		// Make sure that Write holds the entry. Don't check the underlying
+		// logger, since held entries are checked when they're flushed.
+		if enabled(b.log, lvl) {
+			return NewCheckedMessage(b, lvl, msg)
+		}
+		return nil
+	case lvl < ErrorLevel:
+		return b.log.Check(lvl, msg)
+	}
+	// Flush on Write, then write through the underlying logger's
+	// CheckedMessage.
+	return wrapCheckedMessage(b, b.log.Check(lvl, msg), func(cm *CheckedMessage, fields []Field) {
+		b.flush(lvl)
+		cm.Write(fields...)
+	})
+}
+
+func (b *breadcrumbLogger) Log(lvl Level, msg string, fields ...Field) {
+	if b.hold(lvl, msg, fields) {
+		return
+	}
+	b.flush(lvl)
+	b.log.Log(lvl, msg, fields...)
+}
+
+func (b *breadcrumbLogger) Debug(msg string, fields ...Field) {
+	if !b.hold(DebugLevel, msg, fields) {
+		b.log.Debug(msg, fields...)
+	}
+}
+
+func (b *breadcrumbLogger) Info(msg string, fields ...Field) {
+	if !b.hold(InfoLevel, msg, fields) {
+		b.log.Info(msg, fields...)
+	}
+}
+
+func (b *breadcrumbLogger) Warn(msg string, fields ...Field) {
+	if !b.hold(WarnLevel, msg, fields) {
+		b.log.Warn(msg, fields...)
+	}
+}
+
+func (b *breadcrumbLogger) Error(msg string, fields ...Field) {
+	b.flush(ErrorLevel)
+	b.log.Error(msg, fields...)
+}
+
+func (b *breadcrumbLogger) Panic(msg string, fields ...Field) {
+	b.flush(PanicLevel)
+	b.log.Log(PanicLevel, msg, fields...)
+	panic(msg)
+}
+
+func (b *breadcrumbLogger) Fatal(msg string, fields ...Field) {
+	b.flush(FatalLevel)
+	b.log.Log(FatalLevel, msg, fields...)
+	_exit(1)
+}
+
+func (b *breadcrumbLogger) DFatal(msg string, fields ...Field) {
+	// DFatal logs at Error or Fatal, both of which flush.
+	b.flush(ErrorLevel)
+	b.log.DFatal(msg, fields...)
+}
+
+// hold buffers entries below the logger's level, reporting whether the entry
+// was consumed. Entries are held if the underlying logger's level allows
+// them; they aren't checked (which could, e.g., spend a sampler's budget)
+// until they're flushed.
+func (b *breadcrumbLogger) hold(lvl Level, msg string, fields []Field) bool {
+	if lvl >= b.lvl {
+		return false
+	}
+	if enabled(b.log, lvl) {
+		b.crumbs.add(breadcrumb{
+			log:    b.log,
+			lvl:    lvl,
+			msg:    msg,
+			fields: append([]Field(nil), fields...),
+			time:   Time(_breadcrumbTimeKey, _timeNow()),
+		})
+	}
+	return true
+}
+
+// flush writes the held entries if the level is high enough.
+func (b *breadcrumbLogger) flush(lvl Level) {
+	if lvl < ErrorLevel {
+		return
+	}
+	for _, c := range b.crumbs.drain() {
+		c.log.Log(c.lvl, c.msg, append(c.fields, c.time)...)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreadcrumbs(t *testing.T) {
+	defer stubNow(time.Second)()
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		log := Breadcrumbs(logger, InfoLevel, 10)
+		log.Debug("connecting", Int("attempt", 1))
+		log.With(String("user", "alice")).Debug("authenticated")
+		log.Info("request")
+		log.Warn("slow")
+		assert.Equal(t, []string{
+			`{"level":"info","msg":"request"}`,
+			`{"level":"warn","msg":"slow"}`,
+		}, buf.Lines(), "Expected Debug entries to be held.")
+
+		buf.Reset()
+		log.Error("failed")
+		assert.Equal(t, []string{
+			`{"level":"debug","msg":"connecting","attempt":1,"breadcrumbTime":1}`,
+			`{"level":"debug","msg":"authenticated","user":"alice","breadcrumbTime":1}`,
+			`{"level":"error","msg":"failed"}`,
+		}, buf.Lines(), "Expected held entries to be written before the error.")
+
+		buf.Reset()
+		log.Error("failed again")
+		assert.Equal(t, []string{`{"level":"error","msg":"failed again"}`}, buf.Lines(), "Expected the buffer to be emptied.")
+	})
+}
+
+func TestBreadcrumbsRingBuffer(t *testing.T) {
+	defer stubNow(0)()
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		log := Breadcrumbs(logger, InfoLevel, 2)
+		for i := 0; i < 5; i++ {
+			log.Debug("step", Int("i", i))
+		}
+		log.Log(ErrorLevel, "failed")
+		assert.Equal(t, []string{
+			`{"level":"debug","msg":"step","i":3,"breadcrumbTime":0}`,
+			`{"level":"debug","msg":"step","i":4,"breadcrumbTime":0}`,
+			`{"level":"error","msg":"failed"}`,
+		}, buf.Lines(), "Expected only the most recent entries to be kept.")
+	})
+}
+
+func TestBreadcrumbsSizes(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+			log := Breadcrumbs(logger, WarnLevel, size)
+			log.Info("dropped")
+			log.Error("failed")
+			assert.Equal(t, []string{`{"level":"error","msg":"failed"}`}, buf.Lines(), "Expected no entries to be held with size %v.", size)
+		})
+	}
+}
+
+func TestBreadcrumbsSkipsDisabledLevels(t *testing.T) {
+	defer stubNow(0)()
+	withJSONLogger(t, opts(InfoLevel), func(logger Logger, buf *testBuffer) {
+		log := Breadcrumbs(logger, WarnLevel, 1)
+		log.Info("held")
+		log.Debug("disabled")
+		log.Error("failed")
+		assert.Equal(t, []string{
+			`{"level":"info","msg":"held","breadcrumbTime":0}`,
+			`{"level":"error","msg":"failed"}`,
+		}, buf.Lines(), "Expected disabled entries not to displace held ones.")
+	})
+}
+
+func TestBreadcrumbsCheck(t *testing.T) {
+	defer stubNow(0)()
+	withJSONLogger(t, opts(InfoLevel), func(logger Logger, buf *testBuffer) {
+		log := Breadcrumbs(logger, WarnLevel, 10)
+		assert.False(t, log.Check(DebugLevel, "disabled").OK(), "Expected disabled levels to stay disabled.")
+		log.Check(InfoLevel, "held").Write(Int("n", 1))
+		log.Check(WarnLevel, "written").Write()
+		assert.Equal(t, []string{`{"level":"warn","msg":"written"}`}, buf.Lines(), "Expected Info entries to be held.")
+
+		buf.Reset()
+		log.Check(ErrorLevel, "failed").Write()
+		assert.Equal(t, []string{
+			`{"level":"info","msg":"held","n":1,"breadcrumbTime":0}`,
+			`{"level":"error","msg":"failed"}`,
+		}, buf.Lines(), "Expected checked errors to flush held entries.")
+	})
+}
+
+func TestBreadcrumbsPanicAndFatal(t *testing.T) {
+	defer stubNow(0)()
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		log := Breadcrumbs(logger, InfoLevel, 10)
+		log.Debug("before panic")
+		assert.Panics(t, func() { log.Panic("boom") }, "Expected Panic to panic.")
+		assert.Equal(t, []string{
+			`{"level":"debug","msg":"before panic","breadcrumbTime":0}`,
+			`{"level":"panic","msg":"boom"}`,
+		}, buf.Lines(), "Expected Panic to flush held entries.")
+
+		buf.Reset()
+		stub := stubExit()
+		defer stub.Unstub()
+		log.Debug("before fatal")
+		log.Fatal("crash")
+		stub.AssertStatus(t, 1)
+		assert.Equal(t, []string{
+			`{"level":"debug","msg":"before fatal","breadcrumbTime":0}`,
+			`{"level":"fatal","msg":"crash"}`,
+		}, buf.Lines(), "Expected Fatal to flush held entries.")
+
+		buf.Reset()
+		log.Debug("before dfatal")
+		log.DFatal("oops")
+		assert.Equal(t, []string{
+			`{"level":"debug","msg":"before dfatal","breadcrumbTime":0}`,
+			`{"level":"error","msg":"oops"}`,
+		}, buf.Lines(), "Expected DFatal to flush held entries.")
+	})
+}
+
+func TestBreadcrumbsSeparateScopes(t *testing.T) {
+	defer stubNow(0)()
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		first := Breadcrumbs(logger, InfoLevel, 10)
+		second := Breadcrumbs(logger, InfoLevel, 10)
+		first.Debug("first request")
+		second.Debug("second request")
+		second.Error("failed")
+		assert.Equal(t, []string{
+			`{"level":"debug","msg":"second request","breadcrumbTime":0}`,
+			`{"level":"error","msg":"failed"}`,
+		}, buf.Lines(), "Expected each wrapper to have its own buffer.")
+	})
+}
+
+func TestBreadcrumbsConcurrent(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		log := Breadcrumbs(logger, InfoLevel, 16)
+		var wg sync.WaitGroup
+		runConcurrently(5 /* goroutines */, 50 /* iterations */, &wg, func() {
+			log.Debug("step")
+			log.Error("failed")
+		})
+		wg.Wait()
+		assert.Equal(t, 500, len(buf.Lines()), "Expected every entry to be written once.")
+	})
+}
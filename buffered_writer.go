@@ -0,0 +1,141 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"time"
+)
+
+// A BufferedWriteSyncer batches writes in memory, which trades durability for
+// throughput. Buffered data is written to the underlying WriteSyncer when the
+// buffer fills, when the flush interval elapses, and when Sync or Close is
+// called.
+//
+// To buffer routine entries while writing important ones durably, combine it
+// with the SyncLevel option: the logger syncs its output (flushing the buffer,
+// including any earlier entries, in order) immediately after writing each
+// entry at or above the configured level.
+//
+//	ws := zap.NewBufferedWriteSyncer(file, 256*1024, time.Second)
+//	logger := zap.New(enc, zap.Output(ws), zap.SyncLevel(zap.ErrorLevel))
+//	defer zap.Close(logger)
+//
+// Unless the flush interval is zero or negative, the BufferedWriteSyncer runs
+// a background goroutine; call Close (or Close the logger) to stop it and
+// flush any remaining data.
+type BufferedWriteSyncer struct {
+	mu   sync.Mutex
+	ws   WriteSyncer
+	buf  []byte
+	size int
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewBufferedWriteSyncer creates a BufferedWriteSyncer that buffers up to
+// size bytes and flushes at least once per interval.
+func NewBufferedWriteSyncer(ws WriteSyncer, size int, interval time.Duration) *BufferedWriteSyncer {
+	b := &BufferedWriteSyncer{
+		ws:   ws,
+		buf:  make([]byte, 0, size),
+		size: size,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if interval > 0 {
+		go b.flushLoop(interval)
+	} else {
+		close(b.done)
+	}
+	return b
+}
+
+// Write buffers the data, flushing first if it wouldn't fit. Writes at least
+// as large as the buffer bypass it.
+func (b *BufferedWriteSyncer) Write(bs []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.buf)+len(bs) > b.size {
+		if err := b.flush(); err != nil {
+			return 0, err
+		}
+	}
+	if len(bs) >= b.size {
+		return b.ws.Write(bs)
+	}
+	b.buf = append(b.buf, bs...)
+	return len(bs), nil
+}
+
+// Sync flushes any buffered data and syncs the underlying WriteSyncer.
+func (b *BufferedWriteSyncer) Sync() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.flush(); err != nil {
+		return err
+	}
+	return b.ws.Sync()
+}
+
+// Close stops the background flushes, flushes any buffered data, and syncs
+// the underlying WriteSyncer, closing it if it's an io.Closer other than a
+// file. It's safe to call Close more than once.
+func (b *BufferedWriteSyncer) Close() error {
+	b.once.Do(func() { close(b.stop) })
+	<-b.done
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.flush(); err != nil {
+		return err
+	}
+	return closeSyncer(b.ws)
+}
+
+func (b *BufferedWriteSyncer) flushLoop(interval time.Duration) {
+	defer close(b.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.mu.Lock()
+			b.flush()
+			b.mu.Unlock()
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// flush writes the buffered data. The caller must hold the lock.
+func (b *BufferedWriteSyncer) flush() error {
+	if len(b.buf) == 0 {
+		return nil
+	}
+	_, err := b.ws.Write(b.buf)
+	b.buf = b.buf[:0]
+	return err
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/uber-go/zap/spywrite"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferedWriteSyncer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ws := NewBufferedWriteSyncer(AddSync(buf), 8, 0)
+
+	ws.Write([]byte("abc"))
+	ws.Write([]byte("def"))
+	assert.Equal(t, "", buf.String(), "Expected writes to be buffered.")
+
+	ws.Write([]byte("ghi"))
+	assert.Equal(t, "abcdef", buf.String(), "Expected a full buffer to be flushed.")
+
+	ws.Write([]byte("0123456789"))
+	assert.Equal(t, "abcdefghi0123456789", buf.String(), "Expected large writes to bypass the buffer.")
+
+	ws.Write([]byte("jkl"))
+	assert.NoError(t, ws.Sync(), "Unexpected error syncing.")
+	assert.Equal(t, "abcdefghi0123456789jkl", buf.String(), "Expected Sync to flush the buffer.")
+
+	ws.Write([]byte("mno"))
+	assert.NoError(t, ws.Close(), "Unexpected error closing.")
+	assert.NoError(t, ws.Close(), "Expected Close to be idempotent.")
+	assert.Equal(t, "abcdefghi0123456789jklmno", buf.String(), "Expected Close to flush the buffer.")
+}
+
+func TestBufferedWriteSyncerInterval(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ws := NewBufferedWriteSyncer(AddSync(buf), 1024, time.Millisecond)
+	defer ws.Close()
+
+	ws.Write([]byte("hello"))
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		ws.mu.Lock()
+		flushed := buf.String()
+		ws.mu.Unlock()
+		if flushed == "hello" {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("Expected buffer to be flushed periodically.")
+}
+
+func TestBufferedWriteSyncerSyncLevel(t *testing.T) {
+	sink := &spywrite.WriteSyncer{Writer: &bytes.Buffer{}}
+	ws := NewBufferedWriteSyncer(sink, 1024, 0)
+	logger := New(NewJSONEncoder(NoTime()), Output(ws), SyncLevel(ErrorLevel))
+
+	logger.Info("routine")
+	assert.Equal(t, "", sink.Writer.(*bytes.Buffer).String(), "Expected Info entries to be buffered.")
+	assert.False(t, sink.Called(), "Expected no sync after Info entries.")
+
+	logger.Error("important")
+	assert.Equal(
+		t,
+		`{"level":"info","msg":"routine"}`+"\n"+`{"level":"error","msg":"important"}`+"\n",
+		sink.Writer.(*bytes.Buffer).String(),
+		"Expected Error entries to flush the buffer in order.",
+	)
+	assert.True(t, sink.Called(), "Expected Error entries to sync the output.")
+}
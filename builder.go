@@ -0,0 +1,71 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "errors"
+
+var errNoLoggers = errors.New("can't build a logger without any sub-loggers")
+
+// A Builder assembles a Tee from loggers whose construction may fail, such as
+// loggers writing to files or network endpoints. Rather than handling each
+// error as it occurs, callers add constructors to the Builder and check for
+// errors once, when calling Build. This is particularly convenient when
+// building loggers from configuration:
+//
+//	var b zap.Builder
+//	for _, cfg := range configs {
+//		cfg := cfg
+//		b.Add(func() (zap.Logger, error) { return newLoggerFromConfig(cfg) })
+//	}
+//	logger, err := b.Build()
+//
+// The zero value is ready to use. Builders aren't safe for concurrent use.
+type Builder struct {
+	logs []Logger
+	errs multiError
+}
+
+// Add runs the supplied constructor, recording the resulting logger or error.
+// It returns the Builder to allow chaining.
+func (b *Builder) Add(fn func() (Logger, error)) *Builder {
+	log, err := fn()
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	b.logs = append(b.logs, log)
+	return b
+}
+
+// Build returns a Tee of all the successfully-constructed loggers. If any
+// constructor failed, Build instead closes the loggers that were constructed
+// (see Close) and returns an error describing every failure. It's also an
+// error to build a logger without adding any constructors.
+func (b *Builder) Build() (Logger, error) {
+	if len(b.errs) > 0 {
+		closeLoggers(b.logs)
+		return nil, b.errs
+	}
+	if len(b.logs) == 0 {
+		return nil, errNoLoggers
+	}
+	return Tee(b.logs...), nil
+}
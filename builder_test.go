@@ -0,0 +1,65 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	buf1, buf2 := &testBuffer{}, &testBuffer{}
+	var b Builder
+	log, err := b.
+		Add(func() (Logger, error) { return New(newJSONEncoder(NoTime()), Output(buf1)), nil }).
+		Add(func() (Logger, error) { return New(newJSONEncoder(NoTime()), Output(buf2)), nil }).
+		Build()
+	require.NoError(t, err, "Unexpected error building logger.")
+
+	log.Info("both")
+	for _, buf := range []*testBuffer{buf1, buf2} {
+		assert.Equal(t, `{"level":"info","msg":"both"}`, buf.Stripped(), "Expected output from each sub-logger.")
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	out := &closeSpy{}
+	var b Builder
+	b.Add(func() (Logger, error) { return nil, errors.New("no file") })
+	b.Add(func() (Logger, error) { return New(NullEncoder(), Output(out)), nil })
+	b.Add(func() (Logger, error) { return nil, errors.New("no network") })
+
+	log, err := b.Build()
+	assert.Nil(t, log, "Expected a nil logger on failure.")
+	require.Error(t, err, "Expected construction errors to be reported.")
+	assert.Contains(t, err.Error(), "no file", "Expected the first failure in the error.")
+	assert.Contains(t, err.Error(), "no network", "Expected the second failure in the error.")
+	assert.Equal(t, 1, out.closed, "Expected successfully-built loggers to be closed.")
+}
+
+func TestBuilderEmpty(t *testing.T) {
+	var b Builder
+	_, err := b.Build()
+	assert.Equal(t, errNoLoggers, err, "Expected an error building without any loggers.")
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// errEntryDropped signals that the ByteLimiter dropped an entry. Loggers don't
+// report it, but encoders that track what they've written (like the signed
+// encoder) see a failed write.
+var errEntryDropped = errors.New("entry dropped by ByteLimiter")
+
+// A ByteLimiter caps the volume of log output in bytes per second, which keeps
+// the cost of metered log egress predictable. It measures the encoded size of
+// each entry and tracks its budget with a token bucket that holds one second's
+// worth of bytes.
+//
+// When the budget runs low, the least severe entries are dropped first: Debug
+// entries (and those at custom levels below Debug) may only spend the top half
+// of the bucket, Info entries the top
+// three quarters, and Warn entries the top seven eighths, leaving the rest for
+// more important entries. Error entries may spend the whole bucket. Panic and
+// Fatal entries are always written, since they may be the last the process
+// produces, but still count against the budget.
+//
+// ByteLimiters implement the Option interface. A single limiter may be shared
+// by several loggers to enforce a combined budget.
+type ByteLimiter struct {
+	mu       sync.Mutex
+	capacity float64
+	rate     float64
+	tokens   float64
+	last     time.Time
+	dropped  map[Level]uint64
+}
+
+// NewByteLimiter creates a ByteLimiter that allows, on average, the supplied
+// number of bytes per second.
+func NewByteLimiter(bytesPerSecond int) *ByteLimiter {
+	return &ByteLimiter{
+		capacity: float64(bytesPerSecond),
+		rate:     float64(bytesPerSecond),
+		tokens:   float64(bytesPerSecond),
+		last:     _timeNow(),
+		dropped:  make(map[Level]uint64),
+	}
+}
+
+// apply implements the Option interface.
+func (l *ByteLimiter) apply(m *Meta) {
+	m.ByteLimiter = l
+}
+
+// Dropped returns the total number of entries dropped by the limiter.
+func (l *ByteLimiter) Dropped() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var total uint64
+	for _, n := range l.dropped {
+		total += n
+	}
+	return total
+}
+
+// DroppedByLevel returns the number of entries dropped at each level.
+func (l *ByteLimiter) DroppedByLevel() map[Level]uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	counts := make(map[Level]uint64, len(l.dropped))
+	for lvl, n := range l.dropped {
+		counts[lvl] = n
+	}
+	return counts
+}
+
+// reserve returns the number of tokens that entries at the given level must
+// leave in the bucket. Custom levels below Debug are treated like Debug.
+func (l *ByteLimiter) reserve(lvl Level) float64 {
+	switch {
+	case lvl <= DebugLevel:
+		return l.capacity / 2
+	case lvl == InfoLevel:
+		return l.capacity / 4
+	case lvl == WarnLevel:
+		return l.capacity / 8
+	default:
+		return 0
+	}
+}
+
+// allow reports whether an entry of the given level and encoded size fits in
+// the budget, spending the budget if so.
+func (l *ByteLimiter) allow(lvl Level, size int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := _timeNow()
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * l.rate
+		if l.tokens > l.capacity {
+			l.tokens = l.capacity
+		}
+	}
+	l.last = now
+
+	cost := float64(size)
+	if lvl > ErrorLevel {
+		l.tokens -= cost
+		if l.tokens < 0 {
+			l.tokens = 0
+		}
+		return true
+	}
+	if l.tokens-cost < l.reserve(lvl) {
+		l.dropped[lvl]++
+		return false
+	}
+	l.tokens -= cost
+	return true
+}
+
+// limitedEntryWriter writes a single encoded entry if the limiter allows it.
+// Encoders write each entry with one call to Write, so the size passed to
+// Write is the entry's encoded size.
+type limitedEntryWriter struct {
+	limiter *ByteLimiter
+	lvl     Level
+	out     io.Writer
+}
+
+func (w limitedEntryWriter) Write(bs []byte) (int, error) {
+	if !w.limiter.allow(w.lvl, len(bs)) {
+		return 0, errEntryDropped
+	}
+	return w.out.Write(bs)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteLimiterPrefersSevereEntries(t *testing.T) {
+	defer stubNow(0)()
+	l := NewByteLimiter(800)
+
+	// Debug entries may only spend the top half of the bucket.
+	assert.True(t, l.allow(DebugLevel, 400), "Expected Debug entry within budget to be allowed.")
+	assert.False(t, l.allow(DebugLevel, 1), "Expected Debug entry to be dropped once half the budget is spent.")
+	// Info entries may spend down to a quarter, Warn down to an eighth.
+	assert.True(t, l.allow(InfoLevel, 200), "Expected Info entry within budget to be allowed.")
+	assert.False(t, l.allow(InfoLevel, 1), "Expected Info entry to be dropped.")
+	assert.True(t, l.allow(WarnLevel, 100), "Expected Warn entry within budget to be allowed.")
+	assert.False(t, l.allow(WarnLevel, 1), "Expected Warn entry to be dropped.")
+	// Errors may spend everything.
+	assert.True(t, l.allow(ErrorLevel, 100), "Expected Error entry within budget to be allowed.")
+	assert.False(t, l.allow(ErrorLevel, 1), "Expected Error entry to be dropped once the budget is spent.")
+	// Panic and Fatal entries are always allowed.
+	assert.True(t, l.allow(PanicLevel, 1000), "Expected Panic entry to be allowed.")
+
+	assert.Equal(t, uint64(4), l.Dropped(), "Unexpected total drop count.")
+	assert.Equal(t, map[Level]uint64{
+		DebugLevel: 1,
+		InfoLevel:  1,
+		WarnLevel:  1,
+		ErrorLevel: 1,
+	}, l.DroppedByLevel(), "Unexpected drop counts by level.")
+}
+
+func TestByteLimiterBelowDebug(t *testing.T) {
+	defer stubNow(0)()
+	l := NewByteLimiter(800)
+	trace := DebugLevel - 1
+	assert.True(t, l.allow(trace, 400), "Expected entry below Debug within budget to be allowed.")
+	assert.False(t, l.allow(trace, 1), "Expected entries below Debug to keep Debug's reserve.")
+}
+
+func TestByteLimiterRefills(t *testing.T) {
+	unstub := stubNow(0)
+	l := NewByteLimiter(100)
+	assert.True(t, l.allow(ErrorLevel, 100), "Expected entry within budget to be allowed.")
+	assert.False(t, l.allow(ErrorLevel, 10), "Expected entry over budget to be dropped.")
+	unstub()
+
+	defer stubNow(100 * time.Millisecond)()
+	assert.True(t, l.allow(ErrorLevel, 10), "Expected budget to refill over time.")
+	assert.False(t, l.allow(ErrorLevel, 1), "Expected refill to be proportional to elapsed time.")
+}
+
+func TestByteLimiterOption(t *testing.T) {
+	defer stubNow(0)()
+	limiter := NewByteLimiter(60)
+	withJSONLogger(t, opts(limiter), func(logger Logger, buf *testBuffer) {
+		logger.Debug("dropped")
+		logger.Error("kept")
+		logger.Error("dropped")
+		assert.Equal(t, []string{`{"level":"error","msg":"kept"}`}, buf.Lines(), "Unexpected output from rate-limited logger.")
+	})
+	assert.Equal(t, map[Level]uint64{DebugLevel: 1, ErrorLevel: 1}, limiter.DroppedByLevel(), "Unexpected drop counts.")
+}
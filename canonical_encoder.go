@@ -0,0 +1,171 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewCanonicalJSONEncoder creates an encoder that writes canonical JSON: the
+// same logical entry always produces byte-identical output, which makes it
+// suitable for hashing or signing each line of a tamper-evident audit log.
+// It accepts the same options as NewJSONEncoder.
+//
+// Entries are canonicalized as a whole before they're written: keys are
+// sorted at every level of nesting, insignificant whitespace is removed, and
+// every number (including those in RawJSON and Object fields) takes the JSON
+// encoder's fixed formatting: integers in decimal, and floats in the shortest
+// decimal representation without an exponent, so that 1e21, 1.0e21, and
+// 1000000000000000000000 are all written the same way. Strings are escaped
+// like encoding/json does, except that HTML characters are never escaped.
+// Since objects can't hold duplicate keys, only the last value for a repeated
+// key is kept.
+//
+// Canonicalization re-parses each entry, so this encoder is much slower than
+// the JSON encoder.
+func NewCanonicalJSONEncoder(options ...JSONOption) Encoder {
+	return canonicalEncoder{NewJSONEncoder(options...)}
+}
+
+type canonicalEncoder struct {
+	Encoder
+}
+
+func (enc canonicalEncoder) Clone() Encoder {
+	return canonicalEncoder{enc.Encoder.Clone()}
+}
+
+func (enc canonicalEncoder) setMessageKey(key string) {
+	setMessageKey(enc.Encoder, key)
+}
+
+func (enc canonicalEncoder) addUnlimitedString(key, val string) {
+	addUnlimitedString(enc.Encoder, key, val)
+}
+
+// AddRawJSON passes the data to the JSON encoder, so that it's canonicalized
+// along with the rest of the entry rather than quoted as a string.
+func (enc canonicalEncoder) AddRawJSON(key string, data []byte) error {
+	return addRawJSON(enc.Encoder, key, data)
+}
+
+func (enc canonicalEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time.Time) error {
+	if sink == nil {
+		return errNilSink
+	}
+	var raw bytes.Buffer
+	if err := enc.Encoder.WriteEntry(&raw, msg, lvl, t); err != nil {
+		return err
+	}
+
+	_, ending := splitLineEnding(raw.Bytes())
+	ending = append([]byte(nil), ending...)
+
+	// Decoding into a map discards key order, and encoding/json always
+	// marshals maps with sorted keys.
+	var entry map[string]interface{}
+	dec := json.NewDecoder(&raw)
+	dec.UseNumber()
+	if err := dec.Decode(&entry); err != nil {
+		return err
+	}
+	if err := canonicalizeNumbers(entry); err != nil {
+		return err
+	}
+	var canonical bytes.Buffer
+	out := json.NewEncoder(&canonical)
+	out.SetEscapeHTML(false)
+	if err := out.Encode(entry); err != nil {
+		return err
+	}
+	// The encoder always terminates entries with a newline.
+	canonical.Truncate(canonical.Len() - 1)
+	canonical.Write(ending)
+
+	expectedBytes := canonical.Len()
+	n, err := sink.Write(canonical.Bytes())
+	if err != nil {
+		return err
+	}
+	if n != expectedBytes {
+		return fmt.Errorf("incomplete write: only wrote %v of %v bytes", n, expectedBytes)
+	}
+	return nil
+}
+
+// canonicalizeNumbers rewrites every json.Number in the decoded value, in
+// place, with canonicalNumber.
+func canonicalizeNumbers(v interface{}) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, elem := range v {
+			if n, ok := elem.(json.Number); ok {
+				c, err := canonicalNumber(n)
+				if err != nil {
+					return err
+				}
+				v[k] = c
+			} else if err := canonicalizeNumbers(elem); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, elem := range v {
+			if n, ok := elem.(json.Number); ok {
+				c, err := canonicalNumber(n)
+				if err != nil {
+					return err
+				}
+				v[i] = c
+			} else if err := canonicalizeNumbers(elem); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// canonicalNumber formats a number the way the JSON encoder formats floats.
+// Integers are kept digit for digit, since they may not fit in a float64, and
+// negative zero is written as zero.
+func canonicalNumber(n json.Number) (json.Number, error) {
+	s := string(n)
+	if !strings.ContainsAny(s, ".eE") {
+		if s == "-0" {
+			return "0", nil
+		}
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return "", fmt.Errorf("can't canonicalize number %s: %v", s, err)
+	}
+	if f == 0 {
+		return "0", nil
+	}
+	return json.Number(strconv.FormatFloat(f, 'f', -1, 64)), nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"math"
+	"testing"
+
+	"github.com/uber-go/zap/spywrite"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalJSONEncoder(t *testing.T) {
+	first := NewCanonicalJSONEncoder()
+	first.AddString("zeta", "<&>")
+	first.AddFloat64("alpha", 1e21)
+	Nest("nested", Int("b", 2), Int("a", 1)).AddTo(first)
+
+	second := NewCanonicalJSONEncoder()
+	Nest("nested", Int("a", 1), Int("b", 2)).AddTo(second)
+	second.AddFloat64("alpha", 1e21)
+	second.AddString("zeta", "<&>")
+
+	expected := `{"alpha":1000000000000000000000,"level":"info","msg":"hello","nested":{"a":1,"b":2},"ts":0,"zeta":"<&>"}`
+	for _, enc := range []Encoder{first, second} {
+		sink := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(sink, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+		assert.Equal(t, expected+"\n", sink.String(), "Expected sorted, canonical output.")
+	}
+}
+
+func TestCanonicalJSONEncoderNumbers(t *testing.T) {
+	encs := []func(Encoder){
+		func(enc Encoder) {
+			enc.AddFloat64("big", 1e21)
+			enc.AddFloat64("zero", math.Copysign(0, -1))
+			Object("nested", map[string]interface{}{"n": 1.5, "list": []float64{1e21, 2}}).AddTo(enc)
+		},
+		func(enc Encoder) {
+			assert.NoError(t, enc.AddObject("big", 1e21), "Unexpected error adding an object.")
+			RawJSON("zero", []byte("-0.0")).AddTo(enc)
+			RawJSON("nested", []byte(`{"n":1.50e0,"list":[1.0e21,2.0]}`)).AddTo(enc)
+		},
+	}
+	expected := `{"big":1000000000000000000000,"level":"info","msg":"hello","nested":{"list":[1000000000000000000000,2],"n":1.5},"ts":0,"zero":0}`
+	for _, f := range encs {
+		enc := NewCanonicalJSONEncoder()
+		f(enc)
+		sink := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(sink, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+		assert.Equal(t, expected, sink.Stripped(), "Expected numbers to be canonicalized.")
+	}
+}
+
+func TestCanonicalJSONEncoderClone(t *testing.T) {
+	enc := NewCanonicalJSONEncoder(NoTime(), MessageKey("event"))
+	enc.AddString("b", "parent")
+	clone := enc.Clone()
+	clone.AddString("a", "child")
+
+	sink := &testBuffer{}
+	require.NoError(t, clone.WriteEntry(sink, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, `{"a":"child","b":"parent","event":"hello","level":"info"}`, sink.Stripped(), "Unexpected output from clone.")
+
+	sink.Reset()
+	require.NoError(t, enc.WriteEntry(sink, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, `{"b":"parent","event":"hello","level":"info"}`, sink.Stripped(), "Expected the parent to be unaffected by the clone.")
+}
+
+func TestCanonicalJSONEncoderDuplicateKeys(t *testing.T) {
+	enc := NewCanonicalJSONEncoder(NoTime())
+	enc.AddInt("dup", 1)
+	enc.AddInt("dup", 2)
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, `{"dup":2,"level":"info","msg":"hello"}`, sink.Stripped(), "Expected the last duplicate key to win.")
+}
+
+func TestCanonicalJSONEncoderErrors(t *testing.T) {
+	enc := NewCanonicalJSONEncoder()
+	assert.Equal(t, errNilSink, enc.WriteEntry(nil, "hello", InfoLevel, epoch), "Expected an error writing to a nil sink.")
+	assert.Error(t, enc.WriteEntry(spywrite.FailWriter{}, "hello", InfoLevel, epoch), "Expected an error on failed writes.")
+	assert.Error(t, enc.WriteEntry(spywrite.ShortWriter{}, "hello", InfoLevel, epoch), "Expected an error on short writes.")
+}
+
+func TestJSONLoggerWithMessageKeyCanonical(t *testing.T) {
+	sink := &testBuffer{}
+	logger := New(NewCanonicalJSONEncoder(NoTime()), Output(sink))
+	WithMessageKey(logger, "event").Info("signup")
+	assert.Equal(t, `{"event":"signup","level":"info"}`, sink.Stripped(), "Expected WithMessageKey to affect canonical encoders.")
+}
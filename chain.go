@@ -0,0 +1,169 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+// A Middleware transforms a log entry and its fields before they're written.
+// It may add, remove, or rewrite fields, and change the entry's level or
+// message; returning false drops the entry. Middlewares receive entries by
+// value and must not call their Fields method.
+type Middleware func(Entry, []Field) (Entry, []Field, bool)
+
+// Chain wraps a logger with an ordered pipeline of middlewares, which unifies
+// enrichment, redaction, and filtering: each entry is passed through the
+// middlewares in order, and the result is written by the underlying logger.
+// If any middleware drops the entry, the remaining middlewares aren't called.
+// Changes to the entry's time are ignored, since the underlying logger
+// timestamps entries itself.
+//
+// So that middlewares see every field, context added with With is kept by
+// the chain and passed to the underlying logger along with each entry's other
+// fields, rather than being serialized up front. Entries at levels the
+// underlying logger doesn't write skip the middlewares altogether, so
+// middlewares can't raise the level of an otherwise disabled entry. Like Tee,
+// the chain makes an exception for Panic and Fatal: it panics or exits even
+// if the entry is dropped.
+func Chain(log Logger, middlewares ...Middleware) Logger {
+	return &chain{
+		log:         log,
+		middlewares: middlewares,
+	}
+}
+
+type chain struct {
+	log         Logger
+	middlewares []Middleware
+	context     []Field
+}
+
+func (c *chain) With(fields ...Field) Logger {
+	context := make([]Field, 0, len(c.context)+len(fields))
+	context = append(context, c.context...)
+	context = append(context, fields...)
+	return &chain{
+		log:         c.log,
+		middlewares: c.middlewares,
+		context:     context,
+	}
+}
+
+func (c *chain) Check(lvl Level, msg string) *CheckedMessage {
+	switch lvl {
+	case PanicLevel, FatalLevel:
+		// Like Tee, make sure that Write calls our Panic and Fatal methods.
+		return NewCheckedMessage(c, lvl, msg)
+	}
+	// Run the middlewares on Write, then write through the underlying
+	// logger's CheckedMessage.
+	return wrapCheckedMessage(c, c.log.Check(lvl, msg), func(cm *CheckedMessage, fields []Field) {
+		e, fields, ok := c.run(lvl, msg, fields)
+		if !ok {
+			return
+		}
+		if e.Level != lvl || e.Message != msg {
+			// The middlewares rewrote the entry, so cm no longer applies.
+			c.log.Log(e.Level, e.Message, fields...)
+			return
+		}
+		cm.Write(fields...)
+	})
+}
+
+func (c *chain) Log(lvl Level, msg string, fields ...Field) {
+	c.write(lvl, msg, fields)
+}
+
+func (c *chain) Debug(msg string, fields ...Field) {
+	c.write(DebugLevel, msg, fields)
+}
+
+func (c *chain) Info(msg string, fields ...Field) {
+	c.write(InfoLevel, msg, fields)
+}
+
+func (c *chain) Warn(msg string, fields ...Field) {
+	c.write(WarnLevel, msg, fields)
+}
+
+func (c *chain) Error(msg string, fields ...Field) {
+	c.write(ErrorLevel, msg, fields)
+}
+
+func (c *chain) Panic(msg string, fields ...Field) {
+	c.write(PanicLevel, msg, fields)
+	panic(msg)
+}
+
+func (c *chain) Fatal(msg string, fields ...Field) {
+	c.write(FatalLevel, msg, fields)
+	_exit(1)
+}
+
+func (c *chain) DFatal(msg string, fields ...Field) {
+	// We don't know whether the underlying logger is in development mode, so
+	// middlewares see an Error-level entry. Unless they change the level, the
+	// underlying logger's DFatal decides what to do.
+	if !enabled(c.log, ErrorLevel) {
+		return
+	}
+	e, fields, ok := c.run(ErrorLevel, msg, fields)
+	if !ok {
+		return
+	}
+	if e.Level != ErrorLevel {
+		c.log.Log(e.Level, e.Message, fields...)
+		return
+	}
+	c.log.DFatal(e.Message, fields...)
+}
+
+func (c *chain) Close() error {
+	return Close(c.log)
+}
+
+func (c *chain) write(lvl Level, msg string, fields []Field) {
+	switch lvl {
+	case PanicLevel, FatalLevel:
+	default:
+		if !enabled(c.log, lvl) {
+			return
+		}
+	}
+	if e, fields, ok := c.run(lvl, msg, fields); ok {
+		c.log.Log(e.Level, e.Message, fields...)
+	}
+}
+
+func (c *chain) run(lvl Level, msg string, fields []Field) (Entry, []Field, bool) {
+	if len(c.context) > 0 {
+		all := make([]Field, 0, len(c.context)+len(fields))
+		all = append(all, c.context...)
+		all = append(all, fields...)
+		fields = all
+	}
+	e := Entry{Level: lvl, Time: _timeNow().UTC(), Message: msg}
+	for _, m := range c.middlewares {
+		var ok bool
+		if e, fields, ok = m(e, fields); !ok {
+			return e, nil, false
+		}
+	}
+	return e, fields, true
+}
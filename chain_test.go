@@ -0,0 +1,101 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	inner, sink := spy.New(zap.DebugLevel)
+	var calls []string
+	enrich := func(e zap.Entry, fs []zap.Field) (zap.Entry, []zap.Field, bool) {
+		calls = append(calls, "enrich")
+		return e, append(fs, zap.String("region", "us-east")), true
+	}
+	drop := func(e zap.Entry, fs []zap.Field) (zap.Entry, []zap.Field, bool) {
+		calls = append(calls, "drop")
+		return e, fs, !strings.HasPrefix(e.Message, "noisy")
+	}
+	rewrite := func(e zap.Entry, fs []zap.Field) (zap.Entry, []zap.Field, bool) {
+		calls = append(calls, "rewrite")
+		e.Message = strings.ToUpper(e.Message)
+		if e.Level == zap.DebugLevel {
+			e.Level = zap.InfoLevel
+		}
+		return e, fs, true
+	}
+	log := zap.Chain(inner, enrich, drop, rewrite).With(zap.Int("request", 42))
+
+	log.Debug("hello", zap.Bool("ok", true))
+	log.Info("noisy message")
+	log.Check(zap.WarnLevel, "checked").Write()
+
+	assert.Equal(t, []string{"enrich", "drop", "rewrite", "enrich", "drop", "enrich", "drop", "rewrite"}, calls, "Unexpected middleware calls.")
+	assert.Equal(t, []spy.Log{
+		{Level: zap.InfoLevel, Msg: "HELLO", Fields: []zap.Field{zap.Int("request", 42), zap.Bool("ok", true), zap.String("region", "us-east")}},
+		{Level: zap.WarnLevel, Msg: "CHECKED", Fields: []zap.Field{zap.Int("request", 42), zap.String("region", "us-east")}},
+	}, sink.Logs(), "Unexpected output from chain.")
+}
+
+func TestChainPanicsWhenDropped(t *testing.T) {
+	inner, sink := spy.New(zap.DebugLevel)
+	dropAll := func(e zap.Entry, fs []zap.Field) (zap.Entry, []zap.Field, bool) { return e, fs, false }
+	log := zap.Chain(inner, dropAll)
+
+	assert.Panics(t, func() { log.Panic("boom") }, "Expected Panic to panic even if the entry is dropped.")
+	log.DFatal("dropped")
+	assert.Equal(t, 0, len(sink.Logs()), "Expected all entries to be dropped.")
+}
+
+func TestChainCheckDisabled(t *testing.T) {
+	inner, _ := spy.New(zap.WarnLevel)
+	assert.Nil(t, zap.Chain(inner).Check(zap.InfoLevel, "disabled"), "Expected disabled levels to return nil.")
+	assert.NotNil(t, zap.Chain(inner).Check(zap.PanicLevel, "panic"), "Expected Panic level to always be checked.")
+}
+
+func TestChainSkipsDisabledLevels(t *testing.T) {
+	inner, sink := spy.New(zap.ErrorLevel)
+	calls := 0
+	count := func(e zap.Entry, fs []zap.Field) (zap.Entry, []zap.Field, bool) {
+		calls++
+		return e, fs, true
+	}
+	log := zap.Chain(inner, count)
+	log.Debug("disabled")
+	log.Log(zap.WarnLevel, "disabled")
+	assert.Equal(t, 0, calls, "Expected middlewares not to run for disabled levels.")
+
+	log.Error("enabled")
+	assert.Panics(t, func() { log.Panic("boom") }, "Expected Panic to panic.")
+	assert.Equal(t, 2, calls, "Expected middlewares to run for enabled levels.")
+	assert.Equal(t, 2, len(sink.Logs()), "Unexpected number of logs.")
+
+	panicOnly, _ := spy.New(zap.PanicLevel)
+	zap.Chain(panicOnly, count).DFatal("disabled")
+	assert.Equal(t, 2, calls, "Expected DFatal not to run middlewares when Error is disabled.")
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A ChannelEntry is a structured log entry delivered by a ChannelLogger. Its
+// fields include both the logger's context and the fields added at the log
+// site, with duplicate keys resolved by MergeFields; consumers can serialize
+// them by adding them to any Encoder.
+//
+// Each entry gets its own Fields slice, so consumers may modify or retain it.
+// However, the fields themselves are shallow copies: fields that refer to
+// other values (like those constructed with Object, Marshaler, and Error) are
+// serialized lazily, so the consumer sees those values as they are when it
+// encodes them, not as they were when the entry was logged. Avoid mutating
+// values after logging them, or log a snapshot instead.
+type ChannelEntry struct {
+	Level   Level
+	Time    time.Time
+	Message string
+	Fields  []Field
+}
+
+// A ChannelLogger is a Logger that streams entries to a channel, which makes
+// it easy to feed in-process consumers like a live log viewer. It's similar
+// to the spy logger, but it delivers entries as they're written rather than
+// accumulating them.
+//
+// By default, delivery never blocks the logging goroutine: if the channel's
+// buffer is full, the entry is dropped and counted. Like other loggers, Panic
+// and Fatal still panic and exit. Options that configure the encoder or output
+// (including Fields) aren't honored; use With to add context instead. Hooks
+// and FieldHooks run as usual: fields they add (e.g., with AddCaller) are
+// appended to the entry's Fields, and ErrDropEntry keeps the entry off the
+// channel.
+type ChannelLogger struct {
+	Meta
+
+	sink    *channelSink
+	context []Field
+}
+
+type channelSink struct {
+	sync.RWMutex
+
+	entries chan<- ChannelEntry
+	// Whether Close should close the channel.
+	owned      bool
+	dropIfFull bool
+	closed     bool
+	dropped    uint64
+}
+
+// NewChannelLogger constructs a ChannelLogger whose channel buffers up to buf
+// entries. It returns the logger and the receiving end of its channel.
+func NewChannelLogger(buf int, options ...Option) (*ChannelLogger, <-chan ChannelEntry) {
+	entries := make(chan ChannelEntry, buf)
+	return &ChannelLogger{
+		Meta: MakeMeta(NullEncoder(), options...),
+		sink: &channelSink{entries: entries, owned: true, dropIfFull: true},
+	}, entries
+}
+
+// NewChannelLoggerTo constructs a ChannelLogger that sends entries to a
+// channel owned by the caller, which lets several loggers feed one consumer
+// (for example, a supervisor goroutine that reacts to errors). If dropIfFull
+// is true, entries are dropped and counted when the channel is full, as with
+// NewChannelLogger. Otherwise, the logging goroutine blocks until the consumer
+// receives the entry, so a stalled consumer stalls logging.
+//
+// Since the caller owns the channel, closing the logger stops delivery but
+// doesn't close the channel.
+func NewChannelLoggerTo(ch chan<- ChannelEntry, dropIfFull bool, options ...Option) *ChannelLogger {
+	return &ChannelLogger{
+		Meta: MakeMeta(NullEncoder(), options...),
+		sink: &channelSink{entries: ch, dropIfFull: dropIfFull},
+	}
+}
+
+// Close stops delivery, closing the logger's channel if the logger created it.
+// Entries logged after Close, including by child loggers created with With,
+// are discarded. If the logger blocks when its channel is full, Close waits
+// for blocked sends to complete. It's safe to call Close more than once, and
+// it always returns nil.
+func (cl *ChannelLogger) Close() error {
+	cl.sink.Lock()
+	if !cl.sink.closed {
+		cl.sink.closed = true
+		if cl.sink.owned {
+			close(cl.sink.entries)
+		}
+	}
+	cl.sink.Unlock()
+	return nil
+}
+
+// Dropped returns the number of entries dropped because the channel was full.
+func (cl *ChannelLogger) Dropped() uint64 {
+	return atomic.LoadUint64(&cl.sink.dropped)
+}
+
+// With creates a child logger that shares the parent's channel.
+func (cl *ChannelLogger) With(fields ...Field) Logger {
+	context := make([]Field, 0, len(cl.context)+len(fields))
+	context = append(context, cl.context...)
+	context = append(context, fields...)
+	return &ChannelLogger{
+		Meta:    cl.Meta,
+		sink:    cl.sink,
+		context: context,
+	}
+}
+
+// Check returns a CheckedMessage if logging a message at the specified level
+// is enabled.
+func (cl *ChannelLogger) Check(lvl Level, msg string) *CheckedMessage {
+	return cl.Meta.Check(cl, lvl, msg)
+}
+
+// Log sends a message at the specified level.
+func (cl *ChannelLogger) Log(lvl Level, msg string, fields ...Field) {
+	cl.log(lvl, msg, fields, 0)
+}
+
+// Debug sends a message at the Debug level.
+func (cl *ChannelLogger) Debug(msg string, fields ...Field) {
+	cl.log(DebugLevel, msg, fields, 0)
+}
+
+// Info sends a message at the Info level.
+func (cl *ChannelLogger) Info(msg string, fields ...Field) {
+	cl.log(InfoLevel, msg, fields, 0)
+}
+
+// Warn sends a message at the Warn level.
+func (cl *ChannelLogger) Warn(msg string, fields ...Field) {
+	cl.log(WarnLevel, msg, fields, 0)
+}
+
+// Error sends a message at the Error level.
+func (cl *ChannelLogger) Error(msg string, fields ...Field) {
+	cl.log(ErrorLevel, msg, fields, 0)
+}
+
+func (cl *ChannelLogger) errorSkip(skip int, msg string, fields []Field) {
+	cl.log(ErrorLevel, msg, fields, skip)
+}
+
+// Panic sends a message at the Panic level, then panics.
+func (cl *ChannelLogger) Panic(msg string, fields ...Field) {
+	cl.log(PanicLevel, msg, fields, 0)
+	panic(msg)
+}
+
+// Fatal sends a message at the Fatal level, then calls os.Exit(1).
+func (cl *ChannelLogger) Fatal(msg string, fields ...Field) {
+	cl.log(FatalLevel, msg, fields, 0)
+	_exit(1)
+}
+
+// DFatal behaves like Fatal if the logger is in development mode, and like
+// Error otherwise.
+func (cl *ChannelLogger) DFatal(msg string, fields ...Field) {
+	if cl.Development {
+		cl.Fatal(msg, fields...)
+		return
+	}
+	cl.Error(msg, fields...)
+}
+
+func (cl *ChannelLogger) log(lvl Level, msg string, fields []Field, callerSkip int) {
+	if !cl.Meta.Enabled(lvl) {
+		return
+	}
+	fields = MergeFields(cl.context, fields)
+
+	// As in logger.log, run the hooks inline so that caller-capturing hooks
+	// skip the same number of frames.
+	var added fieldsKeyValue
+	entry := newEntry(lvl, msg, &added)
+	entry.callerSkip = callerSkip
+	defer entry.free()
+	for _, hook := range cl.FieldHooks {
+		if err := hook(entry, fields); err == ErrDropEntry {
+			return
+		} else if err != nil {
+			cl.InternalError("hook", err)
+		}
+	}
+	for _, hook := range cl.Hooks {
+		if err := hook(entry); err == ErrDropEntry {
+			return
+		} else if err != nil {
+			cl.InternalError("hook", err)
+		}
+	}
+
+	cl.sink.send(ChannelEntry{
+		Level:   entry.Level,
+		Time:    entry.Time,
+		Message: entry.Message,
+		Fields:  append(fields, added...),
+	})
+}
+
+func (s *channelSink) send(e ChannelEntry) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.closed {
+		return
+	}
+	if !s.dropIfFull {
+		s.entries <- e
+		return
+	}
+	select {
+	case s.entries <- e:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelLogger(t *testing.T) {
+	defer stubNow(time.Second)()
+
+	log, entries := NewChannelLogger(2, DebugLevel)
+	log.With(String("user", "alice")).Info("hello", Int("n", 42))
+	log.Debug("debug")
+
+	require.Equal(t, 2, len(entries), "Expected two buffered entries.")
+	assert.Equal(t, ChannelEntry{
+		Level:   InfoLevel,
+		Time:    time.Unix(0, int64(time.Second)).UTC(),
+		Message: "hello",
+		Fields:  []Field{String("user", "alice"), Int("n", 42)},
+	}, <-entries, "Unexpected first entry.")
+	assert.Equal(t, "debug", (<-entries).Message, "Unexpected second entry.")
+}
+
+func TestChannelLoggerDropsWhenFull(t *testing.T) {
+	log, entries := NewChannelLogger(1, InfoLevel)
+	log.Debug("disabled")
+	log.Info("first")
+	log.Info("dropped")
+	log.Info("also dropped")
+
+	assert.Equal(t, uint64(2), log.Dropped(), "Unexpected number of dropped entries.")
+	assert.Equal(t, "first", (<-entries).Message, "Expected the first entry to be kept.")
+	assert.Equal(t, 0, len(entries), "Expected no more buffered entries.")
+}
+
+func TestChannelLoggerClose(t *testing.T) {
+	log, entries := NewChannelLogger(1, InfoLevel)
+	child := log.With(Int("foo", 42))
+	log.Close()
+	log.Close()
+
+	assert.NotPanics(t, func() { child.Info("after close") }, "Unexpected panic logging after close.")
+	_, ok := <-entries
+	assert.False(t, ok, "Expected channel to be closed.")
+}
+
+func TestChannelLoggerTo(t *testing.T) {
+	ch := make(chan ChannelEntry)
+	log := NewChannelLoggerTo(ch, false, InfoLevel)
+
+	done := make(chan struct{})
+	go func() {
+		log.Error("blocked", Int("n", 1))
+		log.Error("blocked", Int("n", 2))
+		close(done)
+	}()
+	for i := 1; i <= 2; i++ {
+		e := <-ch
+		assert.Equal(t, []Field{Int("n", i)}, e.Fields, "Expected entries to be delivered in order.")
+	}
+	<-done
+	assert.Equal(t, uint64(0), log.Dropped(), "Expected a blocking logger not to drop entries.")
+
+	dropping := NewChannelLoggerTo(ch, true, InfoLevel)
+	dropping.Info("dropped")
+	assert.Equal(t, uint64(1), dropping.Dropped(), "Expected entries to be dropped when nobody is receiving.")
+
+	log.Close()
+	// Would block forever if the closed logger still tried to send.
+	log.Info("after close")
+	assert.NotPanics(t, func() { close(ch) }, "Expected Close not to close a caller-owned channel.")
+}
+
+func TestChannelLoggerPanicAndFatal(t *testing.T) {
+	log, entries := NewChannelLogger(2, InfoLevel)
+	assert.Panics(t, func() { log.Panic("panic") }, "Expected Panic to panic.")
+
+	stub := stubExit()
+	defer stub.Unstub()
+	log.Fatal("fatal")
+	stub.AssertStatus(t, 1)
+
+	assert.Equal(t, PanicLevel, (<-entries).Level, "Unexpected level for Panic entry.")
+	assert.Equal(t, FatalLevel, (<-entries).Level, "Unexpected level for Fatal entry.")
+}
+
+func TestChannelLoggerHooks(t *testing.T) {
+	drop := Hook(func(e *Entry) error {
+		if strings.HasSuffix(e.Message, "drop me") {
+			return ErrDropEntry
+		}
+		return nil
+	})
+	log, entries := NewChannelLogger(2, AddCaller(), AddStacks(ErrorLevel), drop)
+	log.Info("drop me")
+	log.Error("keep me", Int("n", 1))
+	require.Equal(t, 1, len(entries), "Expected hooks to drop an entry.")
+
+	e := <-entries
+	assert.True(t, strings.HasPrefix(e.Message, "channel_logger_test.go:"), "Expected AddCaller to annotate the message, got %q.", e.Message)
+	assert.True(t, strings.HasSuffix(e.Message, ": keep me"), "Unexpected message %q.", e.Message)
+	require.Equal(t, 2, len(e.Fields), "Expected AddStacks to append a field.")
+	assert.Equal(t, Int("n", 1), e.Fields[0], "Expected log-site fields first.")
+	assert.Equal(t, "stacktrace", e.Fields[1].key, "Expected a stacktrace field.")
+}
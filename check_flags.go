@@ -0,0 +1,80 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+// CheckFlags adjust how a single entry is handled, giving call sites control
+// over important one-off entries. Pass them to CheckWithFlags.
+type CheckFlags uint8
+
+const (
+	// ForceSync syncs the logger's outputs after writing the entry, as if the
+	// entry's level were at or above the logger's SyncLevel. It's honored by
+	// loggers created with New.
+	ForceSync CheckFlags = 1 << iota
+	// SkipSampling exempts the entry from sampling. It's honored by the
+	// samplers in the zwrap package.
+	SkipSampling
+)
+
+// A FlagChecker is a Logger that honors CheckFlags. Loggers created with New
+// and Tee implement it; wrappers that delegate to another logger should
+// implement it by passing the flags they don't handle along to
+// CheckWithFlags.
+type FlagChecker interface {
+	Logger
+	CheckWithFlags(Level, string, CheckFlags) *CheckedMessage
+}
+
+// CheckWithFlags is like log.Check, but applies the supplied flags to the
+// entry if log is a FlagChecker:
+//
+//	if cm := zap.CheckWithFlags(logger, zap.WarnLevel, "quota exceeded", zap.ForceSync|zap.SkipSampling); cm.OK() {
+//		cm.Write(zap.String("tenant", tenant))
+//	}
+//
+// Loggers that don't implement FlagChecker ignore the flags.
+func CheckWithFlags(log Logger, lvl Level, msg string, flags CheckFlags) *CheckedMessage {
+	if fc, ok := log.(FlagChecker); ok {
+		return fc.CheckWithFlags(lvl, msg, flags)
+	}
+	return log.Check(lvl, msg)
+}
+
+func (log *logger) CheckWithFlags(lvl Level, msg string, flags CheckFlags) *CheckedMessage {
+	if flags&ForceSync == 0 || lvl >= log.SyncLevel {
+		return log.Check(lvl, msg)
+	}
+	syncing := *log
+	syncing.SyncLevel = lvl
+	return syncing.Check(lvl, msg)
+}
+
+func (ml multiLogger) CheckWithFlags(lvl Level, msg string, flags CheckFlags) *CheckedMessage {
+	switch lvl {
+	case FatalLevel, PanicLevel:
+		return ml.Check(lvl, msg)
+	}
+	var cm *CheckedMessage
+	for _, log := range ml {
+		cm = cm.Chain(CheckWithFlags(log, lvl, msg, flags))
+	}
+	return cm
+}
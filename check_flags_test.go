@@ -0,0 +1,66 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/uber-go/zap/spywrite"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckWithFlagsForceSync(t *testing.T) {
+	sink := &spywrite.WriteSyncer{Writer: ioutil.Discard}
+	logger := New(NewJSONEncoder(), Output(sink))
+
+	CheckWithFlags(logger, InfoLevel, "routine", 0).Write()
+	assert.False(t, sink.Called(), "Expected entries below the SyncLevel not to sync.")
+
+	CheckWithFlags(logger, InfoLevel, "important", ForceSync).Write()
+	assert.True(t, sink.Called(), "Expected ForceSync to sync the output.")
+
+	sink.Syncer = spywrite.Syncer{}
+	logger.Info("routine")
+	assert.False(t, sink.Called(), "Expected ForceSync not to affect later entries.")
+}
+
+func TestCheckWithFlagsDisabled(t *testing.T) {
+	logger := New(NewJSONEncoder(), WarnLevel)
+	assert.Nil(t, CheckWithFlags(logger, InfoLevel, "disabled", ForceSync), "Expected flags not to enable disabled levels.")
+}
+
+func TestCheckWithFlagsTee(t *testing.T) {
+	sinks := []*spywrite.WriteSyncer{{Writer: ioutil.Discard}, {Writer: ioutil.Discard}}
+	tee := Tee(New(NewJSONEncoder(), Output(sinks[0])), New(NewJSONEncoder(), Output(sinks[1])))
+	CheckWithFlags(tee, WarnLevel, "important", ForceSync).Write()
+	for i, sink := range sinks {
+		assert.True(t, sink.Called(), "Expected ForceSync to sync output %v.", i)
+	}
+}
+
+func TestCheckWithFlagsUnsupported(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		CheckWithFlags(WithLazy(logger), InfoLevel, "lazy", ForceSync).Write()
+		assert.Equal(t, `{"level":"info","msg":"lazy"}`, buf.Stripped(), "Expected unsupported loggers to ignore flags.")
+	})
+}
@@ -38,6 +38,9 @@ type CheckedMessage struct {
 	safeToWrite bool
 	lvl         Level
 	msg         string
+	// If set, Write passes the fields to write rather than calling the
+	// logger's methods; see wrapCheckedMessage.
+	write func([]Field)
 
 	// singly linked list built by Chain
 	next *CheckedMessage // carried by each part of Chain-ed list
@@ -51,6 +54,24 @@ type CheckedMessage struct {
 func NewCheckedMessage(logger Logger, lvl Level, msg string) *CheckedMessage {
 	m := _cmPool.Get().(*CheckedMessage)
 	m.safeToWrite, m.logger, m.lvl, m.msg = true, logger, lvl, msg
+	m.write = nil
+	return m
+}
+
+// wrapCheckedMessage returns a CheckedMessage for log that, when written,
+// calls write with inner and the log-site fields; write must write inner at
+// most once. If inner isn't OK, it returns nil. Wrappers use it to see (or
+// change) an entry's fields while writing through the CheckedMessage returned
+// by the logger they wrap, since checking that logger and then logging to it
+// again would, for example, charge a sampler twice for the same entry.
+func wrapCheckedMessage(log Logger, inner *CheckedMessage, write func(inner *CheckedMessage, fields []Field)) *CheckedMessage {
+	if !inner.OK() {
+		return nil
+	}
+	m := NewCheckedMessage(log, inner.lvl, inner.msg)
+	m.write = func(fields []Field) {
+		write(inner, fields)
+	}
 	return m
 }
 
@@ -83,25 +104,29 @@ func (m *CheckedMessage) Write(fields ...Field) {
 	}
 	m.safeToWrite = false
 
-	switch m.lvl {
-	case DebugLevel:
-		m.logger.Debug(m.msg, fields...)
-	case InfoLevel:
-		m.logger.Info(m.msg, fields...)
-	case WarnLevel:
-		m.logger.Warn(m.msg, fields...)
-	case ErrorLevel:
-		m.logger.Error(m.msg, fields...)
-	case PanicLevel:
-		m.logger.Panic(m.msg, fields...)
-	case FatalLevel:
-		m.logger.Fatal(m.msg, fields...)
-	default:
-		m.logger.Log(m.lvl, m.msg, fields...)
+	if m.write != nil {
+		m.write(fields)
+	} else {
+		switch m.lvl {
+		case DebugLevel:
+			m.logger.Debug(m.msg, fields...)
+		case InfoLevel:
+			m.logger.Info(m.msg, fields...)
+		case WarnLevel:
+			m.logger.Warn(m.msg, fields...)
+		case ErrorLevel:
+			m.logger.Error(m.msg, fields...)
+		case PanicLevel:
+			m.logger.Panic(m.msg, fields...)
+		case FatalLevel:
+			m.logger.Fatal(m.msg, fields...)
+		default:
+			m.logger.Log(m.lvl, m.msg, fields...)
+		}
 	}
 
 	m.next.Write(fields...)
-	m.next, m.tail = nil, nil
+	m.next, m.tail, m.write = nil, nil, nil
 	_cmPool.Put(m)
 }
 
@@ -0,0 +1,89 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "io"
+
+// Close flushes and releases the resources held by a logger, including its
+// outputs and any background goroutines, which makes it useful for graceful
+// shutdown. Loggers take part by implementing io.Closer: the loggers returned
+// by New, Tee, and this package's other wrappers do, and they close any
+// loggers or WriteSyncers they wrap. Closing a logger created by New syncs its
+// outputs and closes any that implement io.Closer, except for files (which
+// are often shared, like os.Stdout) and the error output. Loggers that don't
+// implement io.Closer are left alone.
+//
+// Since child loggers created with With share their parent's outputs, Close
+// should be called once, on the root logger, after all logging is done.
+func Close(log Logger) error {
+	if c, ok := log.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (log *logger) Close() error {
+	var errs multiError
+	for _, ws := range []WriteSyncer{log.Output, log.SecondaryOutput, log.DebugOutput} {
+		if err := closeSyncer(ws); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.asError()
+}
+
+func (ml multiLogger) Close() error {
+	return closeLoggers(ml)
+}
+
+func (r *fieldRouter) Close() error {
+	logs := make([]Logger, 0, len(r.routes)+1)
+	for _, log := range r.routes {
+		logs = append(logs, log)
+	}
+	return closeLoggers(append(logs, r.fallback))
+}
+
+func (l *lazyLogger) Close() error {
+	return Close(l.parent)
+}
+
+func (d *dedupLogger) Close() error {
+	return Close(d.log)
+}
+
+func (o *onceLogger) Close() error {
+	return Close(o.log)
+}
+
+func (b *breadcrumbLogger) Close() error {
+	return Close(b.log)
+}
+
+func closeLoggers(logs []Logger) error {
+	var errs multiError
+	for _, log := range logs {
+		if err := Close(log); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.asError()
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/uber-go/zap/spywrite"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closeSpy is a WriteSyncer that records calls to Sync and Close.
+type closeSpy struct {
+	bytes.Buffer
+	spywrite.Syncer
+
+	closed int
+	err    error
+}
+
+func (c *closeSpy) Close() error {
+	c.closed++
+	return c.err
+}
+
+func TestCloseLogger(t *testing.T) {
+	out, secondary := &closeSpy{}, &closeSpy{}
+	log := New(NullEncoder(), Output(out), SecondaryOutput(secondary, ErrorLevel))
+	require.NoError(t, Close(log.With(Int("foo", 42))), "Unexpected error closing logger.")
+	assert.Equal(t, 1, out.closed, "Expected output to be closed.")
+	assert.True(t, out.Called(), "Expected output to be synced.")
+	assert.Equal(t, 1, secondary.closed, "Expected secondary output to be closed.")
+}
+
+func TestCloseLoggerSkipsFiles(t *testing.T) {
+	f, err := ioutil.TempFile("", "zap-close")
+	require.NoError(t, err, "Failed to create temporary file.")
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	require.NoError(t, Close(New(NullEncoder(), Output(f))), "Unexpected error closing logger.")
+	_, err = f.Stat()
+	assert.NoError(t, err, "Expected file to remain open.")
+}
+
+func TestCloseLoggerPipes(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err, "Failed to create pipe.")
+	defer r.Close()
+	defer w.Close()
+
+	require.Error(t, w.Sync(), "Expected syncing a pipe to fail.")
+	log := New(NullEncoder(), Output(w), SplitOutput(w, ErrorLevel))
+	assert.NoError(t, Close(log), "Expected unsupported syncs of pipes to be ignored.")
+}
+
+// syncCounter is a closeSpy that counts calls to Sync.
+type syncCounter struct {
+	closeSpy
+	syncs int
+}
+
+func (s *syncCounter) Sync() error {
+	s.syncs++
+	return nil
+}
+
+func TestCloseLoggerSyncsOnce(t *testing.T) {
+	out := &syncCounter{}
+	require.NoError(t, Close(New(NullEncoder(), Output(out))), "Unexpected error closing logger.")
+	assert.Equal(t, 1, out.syncs, "Expected output to be synced once.")
+	assert.Equal(t, 1, out.closed, "Expected output to be closed.")
+}
+
+func TestCloseWrappers(t *testing.T) {
+	outs := make([]*closeSpy, 7)
+	logs := make([]Logger, len(outs))
+	for i := range outs {
+		outs[i] = &closeSpy{}
+		logs[i] = New(NullEncoder(), Output(outs[i]))
+	}
+	tee := Tee(
+		logs[0],
+		RouteByField("tenant", map[string]Logger{"acme": logs[1]}, logs[2]),
+		WithLazy(logs[3]),
+		WithDedupHash(logs[4]),
+		LogOnce(logs[5]),
+		Breadcrumbs(logs[6], InfoLevel, 10),
+	)
+	require.NoError(t, Close(tee), "Unexpected error closing wrapped loggers.")
+	for i, out := range outs {
+		assert.Equal(t, 1, out.closed, "Expected output %v to be closed.", i)
+	}
+}
+
+func TestCloseTeeShared(t *testing.T) {
+	outs := []*closeSpy{{}, {}, {}}
+	log := TeeShared(NullEncoder(), []WriteSyncer{outs[0], outs[1], outs[2]})
+	require.NoError(t, Close(log), "Unexpected error closing shared tee.")
+	for i, out := range outs {
+		assert.Equal(t, 1, out.closed, "Expected output %v to be closed.", i)
+		assert.True(t, out.Called(), "Expected output %v to be synced.", i)
+	}
+}
+
+func TestCloseErrors(t *testing.T) {
+	failed := &closeSpy{err: errors.New("failed")}
+	tee := Tee(New(NullEncoder(), Output(failed)), New(NullEncoder(), Output(MultiWriteSyncer(&closeSpy{}, failed))))
+	err := Close(tee)
+	require.Error(t, err, "Expected close errors to propagate.")
+	assert.Contains(t, err.Error(), "failed", "Unexpected error message.")
+	assert.Equal(t, 2, failed.closed, "Expected failures not to stop other outputs from closing.")
+	assert.NoError(t, Close(WithLazy(nil)), "Expected nil loggers to be ignored.")
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"io"
+	"time"
+)
+
+// CombineEncoders returns an Encoder that runs two encoders over each entry
+// and writes both results on a single line, joined by the separator: for
+// example, JSON followed by a human-readable comment for grep-ability,
+//
+//	zap.CombineEncoders(zap.NewJSONEncoder(), zap.NewTextEncoder(), " # ")
+//
+// Each field is added to both encoders. When writing an entry, the primary
+// encoder's trailing newline is replaced by the separator, and the combined
+// line always ends in a newline. The line is written with a single call to
+// Write, so it's never split across outputs that shard or limit writes.
+// Encoders that produce multiple lines per entry will make the combined
+// output ambiguous.
+func CombineEncoders(primary, secondary Encoder, sep string) Encoder {
+	return &combinedEncoder{
+		teeKeyValue: teeKeyValue{primary, secondary},
+		primary:     primary,
+		secondary:   secondary,
+		sep:         sep,
+	}
+}
+
+type combinedEncoder struct {
+	teeKeyValue
+
+	primary   Encoder
+	secondary Encoder
+	sep       string
+}
+
+func (c *combinedEncoder) Clone() Encoder {
+	return CombineEncoders(c.primary.Clone(), c.secondary.Clone(), c.sep)
+}
+
+func (c *combinedEncoder) setMessageKey(key string) {
+	setMessageKey(c.primary, key)
+	setMessageKey(c.secondary, key)
+}
+
+func (c *combinedEncoder) Free() {
+	c.primary.Free()
+	c.secondary.Free()
+}
+
+func (c *combinedEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time.Time) error {
+	if sink == nil {
+		return errNilSink
+	}
+	var primary, secondary bytes.Buffer
+	if err := c.primary.WriteEntry(&primary, msg, lvl, t); err != nil {
+		return err
+	}
+	if err := c.secondary.WriteEntry(&secondary, msg, lvl, t); err != nil {
+		return err
+	}
+
+	line, _ := splitLineEnding(primary.Bytes())
+	line = append(line, c.sep...)
+	line = append(line, secondary.Bytes()...)
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		line = append(line, '\n')
+	}
+	_, err := sink.Write(line)
+	return err
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCombineEncoders(t *testing.T) {
+	enc := CombineEncoders(NewJSONEncoder(NoTime()), NewTextEncoder(TextNoTime()), " # ")
+	enc.AddString("user", "alice")
+
+	clone := enc.Clone()
+	clone.AddInt("n", 42)
+
+	sink := &testBuffer{}
+	require.NoError(t, clone.WriteEntry(sink, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	require.NoError(t, enc.WriteEntry(sink, "bye", WarnLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, []string{
+		`{"level":"info","msg":"hello","user":"alice","n":42} # [I] hello user=alice n=42`,
+		`{"level":"warn","msg":"bye","user":"alice"} # [W] bye user=alice`,
+	}, sink.Lines(), "Unexpected combined output.")
+	clone.Free()
+	enc.Free()
+}
+
+func TestCombineEncodersLogger(t *testing.T) {
+	sink := &testBuffer{}
+	enc := CombineEncoders(NewJSONEncoder(NoTime()), NewTextEncoder(TextNoTime()), "\t# ")
+	logger := New(enc, Output(sink), Fields(Int("pid", 1)))
+	logger.With(String("user", "alice")).Info("hello", Nest("req", String("path", "/")))
+	assert.Equal(
+		t,
+		`{"level":"info","msg":"hello","pid":1,"user":"alice","req":{"path":"/"}}`+"\t# "+`[I] hello pid=1 user=alice req={path=/}`,
+		sink.Stripped(),
+		"Unexpected output from logger with combined encoders.",
+	)
+}
+
+func TestCombineEncodersErrors(t *testing.T) {
+	enc := CombineEncoders(NewJSONEncoder(), NewTextEncoder(), " # ")
+	assert.Equal(t, errNilSink, enc.WriteEntry(nil, "hello", InfoLevel, epoch), "Expected an error writing to a nil sink.")
+}
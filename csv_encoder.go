@@ -0,0 +1,274 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Names of the fixed columns written by the CSV encoder.
+var _csvFixedColumns = []string{"time", "level", "msg"}
+
+// _csvFieldsColumn is the name of the column holding fields that don't have
+// their own column.
+const _csvFieldsColumn = "fields"
+
+// csvEncoder is an Encoder implementation that writes one CSV record per
+// entry, using a fixed column schema.
+type csvEncoder struct {
+	comma   rune
+	timeFmt string
+	keys    []string
+	columns map[string]int
+	values  []string
+	extras  *jsonEncoder
+}
+
+// NewCSVEncoder creates an encoder that writes each entry as a CSV record,
+// which makes logs easy to import into spreadsheets and analytics tools. Every
+// record has the same columns: the entry's time, level, and message, then one
+// column for each key configured with the CSVColumns option, then a "fields"
+// column. Fields whose keys have their own column are written there (nested
+// objects as JSON); all other fields are collected into a JSON object in the
+// "fields" column. Values are quoted as required by RFC 4180.
+//
+// By default, the encoder uses commas as delimiters and RFC3339-formatted
+// timestamps. To write a header row, wrap the output in a HeaderWriteSyncer
+// with the result of CSVHeader.
+func NewCSVEncoder(options ...CSVOption) Encoder {
+	enc := &csvEncoder{
+		comma:   ',',
+		timeFmt: time.RFC3339,
+		columns: make(map[string]int),
+		extras:  NewJSONEncoder().(*jsonEncoder),
+	}
+	for _, opt := range options {
+		opt.apply(enc)
+	}
+	enc.values = make([]string, len(enc.keys))
+	return enc
+}
+
+// CSVHeader returns the header row matching the records written by a CSV
+// encoder constructed with the same options.
+func CSVHeader(options ...CSVOption) []byte {
+	enc := NewCSVEncoder(options...).(*csvEncoder)
+	defer enc.Free()
+	header := make([]string, 0, len(_csvFixedColumns)+len(enc.keys)+1)
+	header = append(header, _csvFixedColumns...)
+	header = append(header, enc.keys...)
+	header = append(header, _csvFieldsColumn)
+	// Writing a header row can only fail if the delimiter is invalid, in which
+	// case writing entries fails too.
+	bs, _ := enc.record(header)
+	return bs
+}
+
+func (enc *csvEncoder) Free() {
+	enc.extras.Free()
+}
+
+func (enc *csvEncoder) AddString(key, val string) {
+	if !enc.setColumn(key, val) {
+		enc.extras.AddString(key, val)
+	}
+}
+
+func (enc *csvEncoder) AddBool(key string, val bool) {
+	if !enc.setColumn(key, strconv.FormatBool(val)) {
+		enc.extras.AddBool(key, val)
+	}
+}
+
+func (enc *csvEncoder) AddInt(key string, val int) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *csvEncoder) AddInt64(key string, val int64) {
+	if !enc.setColumn(key, strconv.FormatInt(val, 10)) {
+		enc.extras.AddInt64(key, val)
+	}
+}
+
+func (enc *csvEncoder) AddUint(key string, val uint) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *csvEncoder) AddUint64(key string, val uint64) {
+	if !enc.setColumn(key, strconv.FormatUint(val, 10)) {
+		enc.extras.AddUint64(key, val)
+	}
+}
+
+func (enc *csvEncoder) AddUintptr(key string, val uintptr) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *csvEncoder) AddFloat64(key string, val float64) {
+	if !enc.setColumn(key, strconv.FormatFloat(val, 'f', -1, 64)) {
+		enc.extras.AddFloat64(key, val)
+	}
+}
+
+// AddMarshaler adds a LogMarshaler. If the key has its own column, the
+// marshaler's fields are written there as a JSON object.
+func (enc *csvEncoder) AddMarshaler(key string, obj LogMarshaler) error {
+	if _, ok := enc.columns[key]; !ok {
+		return enc.extras.AddMarshaler(key, obj)
+	}
+	tmp := NewJSONEncoder().(*jsonEncoder)
+	tmp.bytes = append(tmp.bytes, '{')
+	err := obj.MarshalLog(tmp)
+	tmp.bytes = append(tmp.bytes, '}')
+	enc.setColumn(key, string(tmp.bytes))
+	tmp.Free()
+	return err
+}
+
+// AddObject uses reflection to add an arbitrary object. If the key has its
+// own column, the object is written there as JSON.
+func (enc *csvEncoder) AddObject(key string, obj interface{}) error {
+	if _, ok := enc.columns[key]; !ok {
+		return enc.extras.AddObject(key, obj)
+	}
+	marshaled, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	enc.setColumn(key, string(marshaled))
+	return nil
+}
+
+// Clone copies the current encoder, including any data already encoded.
+func (enc *csvEncoder) Clone() Encoder {
+	clone := &csvEncoder{
+		comma:   enc.comma,
+		timeFmt: enc.timeFmt,
+		keys:    enc.keys,
+		columns: enc.columns,
+		values:  make([]string, len(enc.values)),
+		extras:  enc.extras.Clone().(*jsonEncoder),
+	}
+	copy(clone.values, enc.values)
+	return clone
+}
+
+// WriteEntry writes a CSV record to the supplied writer. It doesn't modify
+// the encoder, so it's safe to call from multiple goroutines, but it's not
+// safe to call WriteEntry while adding fields.
+func (enc *csvEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time.Time) error {
+	if sink == nil {
+		return errNilSink
+	}
+
+	record := make([]string, 0, len(_csvFixedColumns)+len(enc.values)+1)
+	var ts string
+	if enc.timeFmt != "" {
+		ts = t.Format(enc.timeFmt)
+	}
+	record = append(record, ts, lvl.String(), msg)
+	record = append(record, enc.values...)
+	var extras string
+	if len(enc.extras.bytes) > 0 {
+		extras = "{" + string(enc.extras.bytes) + "}"
+	}
+	record = append(record, extras)
+
+	bs, err := enc.record(record)
+	if err != nil {
+		return err
+	}
+	n, err := sink.Write(bs)
+	if err != nil {
+		return err
+	}
+	if n != len(bs) {
+		return fmt.Errorf("incomplete write: only wrote %v of %v bytes", n, len(bs))
+	}
+	return nil
+}
+
+func (enc *csvEncoder) record(values []string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	w.Comma = enc.comma
+	if err := w.Write(values); err != nil {
+		return nil, err
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func (enc *csvEncoder) setColumn(key, val string) bool {
+	idx, ok := enc.columns[key]
+	if ok {
+		enc.values[idx] = val
+	}
+	return ok
+}
+
+// A CSVOption is used to set options for a CSV encoder.
+type CSVOption interface {
+	apply(*csvEncoder)
+}
+
+type csvOptionFunc func(*csvEncoder)
+
+func (opt csvOptionFunc) apply(enc *csvEncoder) {
+	opt(enc)
+}
+
+// CSVColumns adds a column for each of the supplied field keys, in order,
+// between the message and "fields" columns. Duplicate keys are ignored.
+func CSVColumns(keys ...string) CSVOption {
+	return csvOptionFunc(func(enc *csvEncoder) {
+		for _, k := range keys {
+			if _, ok := enc.columns[k]; ok {
+				continue
+			}
+			enc.columns[k] = len(enc.keys)
+			enc.keys = append(enc.keys, k)
+		}
+	})
+}
+
+// CSVDelimiter sets the field delimiter; for example, use '\t' to write TSV.
+// The delimiter must not be a quote, a line break, or the Unicode replacement
+// character.
+func CSVDelimiter(comma rune) CSVOption {
+	return csvOptionFunc(func(enc *csvEncoder) {
+		enc.comma = comma
+	})
+}
+
+// CSVTimeFormat sets the format for the time column, using the same layout
+// strings supported by time.Parse. An empty layout leaves the column empty.
+func CSVTimeFormat(layout string) CSVOption {
+	return csvOptionFunc(func(enc *csvEncoder) {
+		enc.timeFmt = layout
+	})
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/uber-go/zap/spywrite"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVEncoder(t *testing.T) {
+	enc := NewCSVEncoder(CSVColumns("user", "status", "user"), CSVTimeFormat(time.RFC3339))
+	defer enc.Free()
+	enc.AddString("user", `alice "al" smith`)
+	enc.AddInt("attempt", 2)
+
+	clone := enc.Clone()
+	clone.AddInt("status", 200)
+	clone.AddBool("cached", true)
+	Nest("req", String("path", "/a,b")).AddTo(clone)
+
+	sink := &testBuffer{}
+	require.NoError(t, clone.WriteEntry(sink, "hello, world", InfoLevel, epoch.UTC()), "Unexpected error writing entry.")
+	require.NoError(t, enc.WriteEntry(sink, "plain", WarnLevel, epoch.UTC()), "Unexpected error writing entry.")
+	assert.Equal(t, []string{
+		`1970-01-01T00:00:00Z,info,"hello, world","alice ""al"" smith",200,"{""attempt"":2,""cached"":true,""req"":{""path"":""/a,b""}}"`,
+		`1970-01-01T00:00:00Z,warn,plain,"alice ""al"" smith",,"{""attempt"":2}"`,
+	}, sink.Lines(), "Unexpected CSV output.")
+}
+
+func TestCSVEncoderColumnTypes(t *testing.T) {
+	enc := NewCSVEncoder(CSVTimeFormat(""), CSVDelimiter('\t'), CSVColumns("b", "f", "u", "p", "m", "o"))
+	defer enc.Free()
+	enc.AddBool("b", false)
+	enc.AddFloat64("f", 1.5)
+	enc.AddUint("u", 7)
+	enc.AddUintptr("p", 8)
+	Nest("m", Int("n", 1)).AddTo(enc)
+	enc.AddObject("o", []int{1, 2})
+
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "types", DebugLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "\tdebug\ttypes\tfalse\t1.5\t7\t8\t\"{\"\"n\"\":1}\"\t[1,2]\t", sink.Stripped(), "Unexpected TSV output.")
+}
+
+func TestCSVEncoderErrors(t *testing.T) {
+	enc := NewCSVEncoder(CSVColumns("o"))
+	defer enc.Free()
+	assert.Error(t, enc.AddObject("o", make(chan int)), "Expected error marshaling unsupported object.")
+	assert.Error(t, enc.WriteEntry(nil, "foo", InfoLevel, epoch), "Expected error writing to a nil sink.")
+	assert.Error(t, enc.WriteEntry(spywrite.FailWriter{}, "foo", InfoLevel, epoch), "Expected write errors to propagate.")
+
+	bad := NewCSVEncoder(CSVDelimiter('"'))
+	defer bad.Free()
+	assert.Error(t, bad.WriteEntry(&bytes.Buffer{}, "foo", InfoLevel, epoch), "Expected an error with an invalid delimiter.")
+}
+
+func TestCSVHeader(t *testing.T) {
+	assert.Equal(t, "time,level,msg,user,fields\n", string(CSVHeader(CSVColumns("user"))), "Unexpected CSV header.")
+
+	buf := &bytes.Buffer{}
+	logger := New(NewCSVEncoder(CSVTimeFormat("")), Output(NewHeaderWriteSyncer(AddSync(buf), CSVHeader())))
+	logger.Info("one")
+	logger.Info("two")
+	assert.Equal(t, "time,level,msg,fields\n,info,one,\n,info,two,\n", buf.String(), "Expected header followed by records.")
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// Key for the field added by WithDedupHash.
+const _dedupHashKey = "dedup_hash"
+
+// WithDedupHash wraps a logger so that each entry includes a "dedup_hash"
+// field: a hex-encoded FNV-1a hash of the entry's level, message, and fields
+// (including context added with With). Since the fields are sorted before
+// hashing, their order doesn't affect the hash. Downstream systems can use
+// the hash to collapse duplicate entries without constructing keys by hand.
+//
+// The wrapper doesn't know whether the underlying logger is in development
+// mode, so DFatal entries are always hashed as Error-level entries.
+func WithDedupHash(log Logger) Logger {
+	return &dedupLogger{log: log}
+}
+
+type dedupLogger struct {
+	log     Logger
+	context []Field
+}
+
+func (d *dedupLogger) With(fields ...Field) Logger {
+	context := make([]Field, 0, len(d.context)+len(fields))
+	context = append(context, d.context...)
+	context = append(context, fields...)
+	return &dedupLogger{
+		log:     d.log.With(fields...),
+		context: context,
+	}
+}
+
+func (d *dedupLogger) Check(lvl Level, msg string) *CheckedMessage {
+	switch lvl {
+	case PanicLevel, FatalLevel:
+		// Make sure that Write calls our Panic and Fatal methods.
+		return NewCheckedMessage(d, lvl, msg)
+	}
+	// Add the hash on Write, then write through the underlying logger's
+	// CheckedMessage.
+	return wrapCheckedMessage(d, d.log.Check(lvl, msg), func(cm *CheckedMessage, fields []Field) {
+		cm.Write(d.withHash(lvl, msg, fields)...)
+	})
+}
+
+func (d *dedupLogger) Log(lvl Level, msg string, fields ...Field) {
+	switch lvl {
+	case PanicLevel, FatalLevel:
+		d.log.Log(lvl, msg, d.withHash(lvl, msg, fields)...)
+	default:
+		d.write(lvl, msg, fields)
+	}
+}
+
+func (d *dedupLogger) Debug(msg string, fields ...Field) {
+	d.write(DebugLevel, msg, fields)
+}
+
+func (d *dedupLogger) Info(msg string, fields ...Field) {
+	d.write(InfoLevel, msg, fields)
+}
+
+func (d *dedupLogger) Warn(msg string, fields ...Field) {
+	d.write(WarnLevel, msg, fields)
+}
+
+func (d *dedupLogger) Error(msg string, fields ...Field) {
+	d.write(ErrorLevel, msg, fields)
+}
+
+func (d *dedupLogger) Panic(msg string, fields ...Field) {
+	d.log.Panic(msg, d.withHash(PanicLevel, msg, fields)...)
+}
+
+func (d *dedupLogger) Fatal(msg string, fields ...Field) {
+	d.log.Fatal(msg, d.withHash(FatalLevel, msg, fields)...)
+}
+
+func (d *dedupLogger) DFatal(msg string, fields ...Field) {
+	d.log.DFatal(msg, d.withHash(ErrorLevel, msg, fields)...)
+}
+
+// write hashes and logs the entry only if the underlying logger accepts it,
+// so that entries at disabled levels aren't hashed.
+func (d *dedupLogger) write(lvl Level, msg string, fields []Field) {
+	if cm := d.log.Check(lvl, msg); cm.OK() {
+		cm.Write(d.withHash(lvl, msg, fields)...)
+	}
+}
+
+// withHash appends the hash to the fields.
+func (d *dedupLogger) withHash(lvl Level, msg string, fields []Field) []Field {
+	all := make([]Field, 0, len(fields)+1)
+	all = append(all, fields...)
+	return append(all, d.hash(lvl, msg, fields))
+}
+
+func (d *dedupLogger) hash(lvl Level, msg string, fields []Field) Field {
+	// Serialize each field separately, so that we can sort them.
+	enc := NewJSONEncoder().(*jsonEncoder)
+	encoded := make([]string, 0, len(d.context)+len(fields))
+	for _, fs := range [][]Field{d.context, fields} {
+		for _, f := range fs {
+			enc.truncate()
+			f.AddTo(enc)
+			if len(enc.bytes) > 0 {
+				encoded = append(encoded, string(enc.bytes))
+			}
+		}
+	}
+	enc.Free()
+	sort.Strings(encoded)
+
+	h := fnv.New64a()
+	h.Write([]byte(lvl.String()))
+	h.Write([]byte{0})
+	h.Write([]byte(msg))
+	for _, s := range encoded {
+		h.Write([]byte{0})
+		h.Write([]byte(s))
+	}
+	return String(_dedupHashKey, strconv.FormatUint(h.Sum64(), 16))
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDedupHash(t *testing.T) {
+	inner, sink := spy.New(zap.DebugLevel)
+	log := zap.WithDedupHash(inner)
+
+	log.Info("hello", zap.String("foo", "bar"), zap.Int("n", 1))
+	log.With(zap.Int("n", 1)).Info("hello", zap.String("foo", "bar"))
+	log.Info("hello", zap.String("foo", "baz"), zap.Int("n", 1))
+	log.Warn("hello", zap.String("foo", "bar"), zap.Int("n", 1))
+	log.Info("goodbye", zap.String("foo", "bar"), zap.Int("n", 1))
+	log.Check(zap.InfoLevel, "hello").Write(zap.Int("n", 1), zap.String("foo", "bar"))
+
+	logs := sink.Logs()
+	require.Equal(t, 6, len(logs), "Unexpected number of logs.")
+	hashes := make([]zap.Field, len(logs))
+	for i, l := range logs {
+		hashes[i] = l.Fields[len(l.Fields)-1]
+	}
+	assert.Equal(t, hashes[0], hashes[1], "Expected context to be included in the hash.")
+	assert.Equal(t, hashes[0], hashes[5], "Expected field order not to affect the hash.")
+	assert.NotEqual(t, hashes[0], hashes[2], "Expected field values to affect the hash.")
+	assert.NotEqual(t, hashes[0], hashes[3], "Expected level to affect the hash.")
+	assert.NotEqual(t, hashes[0], hashes[4], "Expected message to affect the hash.")
+}
+
+func TestWithDedupHashKey(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := zap.WithDedupHash(zap.New(zap.NewJSONEncoder(zap.NoTime()), zap.Output(zap.AddSync(buf))))
+	log.Info("hello")
+	assert.Regexp(t, `^{"level":"info","msg":"hello","dedup_hash":"[0-9a-f]+"}`, buf.String(), "Expected hex-encoded hash field.")
+}
+
+type countingMarshaler struct{ calls int }
+
+func (c *countingMarshaler) MarshalLog(kv zap.KeyValue) error {
+	c.calls++
+	return nil
+}
+
+func TestWithDedupHashDisabledLevels(t *testing.T) {
+	inner, sink := spy.New(zap.InfoLevel)
+	log := zap.WithDedupHash(inner)
+	counter := &countingMarshaler{}
+	log.Debug("disabled", zap.Marshaler("counter", counter))
+	assert.Equal(t, 0, counter.calls, "Expected disabled entries not to be hashed.")
+	assert.Empty(t, sink.Logs(), "Expected disabled entries not to be logged.")
+}
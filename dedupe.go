@@ -0,0 +1,218 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"time"
+)
+
+// Key for the field added to summaries by Dedupe.
+const _repeatedKey = "repeated"
+
+// Dedupe wraps a logger so that runs of consecutive identical entries are
+// collapsed, like syslog's "last message repeated N times." Entries are
+// identical if they have the same level, message, and fields (including
+// context added with With), compared by their encoded values.
+//
+// The first entry in a run is logged immediately, and repeats are suppressed.
+// When the run ends, either because a different entry is logged or because an
+// identical entry arrives more than window after the run started, the last
+// suppressed entry is logged once more with a "repeated" field counting the
+// suppressed entries. Since the wrapper doesn't start any goroutines, a run
+// that's still in progress is only summarized by the next entry or by Close.
+//
+// Parent and child loggers share the same run, so repeats are detected across
+// the whole family. Panic, Fatal, and DFatal entries are never suppressed.
+func Dedupe(log Logger, window time.Duration) Logger {
+	return &dedupeLogger{
+		log:   log,
+		state: &dedupeState{window: window},
+	}
+}
+
+type dedupeLogger struct {
+	log     Logger
+	state   *dedupeState
+	context []Field
+}
+
+// dedupeState tracks the current run of identical entries.
+type dedupeState struct {
+	sync.Mutex
+	window time.Duration
+
+	key   string
+	start time.Time
+	count int
+	// The last suppressed entry.
+	log    Logger
+	lvl    Level
+	msg    string
+	fields []Field
+}
+
+func (d *dedupeLogger) With(fields ...Field) Logger {
+	context := make([]Field, 0, len(d.context)+len(fields))
+	context = append(context, d.context...)
+	context = append(context, fields...)
+	return &dedupeLogger{
+		log:     d.log.With(fields...),
+		state:   d.state,
+		context: context,
+	}
+}
+
+func (d *dedupeLogger) Check(lvl Level, msg string) *CheckedMessage {
+	switch lvl {
+	case PanicLevel, FatalLevel:
+		// Make sure that Write calls our Panic and Fatal methods.
+		return NewCheckedMessage(d, lvl, msg)
+	}
+	// Deduplicate on Write, then write through the underlying logger's
+	// CheckedMessage.
+	return wrapCheckedMessage(d, d.log.Check(lvl, msg), func(cm *CheckedMessage, fields []Field) {
+		if d.admit(lvl, msg, fields) {
+			cm.Write(fields...)
+		}
+	})
+}
+
+func (d *dedupeLogger) Log(lvl Level, msg string, fields ...Field) {
+	switch lvl {
+	case PanicLevel, FatalLevel:
+		d.flush()
+		d.log.Log(lvl, msg, fields...)
+	default:
+		d.write(lvl, msg, fields)
+	}
+}
+
+func (d *dedupeLogger) Debug(msg string, fields ...Field) {
+	d.write(DebugLevel, msg, fields)
+}
+
+func (d *dedupeLogger) Info(msg string, fields ...Field) {
+	d.write(InfoLevel, msg, fields)
+}
+
+func (d *dedupeLogger) Warn(msg string, fields ...Field) {
+	d.write(WarnLevel, msg, fields)
+}
+
+func (d *dedupeLogger) Error(msg string, fields ...Field) {
+	d.write(ErrorLevel, msg, fields)
+}
+
+func (d *dedupeLogger) Panic(msg string, fields ...Field) {
+	d.flush()
+	d.log.Panic(msg, fields...)
+}
+
+func (d *dedupeLogger) Fatal(msg string, fields ...Field) {
+	d.flush()
+	d.log.Fatal(msg, fields...)
+}
+
+func (d *dedupeLogger) DFatal(msg string, fields ...Field) {
+	d.flush()
+	d.log.DFatal(msg, fields...)
+}
+
+func (d *dedupeLogger) Close() error {
+	d.flush()
+	return Close(d.log)
+}
+
+// write logs the entry if the underlying logger accepts it and it doesn't
+// repeat the current run.
+func (d *dedupeLogger) write(lvl Level, msg string, fields []Field) {
+	if cm := d.log.Check(lvl, msg); cm.OK() && d.admit(lvl, msg, fields) {
+		cm.Write(fields...)
+	}
+}
+
+// admit reports whether the entry should be logged, summarizing the previous
+// run if this entry ends it. It's only called for entries the underlying
+// logger accepted, so entries at disabled levels (or dropped by a sampler)
+// are neither logged nor counted, and they don't end runs.
+func (d *dedupeLogger) admit(lvl Level, msg string, fields []Field) bool {
+	key := d.key(lvl, msg, fields)
+	now := _timeNow()
+
+	s := d.state
+	s.Lock()
+	if key == s.key && now.Sub(s.start) <= s.window {
+		s.count++
+		s.log, s.lvl, s.msg = d.log, lvl, msg
+		s.fields = append(s.fields[:0], fields...)
+		s.Unlock()
+		return false
+	}
+	s.summarize()
+	s.key = key
+	s.start = now
+	s.Unlock()
+	return true
+}
+
+// flush summarizes the current run, if any, and starts a new one.
+func (d *dedupeLogger) flush() {
+	s := d.state
+	s.Lock()
+	s.summarize()
+	s.key = ""
+	s.Unlock()
+}
+
+// summarize logs the last suppressed entry with a count of the suppressed
+// entries. The caller must hold the lock.
+func (s *dedupeState) summarize() {
+	if s.count == 0 {
+		return
+	}
+	fields := make([]Field, 0, len(s.fields)+1)
+	fields = append(fields, s.fields...)
+	fields = append(fields, Int(_repeatedKey, s.count))
+	s.log.Log(s.lvl, s.msg, fields...)
+
+	s.count = 0
+	s.log = nil
+	s.fields = s.fields[:0]
+}
+
+// key identifies an entry by its level, message, and encoded fields.
+func (d *dedupeLogger) key(lvl Level, msg string, fields []Field) string {
+	enc := NewJSONEncoder().(*jsonEncoder)
+	enc.bytes = append(enc.bytes, lvl.String()...)
+	enc.bytes = append(enc.bytes, 0)
+	enc.bytes = append(enc.bytes, msg...)
+	enc.bytes = append(enc.bytes, 0)
+	for _, f := range d.context {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	key := string(enc.bytes)
+	enc.Free()
+	return key
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupe(t *testing.T) {
+	defer stubNow(0)()
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		log := Dedupe(logger, time.Minute)
+		for i := 0; i < 4; i++ {
+			log.Info("tick", Int("n", 1))
+		}
+		log.Info("tick", Int("n", 2))
+		log.With(Int("n", 2)).Info("tick")
+		log.Warn("tick", Int("n", 2))
+		log.Warn("tick", Int("n", 2))
+		assert.NoError(t, Close(log), "Unexpected error closing logger.")
+
+		assert.Equal(t, []string{
+			`{"level":"info","msg":"tick","n":1}`,
+			`{"level":"info","msg":"tick","n":1,"repeated":3}`,
+			`{"level":"info","msg":"tick","n":2}`,
+			`{"level":"info","msg":"tick","n":2,"repeated":1}`,
+			`{"level":"warn","msg":"tick","n":2}`,
+			`{"level":"warn","msg":"tick","n":2,"repeated":1}`,
+		}, buf.Lines(), "Expected consecutive entries with the same encoded fields to be collapsed.")
+	})
+}
+
+func TestDedupeDisabledLevels(t *testing.T) {
+	defer stubNow(0)()
+	withJSONLogger(t, opts(InfoLevel), func(logger Logger, buf *testBuffer) {
+		log := Dedupe(logger, time.Minute)
+		counter := &countingMarshaler{}
+		log.Info("tick")
+		log.Info("tick")
+		log.Debug("disabled", Marshaler("counter", counter))
+		log.Info("tick")
+		assert.NoError(t, Close(log), "Unexpected error closing logger.")
+
+		assert.Equal(t, []string{
+			`{"level":"info","msg":"tick"}`,
+			`{"level":"info","msg":"tick","repeated":2}`,
+		}, buf.Lines(), "Expected disabled entries not to end runs.")
+		assert.Equal(t, 0, counter.calls, "Expected disabled entries not to be encoded.")
+	})
+}
+
+func TestDedupeWindow(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		log := Dedupe(logger, time.Second)
+		unstub := stubNow(0)
+		log.Info("tick")
+		log.Info("tick")
+		unstub()
+
+		defer stubNow(2 * time.Second)()
+		log.Info("tick")
+		log.Info("tick")
+		log.Info("tock")
+
+		assert.Equal(t, []string{
+			`{"level":"info","msg":"tick"}`,
+			`{"level":"info","msg":"tick","repeated":1}`,
+			`{"level":"info","msg":"tick"}`,
+			`{"level":"info","msg":"tick","repeated":1}`,
+			`{"level":"info","msg":"tock"}`,
+		}, buf.Lines(), "Expected runs to be summarized once the window elapses.")
+	})
+}
+
+func TestDedupePanic(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		log := Dedupe(logger, time.Minute)
+		log.Error("boom")
+		log.Error("boom")
+		assert.Panics(t, func() { log.Panic("boom") }, "Expected Panic to panic.")
+		assert.Panics(t, func() { log.Panic("boom") }, "Expected Panic to panic.")
+
+		assert.Equal(t, []string{
+			`{"level":"error","msg":"boom"}`,
+			`{"level":"error","msg":"boom","repeated":1}`,
+			`{"level":"panic","msg":"boom"}`,
+			`{"level":"panic","msg":"boom"}`,
+		}, buf.Lines(), "Expected Panic entries never to be suppressed.")
+	})
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sort"
+	"time"
+)
+
+// DurationBuckets assigns durations to labeled buckets, which makes latencies
+// easy to aggregate with grep. For example, buckets with boundaries of 10ms
+// and 100ms are labeled "<10ms", "10ms-100ms", and ">=100ms". Each bucket
+// includes its lower boundary and excludes its upper boundary.
+//
+// DurationBuckets are immutable, so they can be constructed once and shared.
+type DurationBuckets struct {
+	bounds []time.Duration
+	labels []string
+}
+
+// NewDurationBuckets constructs DurationBuckets with the supplied boundaries,
+// which needn't be sorted. Duplicate boundaries are ignored.
+func NewDurationBuckets(bounds ...time.Duration) *DurationBuckets {
+	sorted := append([]time.Duration(nil), bounds...)
+	sort.Sort(durations(sorted))
+	uniq := sorted[:0]
+	for i, b := range sorted {
+		if i == 0 || b != sorted[i-1] {
+			uniq = append(uniq, b)
+		}
+	}
+
+	labels := make([]string, 0, len(uniq)+1)
+	for i, b := range uniq {
+		if i == 0 {
+			labels = append(labels, "<"+b.String())
+			continue
+		}
+		labels = append(labels, uniq[i-1].String()+"-"+b.String())
+	}
+	if len(uniq) > 0 {
+		labels = append(labels, ">="+uniq[len(uniq)-1].String())
+	}
+	return &DurationBuckets{bounds: uniq, labels: labels}
+}
+
+// Label returns the label of the bucket containing the duration. If there are
+// no boundaries, it returns an empty string.
+func (b *DurationBuckets) Label(d time.Duration) string {
+	if len(b.labels) == 0 {
+		return ""
+	}
+	i := sort.Search(len(b.bounds), func(i int) bool { return b.bounds[i] > d })
+	return b.labels[i]
+}
+
+// Field constructs a field that adds the duration (as an integer number of
+// nanoseconds, like Duration) under the given key, and the label of its bucket
+// under the key plus "Bucket". For example, a "latency" field adds both
+// "latency" and "latencyBucket".
+func (b *DurationBuckets) Field(key string, d time.Duration) Field {
+	return Field{key: key, fieldType: bucketedDurationType, ival: int64(d), str: b.Label(d)}
+}
+
+// BucketedDuration is a convenience for one-off bucketing; it's equivalent to
+// NewDurationBuckets(bounds...).Field(key, d). To avoid recomputing the
+// bucket labels, construct DurationBuckets once and reuse them.
+func BucketedDuration(key string, d time.Duration, bounds ...time.Duration) Field {
+	return NewDurationBuckets(bounds...).Field(key, d)
+}
+
+type durations []time.Duration
+
+func (ds durations) Len() int           { return len(ds) }
+func (ds durations) Less(i, j int) bool { return ds[i] < ds[j] }
+func (ds durations) Swap(i, j int)      { ds[i], ds[j] = ds[j], ds[i] }
@@ -0,0 +1,54 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurationBucketsLabel(t *testing.T) {
+	b := NewDurationBuckets(100*time.Millisecond, 10*time.Millisecond, 100*time.Millisecond)
+	tests := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{0, "<10ms"},
+		{9 * time.Millisecond, "<10ms"},
+		{10 * time.Millisecond, "10ms-100ms"},
+		{99 * time.Millisecond, "10ms-100ms"},
+		{100 * time.Millisecond, ">=100ms"},
+		{time.Minute, ">=100ms"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, b.Label(tt.d), "Unexpected label for %v.", tt.d)
+	}
+	assert.Equal(t, "", NewDurationBuckets().Label(time.Second), "Expected no label without boundaries.")
+}
+
+func TestDurationBucketsField(t *testing.T) {
+	b := NewDurationBuckets(10*time.Millisecond, 100*time.Millisecond)
+	assertFieldJSON(t, `"latency":50000000,"latencyBucket":"10ms-100ms"`, b.Field("latency", 50*time.Millisecond))
+	assertFieldJSON(t, `"latency":5,"latencyBucket":"<1µs"`, BucketedDuration("latency", 5, time.Microsecond))
+	assertCanBeReused(t, b.Field("latency", time.Second))
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+// DebugEnabled reports whether the logger writes Debug-level entries. Like
+// Check, it's useful for guarding expensive field construction.
+func DebugEnabled(log Logger) bool { return enabled(log, DebugLevel) }
+
+// InfoEnabled reports whether the logger writes Info-level entries.
+func InfoEnabled(log Logger) bool { return enabled(log, InfoLevel) }
+
+// WarnEnabled reports whether the logger writes Warn-level entries.
+func WarnEnabled(log Logger) bool { return enabled(log, WarnLevel) }
+
+// ErrorEnabled reports whether the logger writes Error-level entries.
+func ErrorEnabled(log Logger) bool { return enabled(log, ErrorLevel) }
+
+// PanicEnabled reports whether the logger writes Panic-level entries. Note
+// that calling Panic always panics, even if the entry isn't written.
+func PanicEnabled(log Logger) bool { return enabled(log, PanicLevel) }
+
+// FatalEnabled reports whether the logger writes Fatal-level entries. Note
+// that calling Fatal always exits, even if the entry isn't written.
+func FatalEnabled(log Logger) bool { return enabled(log, FatalLevel) }
+
+// enabled is the single source of truth for the level predicates. Loggers
+// that implement LevelEnabler (including all loggers created with New and
+// Tee) are asked directly. Others are assumed to be enabled: probing them with
+// Check could have side effects, like spending a sampler's budget.
+func enabled(log Logger, lvl Level) bool {
+	if le, ok := log.(LevelEnabler); ok {
+		return le.Enabled(lvl)
+	}
+	return true
+}
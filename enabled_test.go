@@ -0,0 +1,66 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap_test
+
+import (
+	"testing"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelPredicates(t *testing.T) {
+	predicates := []struct {
+		lvl     zap.Level
+		enabled func(zap.Logger) bool
+	}{
+		{zap.DebugLevel, zap.DebugEnabled},
+		{zap.InfoLevel, zap.InfoEnabled},
+		{zap.WarnLevel, zap.WarnEnabled},
+		{zap.ErrorLevel, zap.ErrorEnabled},
+		{zap.PanicLevel, zap.PanicEnabled},
+		{zap.FatalLevel, zap.FatalEnabled},
+	}
+
+	info := zap.New(zap.NullEncoder(), zap.InfoLevel)
+	errLog, _ := spy.New(zap.ErrorLevel)
+	tee := zap.Tee(zap.New(zap.NullEncoder(), zap.WarnLevel), errLog)
+	for _, p := range predicates {
+		assert.Equal(t, p.lvl >= zap.InfoLevel, p.enabled(info), "Unexpected result from predicate for %v.", p.lvl)
+		assert.Equal(t, p.lvl >= zap.WarnLevel, p.enabled(tee), "Unexpected result from predicate for %v on Tee.", p.lvl)
+	}
+}
+
+func TestLevelPredicatesWithLazy(t *testing.T) {
+	log := zap.WithLazy(zap.New(zap.NullEncoder(), zap.WarnLevel))
+	assert.False(t, zap.InfoEnabled(log), "Expected Info to be disabled.")
+	assert.True(t, zap.WarnEnabled(log), "Expected Warn to be enabled.")
+}
+
+func TestLevelPredicatesAssumeEnabled(t *testing.T) {
+	// Loggers that aren't LevelEnablers aren't probed with Check, since that
+	// could have side effects.
+	base, _ := spy.New(zap.ErrorLevel)
+	log := zap.Chain(base)
+	assert.True(t, zap.DebugEnabled(log), "Expected loggers that aren't LevelEnablers to be treated as enabled.")
+}
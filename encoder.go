@@ -21,8 +21,21 @@
 package zap
 
 import (
+	"bytes"
 	"io"
+	"math"
+	"strconv"
 	"time"
+	"unicode/utf8"
+)
+
+const (
+	// Appended to messages truncated by the MaxMessageLength options.
+	_truncatedSuffix = "..."
+	// Key for the original length of a truncated message.
+	_truncatedLengthKey = "originalMsgLength"
+	// Key for the marker added by the MaxFields options.
+	_fieldsTruncatedKey = "fieldsTruncated"
 )
 
 // Encoder is a format-agnostic interface for all log entry marshalers. Since
@@ -46,3 +59,164 @@ type Encoder interface {
 	// any accumulated context.
 	WriteEntry(io.Writer, string, Level, time.Time) error
 }
+
+// FieldsAdder is an optional interface for Encoders that need to see all the
+// fields in an entry at once, rather than one at a time. If an Encoder
+// implements FieldsAdder, loggers use AddFields instead of calling Field.AddTo
+// for each field. It's most useful in combination with the DeferFields option,
+// which makes loggers pass context and log-site fields together.
+type FieldsAdder interface {
+	AddFields([]Field)
+}
+
+// truncateMessage shortens the message to at most max bytes (plus a suffix),
+// taking care not to split a multi-byte rune. It reports whether the message
+// was truncated.
+func truncateMessage(msg string, max int) (string, bool) {
+	if max <= 0 || len(msg) <= max {
+		return msg, false
+	}
+	for max > 0 && !utf8.RuneStart(msg[max]) {
+		max--
+	}
+	return msg[:max] + _truncatedSuffix, true
+}
+
+// A localTime is an additional representation of the entry time, added by the
+// AddLocalTime options.
+type localTime struct {
+	key string
+	loc *time.Location
+}
+
+func newLocalTime(loc *time.Location, key string) localTime {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return localTime{key: key, loc: loc}
+}
+
+// setMessageKey changes the key under which an encoder writes log messages,
+// if the encoder supports it. See WithMessageKey.
+func setMessageKey(enc Encoder, key string) {
+	if mk, ok := enc.(interface {
+		setMessageKey(string)
+	}); ok {
+		mk.setMessageKey(key)
+	}
+}
+
+// addUnlimitedString adds a string field that doesn't count toward the
+// MaxFields limit, if the encoder supports it, and an ordinary string field
+// otherwise.
+func addUnlimitedString(enc Encoder, key, val string) {
+	if ul, ok := enc.(interface {
+		addUnlimitedString(string, string)
+	}); ok {
+		ul.addUnlimitedString(key, val)
+		return
+	}
+	enc.AddString(key, val)
+}
+
+// fieldLimit enforces the MaxFields options. Only top-level fields count
+// toward the limit; fields nested inside a LogMarshaler are encoded as part of
+// their parent.
+type fieldLimit struct {
+	max   int
+	count int
+	depth int
+	// Number of top-level fields dropped after reaching the limit.
+	dropped int
+}
+
+// admit reports whether another field may be encoded, recording the field if
+// so.
+func (l *fieldLimit) admit() bool {
+	if l.max <= 0 || l.depth > 0 {
+		return true
+	}
+	if l.count >= l.max {
+		l.dropped++
+		return false
+	}
+	l.count++
+	return true
+}
+
+// hoistedMetrics collects the values of the numeric fields named by the
+// HoistMetrics option.
+type hoistedMetrics struct {
+	keys []string
+	// Parallel to keys, allocated lazily.
+	vals []metricValue
+}
+
+type metricKind uint8
+
+const (
+	metricZero metricKind = iota
+	metricInt
+	metricUint
+	metricFloat
+)
+
+type metricValue struct {
+	kind metricKind
+	i    int64
+	u    uint64
+	f    float64
+}
+
+// set records the value if the key is hoisted, reporting whether it was.
+func (m *hoistedMetrics) set(key string, val metricValue) bool {
+	for i, k := range m.keys {
+		if k != key {
+			continue
+		}
+		if m.vals == nil {
+			m.vals = make([]metricValue, len(m.keys))
+		}
+		m.vals[i] = val
+		return true
+	}
+	return false
+}
+
+func (m hoistedMetrics) clone() hoistedMetrics {
+	if m.vals == nil {
+		return m
+	}
+	vals := make([]metricValue, len(m.vals))
+	copy(vals, m.vals)
+	return hoistedMetrics{keys: m.keys, vals: vals}
+}
+
+// appendValue appends the i-th metric's value as a JSON number (or null).
+func (m hoistedMetrics) appendValue(bs []byte, i int) []byte {
+	var val metricValue
+	if m.vals != nil {
+		val = m.vals[i]
+	}
+	switch val.kind {
+	case metricInt:
+		return strconv.AppendInt(bs, val.i, 10)
+	case metricUint:
+		return strconv.AppendUint(bs, val.u, 10)
+	case metricFloat:
+		if math.IsNaN(val.f) || math.IsInf(val.f, 0) {
+			return append(bs, "null"...)
+		}
+		return strconv.AppendFloat(bs, val.f, 'f', -1, 64)
+	default:
+		return append(bs, '0')
+	}
+}
+
+// splitLineEnding splits an encoded entry into its content and its
+// terminator, so that wrapping encoders can preserve the wrapped encoder's
+// LineEnding.
+func splitLineEnding(line []byte) (content, ending []byte) {
+	content = bytes.TrimRight(line, "\r\n")
+	return content, line[len(content):]
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Enrich wraps a logger so that entries with the named field are enriched
+// with the fields returned by lookup, which is passed the field's value: for
+// example, mapping a "user_id" field to the user's plan tier. The field may
+// be added with With or at the log site; if it appears more than once, the
+// last value is used. String, integer, boolean, and Stringer values are
+// passed to lookup in their string forms, and entries whose field holds any
+// other type aren't enriched. The looked-up fields are added after the
+// entry's other fields.
+//
+// Enrich is built on Chain, so it shares Chain's handling of context, Panic,
+// and Fatal. The lookup runs on the logging goroutine for every entry that
+// has the field, so it must be fast and safe for concurrent use; callers
+// should cache lookups against slow stores themselves.
+func Enrich(log Logger, key string, lookup func(value string) []Field) Logger {
+	return Chain(log, func(e Entry, fields []Field) (Entry, []Field, bool) {
+		value, ok := lastFieldString(fields, key)
+		if !ok {
+			return e, fields, true
+		}
+		extra := lookup(value)
+		if len(extra) == 0 {
+			return e, fields, true
+		}
+		// Don't append to the caller's slice.
+		enriched := make([]Field, 0, len(fields)+len(extra))
+		enriched = append(enriched, fields...)
+		return e, append(enriched, extra...), true
+	})
+}
+
+// lastFieldString returns the string form of the last field with the given
+// key, if it has one.
+func lastFieldString(fields []Field, key string) (string, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		f := fields[i]
+		if f.key != key {
+			continue
+		}
+		switch f.fieldType {
+		case stringType:
+			return f.str, true
+		case intType, int64Type:
+			return strconv.FormatInt(f.ival, 10), true
+		case uintType, uint64Type:
+			return strconv.FormatUint(uint64(f.ival), 10), true
+		case boolType:
+			return strconv.FormatBool(f.ival == 1), true
+		case stringerType:
+			return f.obj.(fmt.Stringer).String(), true
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
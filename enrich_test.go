@@ -0,0 +1,82 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap_test
+
+import (
+	"testing"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnrich(t *testing.T) {
+	plans := map[string]string{"1": "free", "2": "pro"}
+	var lookups []string
+	lookup := func(id string) []zap.Field {
+		lookups = append(lookups, id)
+		if plan, ok := plans[id]; ok {
+			return []zap.Field{zap.String("plan", plan)}
+		}
+		return nil
+	}
+
+	base, sink := spy.New(zap.DebugLevel)
+	log := zap.Enrich(base, "user_id", lookup)
+	log.Info("no user")
+	log.Info("site", zap.Int("user_id", 1))
+	log.With(zap.String("user_id", "2")).Warn("context")
+	log.With(zap.String("user_id", "1")).Info("site wins", zap.String("user_id", "2"))
+	log.Info("unknown", zap.String("user_id", "3"))
+	log.Info("not a scalar", zap.Object("user_id", []int{1}))
+
+	assert.Equal(t, []string{"1", "2", "2", "3"}, lookups, "Unexpected lookups.")
+	var plansLogged []interface{}
+	for _, l := range sink.Logs() {
+		plansLogged = append(plansLogged, l.FieldMap()["plan"])
+	}
+	assert.Equal(t, []interface{}{nil, "free", "pro", "pro", nil, nil}, plansLogged, "Unexpected enrichment.")
+}
+
+func TestEnrichDoesNotModifyFields(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	log := zap.Enrich(base, "id", func(string) []zap.Field {
+		return []zap.Field{zap.Bool("enriched", true)}
+	})
+	fields := make([]zap.Field, 1, 2)
+	fields[0] = zap.String("id", "x")
+	log.Info("hello", fields...)
+
+	assert.Equal(t, zap.Field{}, fields[:2][1], "Expected the caller's slice not to be modified.")
+	assert.Equal(t, map[string]interface{}{"id": "x", "enriched": true}, sink.Logs()[0].FieldMap(), "Unexpected fields.")
+}
+
+func TestEnrichSkipsDisabledLevels(t *testing.T) {
+	lookups := 0
+	lookup := func(string) []zap.Field {
+		lookups++
+		return nil
+	}
+	base, _ := spy.New(zap.InfoLevel)
+	zap.Enrich(base, "user_id", lookup).Debug("disabled", zap.String("user_id", "1"))
+	assert.Equal(t, 0, lookups, "Expected no lookups for disabled levels.")
+}
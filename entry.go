@@ -40,10 +40,13 @@ type Entry struct {
 	Level   Level
 	Time    time.Time
 	Message string
-	enc     Encoder
+	enc     KeyValue
+	// callerSkip is the number of extra frames that caller-capturing hooks
+	// skip, for entries logged on the caller's behalf by helpers like LogErr.
+	callerSkip int
 }
 
-func newEntry(lvl Level, msg string, enc Encoder) *Entry {
+func newEntry(lvl Level, msg string, enc KeyValue) *Entry {
 	e := _entryPool.Get().(*Entry)
 	e.Level = lvl
 	e.Message = msg
@@ -57,6 +60,10 @@ func (e *Entry) Fields() KeyValue {
 	return e.enc
 }
 
+// free resets the entry and returns it to the pool. Resetting drops the
+// entry's references to its message and encoder, so pooled entries don't keep
+// them alive.
 func (e *Entry) free() {
+	*e = Entry{}
 	_entryPool.Put(e)
 }
@@ -41,3 +41,10 @@ func TestNewEntry(t *testing.T) {
 	assert.Equal(t, time.Unix(0, 0).UTC(), e.Time, "Unexpected time.")
 	assert.Nil(t, e.Fields(), "Unexpected fields.")
 }
+
+func TestEntryFree(t *testing.T) {
+	enc := NewJSONEncoder()
+	e := newEntry(InfoLevel, "hello", enc)
+	e.free()
+	assert.Equal(t, Entry{}, *e, "Expected free to reset the entry.")
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Event constructs a Field that nests an analytics event under the key
+// "event". The event is encoded as an object with the event's name under the
+// "name" key and a nested object of its properties under the "properties"
+// key.
+//
+// Property values must be bools, strings, numeric types, time.Durations,
+// time.Times, errors, fmt.Stringers, or LogMarshalers. Properties are encoded
+// in key order. Properties of any other type are left out, and the field adds
+// an "eventError" key describing them next to the event. Marshaling is lazy,
+// so the map must not be modified until the field is marshaled.
+func Event(name string, properties map[string]interface{}) Field {
+	return Marshaler("event", event{name: name, properties: properties})
+}
+
+type event struct {
+	name       string
+	properties map[string]interface{}
+}
+
+func (e event) MarshalLog(kv KeyValue) error {
+	kv.AddString("name", e.name)
+	return kv.AddMarshaler("properties", eventProperties(e.properties))
+}
+
+type eventProperties map[string]interface{}
+
+func (ps eventProperties) MarshalLog(kv KeyValue) error {
+	keys := make([]string, 0, len(ps))
+	for k := range ps {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs multiError
+	for _, k := range keys {
+		f, err := eventProperty(k, ps[k])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		f.AddTo(kv)
+	}
+	return errs.asError()
+}
+
+func eventProperty(key string, val interface{}) (Field, error) {
+	switch v := val.(type) {
+	case bool:
+		return Bool(key, v), nil
+	case string:
+		return String(key, v), nil
+	case int:
+		return Int(key, v), nil
+	case int8:
+		return Int64(key, int64(v)), nil
+	case int16:
+		return Int64(key, int64(v)), nil
+	case int32:
+		return Int64(key, int64(v)), nil
+	case int64:
+		return Int64(key, v), nil
+	case uint:
+		return Uint(key, v), nil
+	case uint8:
+		return Uint64(key, uint64(v)), nil
+	case uint16:
+		return Uint64(key, uint64(v)), nil
+	case uint32:
+		return Uint64(key, uint64(v)), nil
+	case uint64:
+		return Uint64(key, v), nil
+	case uintptr:
+		return Uintptr(key, v), nil
+	case float32:
+		return Float64(key, float64(v)), nil
+	case float64:
+		return Float64(key, v), nil
+	case time.Duration:
+		return Duration(key, v), nil
+	case time.Time:
+		return Time(key, v), nil
+	case LogMarshaler:
+		return Marshaler(key, v), nil
+	case error:
+		return String(key, v.Error()), nil
+	case fmt.Stringer:
+		return Stringer(key, v), nil
+	default:
+		return Skip(), fmt.Errorf("unsupported type %T for event property %q", val, key)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventField(t *testing.T) {
+	props := map[string]interface{}{
+		"sku":      "1234",
+		"qty":      2,
+		"price":    9.99,
+		"gift":     false,
+		"big":      uint64(1 << 40),
+		"small":    int8(-3),
+		"elapsed":  time.Millisecond,
+		"at":       time.Unix(1, 0),
+		"err":      errors.New("fail"),
+		"customer": fakeUser{"phil"},
+	}
+	assertFieldJSON(t,
+		`"event":{"name":"purchase","properties":{`+
+			`"at":1,"big":1099511627776,"customer":{"name":"phil"},"elapsed":1000000,"err":"fail",`+
+			`"gift":false,"price":9.99,"qty":2,"sku":"1234","small":-3}}`,
+		Event("purchase", props))
+	assertCanBeReused(t, Event("purchase", props))
+}
+
+func TestEventFieldEmpty(t *testing.T) {
+	assertFieldJSON(t, `"event":{"name":"signup","properties":{}}`, Event("signup", nil))
+}
+
+func TestEventFieldUnsupportedType(t *testing.T) {
+	props := map[string]interface{}{
+		"ok":  "yes",
+		"bad": []int{1},
+	}
+	assertFieldJSON(t,
+		`"event":{"name":"purchase","properties":{"ok":"yes"}},`+
+			`"eventError":"unsupported type []int for event property \"bad\" "`,
+		Event("purchase", props))
+}
+
+func TestEventFieldText(t *testing.T) {
+	withTextEncoder(func(enc *textEncoder) {
+		Event("purchase", map[string]interface{}{"qty": 2}).AddTo(enc)
+		assert.Equal(t, "event={name=purchase properties={qty=2}}", string(enc.bytes), "Unexpected text output for event.")
+	})
+}
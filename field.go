@@ -22,11 +22,20 @@ package zap
 
 import (
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"math"
+	"math/big"
+	"net"
+	"reflect"
+	"runtime"
+	"strconv"
 	"time"
 )
 
+// _hexDumpMax is the number of bytes HexDump renders before truncating.
+const _hexDumpMax = 4096
+
 type fieldType int
 
 const (
@@ -44,6 +53,9 @@ const (
 	stringerType
 	errorType
 	skipType
+	onceType
+	rawJSONType
+	bucketedDurationType
 )
 
 // A Field is a marshaling operation used to add a key-value pair to a logger's
@@ -62,12 +74,75 @@ func Skip() Field {
 	return Field{fieldType: skipType}
 }
 
+// OmitEmpty wraps a Field so that it's dropped when it holds its type's zero
+// value: false, zero numbers (including zero durations and negative zero),
+// empty strings, and nil objects, marshalers, and stringers (including typed
+// nils, like a nil pointer stored in a fmt.Stringer). Other fields are
+// returned unchanged. It's useful for keeping sparse structured logs tidy:
+//
+//	logger.Info("request", zap.OmitEmpty(zap.String("user", user)))
+func OmitEmpty(f Field) Field {
+	switch f.fieldType {
+	case boolType, intType, int64Type, uintType, uint64Type, uintptrType:
+		if f.ival == 0 {
+			return Skip()
+		}
+	case floatType:
+		if math.Float64frombits(uint64(f.ival)) == 0 {
+			return Skip()
+		}
+	case stringType:
+		if f.str == "" {
+			return Skip()
+		}
+	case marshalerType, objectType, stringerType:
+		if isNil(f.obj) {
+			return Skip()
+		}
+	}
+	return f
+}
+
+// isNil reports whether v is nil, or an interface holding a nil pointer, map,
+// slice, channel, or function.
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		return rv.IsNil()
+	}
+	return false
+}
+
 // Base64 constructs a field that encodes the given value as a padded base64
 // string. The byte slice is converted to a base64 string eagerly.
 func Base64(key string, val []byte) Field {
 	return String(key, base64.StdEncoding.EncodeToString(val))
 }
 
+// HexDump constructs a field that renders a byte slice like hexdump -C. Text
+// encoders render the dump as a multi-line block of offsets, hex, and ASCII,
+// while JSON encoders render it as a single hex string. At most 4KiB of the
+// slice is included; use HexDumpN to choose a different limit.
+//
+// The byte slice isn't copied, so it must not be modified until the field is
+// marshaled.
+func HexDump(key string, val []byte) Field {
+	return HexDumpN(key, val, _hexDumpMax)
+}
+
+// HexDumpN is like HexDump, but includes at most max bytes of the slice. A
+// negative max disables truncation.
+func HexDumpN(key string, val []byte, max int) Field {
+	total := len(val)
+	if max >= 0 && total > max {
+		val = val[:max]
+	}
+	return Object(key, hexDump{bytes: val, total: total})
+}
+
 // Bool constructs a Field with the given key and value. Bools are marshaled
 // lazily.
 func Bool(key string, val bool) Field {
@@ -107,6 +182,24 @@ func Uint64(key string, val uint64) Field {
 	return Field{key: key, fieldType: uint64Type, ival: int64(val)}
 }
 
+// BigInt constructs a Field with the given key and value. Since big integers
+// may exceed the range of any fixed-size number, the value is encoded as a
+// base-10 string. Like Stringers, BigInts are marshaled lazily, so the value
+// must not be modified until the field is marshaled.
+func BigInt(key string, val *big.Int) Field {
+	return Stringer(key, val)
+}
+
+// BigFloat constructs a Field with the given key and value. The value is
+// eagerly converted to the shortest decimal string that represents it exactly
+// at its precision.
+func BigFloat(key string, val *big.Float) Field {
+	if val == nil {
+		return String(key, "<nil>")
+	}
+	return String(key, val.Text('g', -1))
+}
+
 // Uintptr constructs a Field with the given key and value.
 func Uintptr(key string, val uintptr) Field {
 	return Field{key: key, fieldType: uintptrType, ival: int64(val)}
@@ -123,6 +216,71 @@ func Stringer(key string, val fmt.Stringer) Field {
 	return Field{key: key, fieldType: stringerType, obj: val}
 }
 
+// IP constructs a Field with the given key and the canonical string form of
+// the IP address (e.g., "192.0.2.1" or "2001:db8::1"). Nil IPs are encoded as
+// null (or the encoder's equivalent). Like Stringer, the address is formatted
+// lazily, so it's inexpensive to add to disabled log statements.
+func IP(key string, ip net.IP) Field {
+	if ip == nil {
+		return nilField(key)
+	}
+	return Stringer(key, ip)
+}
+
+// Addr constructs a Field with the given key and the string form of the
+// network address (e.g., "192.0.2.1:80"). Nil addresses are encoded as null
+// (or the encoder's equivalent). The address is formatted lazily.
+func Addr(key string, addr net.Addr) Field {
+	if addr == nil {
+		return nilField(key)
+	}
+	return Stringer(key, addr)
+}
+
+// Boolp constructs a Field with the given key and the value pointed to by val.
+// Nil pointers are encoded as null (or the encoder's equivalent). The pointer
+// is dereferenced eagerly.
+func Boolp(key string, val *bool) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Bool(key, *val)
+}
+
+// Float64p constructs a Field with the given key and the value pointed to by
+// val. Nil pointers are encoded as null (or the encoder's equivalent). The
+// pointer is dereferenced eagerly.
+func Float64p(key string, val *float64) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Float64(key, *val)
+}
+
+// Intp constructs a Field with the given key and the value pointed to by val.
+// Nil pointers are encoded as null (or the encoder's equivalent). The pointer
+// is dereferenced eagerly.
+func Intp(key string, val *int) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Int(key, *val)
+}
+
+// Stringp constructs a Field with the given key and the value pointed to by
+// val. Nil pointers are encoded as null (or the encoder's equivalent). The
+// pointer is dereferenced eagerly.
+func Stringp(key string, val *string) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return String(key, *val)
+}
+
+func nilField(key string) Field {
+	return Object(key, nil)
+}
+
 // Time constructs a Field with the given key and value. It represents a
 // time.Time as a floating-point number of seconds since epoch. Conversion to a
 // float64 happens eagerly.
@@ -132,6 +290,9 @@ func Time(key string, val time.Time) Field {
 
 // Error constructs a Field that lazily stores err.Error() under the key
 // "error". If passed a nil error, the field is a no-op.
+//
+// If err (or any error it wraps) implements FieldsError, its fields are also
+// added in a nested object under the key "errorFields".
 func Error(err error) Field {
 	if err == nil {
 		return Skip()
@@ -139,6 +300,38 @@ func Error(err error) Field {
 	return Field{key: "error", fieldType: errorType, obj: err}
 }
 
+// FieldsError is implemented by errors that carry structured context, like a
+// status code or whether the failed operation is retryable. The Error field
+// logs these fields automatically, so call sites don't need to restate them.
+type FieldsError interface {
+	error
+	Fields() []Field
+}
+
+// errorFields returns the fields of the first error in err's chain that
+// implements FieldsError. Errors are unwrapped with either an Unwrap or a
+// Cause method.
+func errorFields(err error) []Field {
+	for err != nil {
+		if fe, ok := err.(FieldsError); ok {
+			return fe.Fields()
+		}
+		switch e := err.(type) {
+		case interface {
+			Unwrap() error
+		}:
+			err = e.Unwrap()
+		case interface {
+			Cause() error
+		}:
+			err = e.Cause()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
 // Stack constructs a Field that stores a stacktrace of the current goroutine
 // under the key "stacktrace". Keep in mind that taking a stacktrace is eager
 // and extremely expensive (relatively speaking); this function both makes an
@@ -157,16 +350,43 @@ func Stack() Field {
 	return field
 }
 
+// StructuredStack is like Stack, but it represents the stacktrace as a list
+// of frames, each with a function, file, and line number. The JSON encoder
+// serializes it as an array of objects, which log platforms can index, while
+// the text encoder renders it on a single line. Like Stack, it's eager and
+// expensive.
+func StructuredStack() Field {
+	return Object("stacktrace", takeStackFrames(1))
+}
+
 // Duration constructs a Field with the given key and value. It represents
 // durations as an integer number of nanoseconds.
 func Duration(key string, val time.Duration) Field {
 	return Int64(key, int64(val))
 }
 
+// Since constructs a Duration field with the time elapsed since start. Like
+// time.Since, it's computed eagerly, but it uses the same clock as the logger.
+func Since(key string, start time.Time) Field {
+	return Duration(key, _timeNow().Sub(start))
+}
+
+// RelativeTime constructs a Duration field with the offset of t from ref,
+// which is useful for logging the events in a trace span relative to the
+// span's start. It's encoded like any other Duration.
+func RelativeTime(key string, ref, t time.Time) Field {
+	return Duration(key, t.Sub(ref))
+}
+
 // Marshaler constructs a field with the given key and zap.LogMarshaler. It
 // provides a flexible, but still type-safe and efficient, way to add
 // user-defined types to the logging context. The LogMarshaler's MarshalLog
 // method is called lazily.
+//
+// Passing a Marshaler field to a logger's With method groups a whole object
+// (for example, a request's metadata) under a single key in every entry. The
+// object is marshaled once, when With is called, unless the logger uses the
+// DeferFields option, in which case it's marshaled for each entry.
 func Marshaler(key string, val LogMarshaler) Field {
 	return Field{key: key, fieldType: marshalerType, obj: val}
 }
@@ -182,6 +402,56 @@ func Object(key string, val interface{}) Field {
 	return Field{key: key, fieldType: objectType, obj: val}
 }
 
+// MemStats constructs a field that snapshots the runtime's memory statistics:
+// the bytes allocated and not yet freed ("alloc"), the bytes in in-use heap
+// spans ("heapInuse"), the total bytes obtained from the OS ("sys"), and the
+// number of completed GC cycles ("numGC"). Since runtime.ReadMemStats briefly
+// stops the world, the snapshot is taken lazily, only when the field is
+// encoded; entries at disabled levels pay nothing. Keep in mind that fields
+// added with With are encoded immediately.
+func MemStats(key string) Field {
+	return Marshaler(key, memStats{})
+}
+
+type memStats struct{}
+
+func (memStats) MarshalLog(kv KeyValue) error {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	kv.AddUint64("alloc", ms.Alloc)
+	kv.AddUint64("heapInuse", ms.HeapInuse)
+	kv.AddUint64("sys", ms.Sys)
+	kv.AddUint("numGC", uint(ms.NumGC))
+	return nil
+}
+
+// RawJSON constructs a field that holds pre-serialized JSON, which avoids
+// re-marshaling large cached payloads. The JSON encoder writes the data
+// verbatim (compacting any line breaks), trusting that it's well-formed
+// unless the ValidateRawJSON option is set; the canonical and signed JSON
+// encoders canonicalize it along with the rest of the entry, and other
+// encoders treat it as a string. The data must not be modified until the
+// field is marshaled.
+func RawJSON(key string, data []byte) Field {
+	return Field{key: key, fieldType: rawJSONType, obj: data}
+}
+
+// rawJSONAdder is implemented by encoders that can write RawJSON fields
+// verbatim.
+type rawJSONAdder interface {
+	AddRawJSON(key string, data []byte) error
+}
+
+// addRawJSON adds the data verbatim if the KeyValue supports it, and as a
+// string otherwise.
+func addRawJSON(kv KeyValue, key string, data []byte) error {
+	if raw, ok := kv.(rawJSONAdder); ok {
+		return raw.AddRawJSON(key, data)
+	}
+	kv.AddString(key, string(data))
+	return nil
+}
+
 // Nest takes a key and a variadic number of Fields and creates a nested
 // namespace.
 func Nest(key string, fields ...Field) Field {
@@ -218,7 +488,15 @@ func (f Field) AddTo(kv KeyValue) {
 		err = kv.AddObject(f.key, f.obj)
 	case errorType:
 		kv.AddString(f.key, f.obj.(error).Error())
-	case skipType:
+		if fs := errorFields(f.obj.(error)); len(fs) > 0 {
+			err = kv.AddMarshaler(f.key+"Fields", multiFields(fs))
+		}
+	case rawJSONType:
+		err = addRawJSON(kv, f.key, f.obj.([]byte))
+	case bucketedDurationType:
+		kv.AddInt64(f.key, f.ival)
+		kv.AddString(f.key+"Bucket", f.str)
+	case skipType, onceType:
 		break
 	default:
 		panic(fmt.Sprintf("unknown field type found: %v", f))
@@ -232,12 +510,116 @@ func (f Field) AddTo(kv KeyValue) {
 type multiFields []Field
 
 func (fs multiFields) MarshalLog(kv KeyValue) error {
-	addFields(kv, []Field(fs))
+	// Nested fields are always added one at a time, even if the encoder
+	// implements FieldsAdder.
+	for _, f := range fs {
+		f.AddTo(kv)
+	}
 	return nil
 }
 
+// _mergeFieldsScanMax is the number of fields above which MergeFields uses a
+// map, rather than a quadratic scan, to find duplicate keys.
+const _mergeFieldsScanMax = 32
+
+// MergeFields combines a logger's context with the fields supplied at the log
+// site, resolving duplicate keys in favor of the last field with each key: a
+// log-site field replaces a context field with the same key, and later
+// fields replace earlier ones. The surviving fields keep their relative order.
+// Fields without a key, like those constructed with Skip, are never
+// considered duplicates.
+//
+// Loggers that keep their context as a list of fields use MergeFields, so that
+// duplicate keys are resolved the same way regardless of the encoder; loggers
+// created by New do so when the DeferFields option is set. It's intended for
+// wrapper libraries, and shouldn't be necessary in application code. The
+// supplied slices aren't modified.
+func MergeFields(context, fields []Field) []Field {
+	all := make([]Field, 0, len(context)+len(fields))
+	all = append(all, context...)
+	all = append(all, fields...)
+	if !hasDuplicateKeys(all) {
+		return all
+	}
+	last := make(map[string]int, len(all))
+	for i, f := range all {
+		last[f.key] = i
+	}
+	merged := all[:0]
+	for i, f := range all {
+		if f.key == "" || last[f.key] == i {
+			merged = append(merged, f)
+		}
+	}
+	return merged
+}
+
+func hasDuplicateKeys(fields []Field) bool {
+	if len(fields) > _mergeFieldsScanMax {
+		seen := make(map[string]struct{}, len(fields))
+		for _, f := range fields {
+			if f.key == "" {
+				continue
+			}
+			if _, ok := seen[f.key]; ok {
+				return true
+			}
+			seen[f.key] = struct{}{}
+		}
+		return false
+	}
+	for i, f := range fields {
+		if f.key == "" {
+			continue
+		}
+		for _, g := range fields[i+1:] {
+			if f.key == g.key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func addFields(kv KeyValue, fields []Field) {
+	if fa, ok := kv.(FieldsAdder); ok {
+		fa.AddFields(fields)
+		return
+	}
 	for _, f := range fields {
 		f.AddTo(kv)
 	}
 }
+
+// hexDump formats a (possibly truncated) byte slice for HexDump fields.
+type hexDump struct {
+	bytes []byte
+	total int
+}
+
+// String renders the dump as a block starting on a new line, which keeps the
+// columns aligned in text output.
+func (h hexDump) String() string {
+	buf := []byte{'\n'}
+	if dump := hex.Dump(h.bytes); len(dump) > 0 {
+		buf = append(buf, dump[:len(dump)-1]...)
+	}
+	if omitted := h.total - len(h.bytes); omitted > 0 {
+		if len(h.bytes) > 0 {
+			buf = append(buf, '\n')
+		}
+		buf = append(buf, "... "...)
+		buf = strconv.AppendInt(buf, int64(omitted), 10)
+		buf = append(buf, " bytes omitted"...)
+	}
+	return string(buf)
+}
+
+// MarshalJSON renders the dump as a single hex string.
+func (h hexDump) MarshalJSON() ([]byte, error) {
+	buf := make([]byte, hex.EncodedLen(len(h.bytes))+2)
+	buf[0] = '"'
+	hex.Encode(buf[1:], h.bytes)
+	buf[len(buf)-1] = '"'
+	return buf, nil
+}
@@ -23,6 +23,9 @@ package zap
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"math/big"
 	"net"
 	"strings"
 	"sync"
@@ -92,6 +95,58 @@ func TestSkipField(t *testing.T) {
 	assertCanBeReused(t, Skip())
 }
 
+func TestOmitEmptyField(t *testing.T) {
+	empty := []Field{
+		Bool("foo", false),
+		Float64("foo", 0),
+		Float64("foo", math.Copysign(0, -1)),
+		Int("foo", 0),
+		Int64("foo", 0),
+		Uint("foo", 0),
+		Uint64("foo", 0),
+		Uintptr("foo", 0),
+		Duration("foo", 0),
+		String("foo", ""),
+		Object("foo", nil),
+		Stringer("foo", nil),
+		Stringer("foo", (*net.IPNet)(nil)),
+		Marshaler("foo", nil),
+		Marshaler("foo", (*fakeUser)(nil)),
+	}
+	for _, f := range empty {
+		assertFieldJSON(t, ``, OmitEmpty(f))
+	}
+
+	assertFieldJSON(t, `"foo":"bar"`, OmitEmpty(String("foo", "bar")))
+	assertFieldJSON(t, `"foo":-1`, OmitEmpty(Int("foo", -1)))
+	assertFieldJSON(t, `"foo":true`, OmitEmpty(Bool("foo", true)))
+	assertFieldJSON(t, `"foo":1.5`, OmitEmpty(Float64("foo", 1.5)))
+	assertFieldJSON(t, `"foo":{"name":"phil"}`, OmitEmpty(Marshaler("foo", fakeUser{"phil"})))
+	assertCanBeReused(t, OmitEmpty(String("foo", "bar")))
+}
+
+func TestMergeFields(t *testing.T) {
+	context := []Field{String("a", "ctx"), Int("b", 1), Skip()}
+	fields := []Field{Skip(), Int("c", 2), String("a", "site")}
+	assert.Equal(
+		t,
+		[]Field{Int("b", 1), Skip(), Skip(), Int("c", 2), String("a", "site")},
+		MergeFields(context, fields),
+		"Expected the last field with each key to win.",
+	)
+	assert.Equal(t, String("a", "ctx"), context[0], "Expected the context not to be modified.")
+	assert.Equal(t, []Field{Int("b", 1), Int("c", 2)}, MergeFields([]Field{Int("b", 1)}, []Field{Int("c", 2)}), "Unexpected result without duplicates.")
+	assert.Equal(t, []Field{}, MergeFields(nil, nil), "Unexpected result without fields.")
+
+	many := make([]Field, 0, _mergeFieldsScanMax+2)
+	for i := 0; i <= _mergeFieldsScanMax; i++ {
+		many = append(many, Int(fmt.Sprint(i), i))
+	}
+	merged := MergeFields(many, []Field{Int("0", -1)})
+	assert.Equal(t, len(many), len(merged), "Expected duplicates to be removed from long lists.")
+	assert.Equal(t, Int("0", -1), merged[len(merged)-1], "Expected the last field to win in long lists.")
+}
+
 func TestTrueBoolField(t *testing.T) {
 	assertFieldJSON(t, `"foo":true`, Bool("foo", true))
 	assertCanBeReused(t, Bool("foo", true))
@@ -132,6 +187,22 @@ func TestUint64Field(t *testing.T) {
 	assertCanBeReused(t, Uint64("foo", uint64(1)))
 }
 
+func TestBigIntField(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	assertFieldJSON(t, `"foo":"123456789012345678901234567890"`, BigInt("foo", huge))
+	assertFieldJSON(t, `"foo":"-1"`, BigInt("foo", big.NewInt(-1)))
+	assertFieldJSON(t, `"foo":"<nil>"`, BigInt("foo", nil))
+	assertCanBeReused(t, BigInt("foo", huge))
+}
+
+func TestBigFloatField(t *testing.T) {
+	precise, _ := new(big.Float).SetPrec(200).SetString("1.00000000000000000000000001")
+	assertFieldJSON(t, `"foo":"1.00000000000000000000000001"`, BigFloat("foo", precise))
+	assertFieldJSON(t, `"foo":"1.5"`, BigFloat("foo", big.NewFloat(1.5)))
+	assertFieldJSON(t, `"foo":"<nil>"`, BigFloat("foo", nil))
+	assertCanBeReused(t, BigFloat("foo", precise))
+}
+
 func TestUintptrField(t *testing.T) {
 	assertFieldJSON(t, `"foo":10`, Uintptr("foo", uintptr(0xa)))
 	assertCanBeReused(t, Uintptr("foo", uintptr(0xa)))
@@ -148,6 +219,41 @@ func TestStringerField(t *testing.T) {
 	assertCanBeReused(t, Stringer("foo", ip))
 }
 
+func TestIPField(t *testing.T) {
+	assertFieldJSON(t, `"ip":"1.2.3.4"`, IP("ip", net.ParseIP("1.2.3.4")))
+	assertFieldJSON(t, `"ip":"2001:db8::1"`, IP("ip", net.ParseIP("2001:db8:0::1")))
+	assertFieldJSON(t, `"ip":null`, IP("ip", nil))
+	assertCanBeReused(t, IP("ip", net.ParseIP("1.2.3.4")))
+}
+
+func TestAddrField(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.ParseIP("1.2.3.4"), Port: 80}
+	assertFieldJSON(t, `"addr":"1.2.3.4:80"`, Addr("addr", addr))
+	assertFieldJSON(t, `"addr":null`, Addr("addr", nil))
+	assertCanBeReused(t, Addr("addr", addr))
+}
+
+func TestPointerFields(t *testing.T) {
+	b, f, i, s := true, 1.5, 42, "bar"
+	tests := []struct {
+		field    Field
+		nilField Field
+		expected string
+	}{
+		{Boolp("foo", &b), Boolp("foo", nil), `"foo":true`},
+		{Float64p("foo", &f), Float64p("foo", nil), `"foo":1.5`},
+		{Intp("foo", &i), Intp("foo", nil), `"foo":42`},
+		{Stringp("foo", &s), Stringp("foo", nil), `"foo":"bar"`},
+	}
+
+	for _, tt := range tests {
+		assertFieldJSON(t, tt.expected, tt.field)
+		assertCanBeReused(t, tt.field)
+		assertFieldJSON(t, `"foo":null`, tt.nilField)
+		assertCanBeReused(t, tt.nilField)
+	}
+}
+
 func TestTimeField(t *testing.T) {
 	assertFieldJSON(t, `"foo":0`, Time("foo", time.Unix(0, 0)))
 	assertFieldJSON(t, `"foo":1.5`, Time("foo", time.Unix(1, int64(500*time.Millisecond))))
@@ -160,11 +266,57 @@ func TestErrField(t *testing.T) {
 	assertCanBeReused(t, Error(errors.New("fail")))
 }
 
+type statusError struct {
+	code      int
+	retryable bool
+}
+
+func (e statusError) Error() string { return fmt.Sprintf("status %d", e.code) }
+
+func (e statusError) Fields() []Field {
+	return []Field{Int("code", e.code), Bool("retryable", e.retryable)}
+}
+
+type wrappedError struct{ err error }
+
+func (e wrappedError) Error() string { return "wrapped: " + e.err.Error() }
+func (e wrappedError) Unwrap() error { return e.err }
+
+type causedError struct{ err error }
+
+func (e causedError) Error() string { return "caused: " + e.err.Error() }
+func (e causedError) Cause() error  { return e.err }
+
+func TestErrFieldWithFields(t *testing.T) {
+	status := statusError{code: 503, retryable: true}
+	assertFieldJSON(t, `"error":"status 503","errorFields":{"code":503,"retryable":true}`, Error(status))
+	assertFieldJSON(
+		t,
+		`"error":"caused: wrapped: status 503","errorFields":{"code":503,"retryable":true}`,
+		Error(causedError{wrappedError{status}}),
+	)
+	assertFieldJSON(t, `"error":"wrapped: fail"`, Error(wrappedError{errors.New("fail")}))
+	assertCanBeReused(t, Error(status))
+}
+
 func TestDurationField(t *testing.T) {
 	assertFieldJSON(t, `"foo":1`, Duration("foo", time.Nanosecond))
 	assertCanBeReused(t, Duration("foo", time.Nanosecond))
 }
 
+func TestSinceField(t *testing.T) {
+	defer stubNow(3 * time.Second)()
+	assertFieldJSON(t, `"foo":2000000000`, Since("foo", time.Unix(1, 0)))
+	assertCanBeReused(t, Since("foo", time.Unix(1, 0)))
+}
+
+func TestRelativeTimeField(t *testing.T) {
+	ref := time.Unix(1, 0)
+	assertFieldJSON(t, `"foo":1500000000`, RelativeTime("foo", ref, ref.Add(1500*time.Millisecond)))
+	assertFieldJSON(t, `"foo":-1000000000`, RelativeTime("foo", ref, time.Unix(0, 0)))
+	assertCanBeReused(t, RelativeTime("foo", ref, ref.Add(time.Second)))
+}
+
 func TestMarshalerField(t *testing.T) {
 	// Marshaling the user failed, so we expect an empty object and an error
 	// message.
@@ -200,6 +352,41 @@ func TestBase64Field(t *testing.T) {
 	assertCanBeReused(t, Base64("foo", []byte("bar")))
 }
 
+func TestHexDumpField(t *testing.T) {
+	assertFieldJSON(t, `"foo":"6162310a"`, HexDump("foo", []byte("ab1\n")))
+	assertFieldJSON(t, `"foo":""`, HexDump("foo", nil))
+	assertFieldJSON(t, `"foo":"6162"`, HexDumpN("foo", []byte("ab12"), 2))
+	assertFieldJSON(t, `"foo":"61623132"`, HexDumpN("foo", []byte("ab12"), -1))
+	assertCanBeReused(t, HexDump("foo", []byte("bar")))
+}
+
+func TestHexDumpFieldText(t *testing.T) {
+	tests := []struct {
+		field    Field
+		expected string
+	}{
+		{
+			HexDump("foo", []byte("ab1\n")),
+			"foo=\n00000000  61 62 31 0a                                       |ab1.|",
+		},
+		{
+			HexDumpN("foo", []byte("ab12"), 2),
+			"foo=\n00000000  61 62                                             |ab|\n... 2 bytes omitted",
+		},
+		{
+			HexDumpN("foo", []byte("ab12"), 0),
+			"foo=\n... 4 bytes omitted",
+		},
+	}
+
+	for _, tt := range tests {
+		withTextEncoder(func(enc *textEncoder) {
+			tt.field.AddTo(enc)
+			assert.Equal(t, tt.expected, string(enc.bytes), "Unexpected text output for hex dump.")
+		})
+	}
+}
+
 func TestLogMarshalerFunc(t *testing.T) {
 	assertFieldJSON(t, `"foo":{"name":"phil"}`,
 		Marshaler("foo", LogMarshalerFunc(fakeUser{"phil"}.MarshalLog)))
@@ -216,6 +403,79 @@ func TestStackField(t *testing.T) {
 	assert.Contains(t, output[13:], "zap.TestStackField", "Expected stacktrace to contain caller.")
 }
 
+func TestStructuredStackField(t *testing.T) {
+	enc := newJSONEncoder()
+	defer enc.Free()
+
+	StructuredStack().AddTo(enc)
+	output := string(enc.bytes)
+
+	require.True(t, strings.HasPrefix(output, `"stacktrace":[{"function":`), "Expected stacktrace to be an array of frames.")
+	assert.Contains(t, output, `"function":"github.com/uber-go/zap.TestStructuredStackField","file":`, "Expected first frame to be caller.")
+	assert.Contains(t, output, `field_test.go","line":`, "Expected frame to include file and line.")
+	assert.NotContains(t, output, "takeStackFrames", "Expected internal frames to be skipped.")
+}
+
+func TestStructuredStackFieldText(t *testing.T) {
+	withTextEncoder(func(enc *textEncoder) {
+		StructuredStack().AddTo(enc)
+		output := string(enc.bytes)
+		require.True(t, strings.HasPrefix(output, "stacktrace=github.com/uber-go/zap.TestStructuredStackFieldText"), "Unexpected text stacktrace.")
+		assert.NotContains(t, output, "\n", "Expected text stacktrace on a single line.")
+	})
+}
+
+func TestMemStatsField(t *testing.T) {
+	enc := newJSONEncoder()
+	defer enc.Free()
+
+	MemStats("mem").AddTo(enc)
+	assert.Regexp(t, `^"mem":{"alloc":\d+,"heapInuse":\d+,"sys":\d+,"numGC":\d+}$`, string(enc.bytes), "Unexpected memory stats.")
+	assertCanBeReused(t, MemStats("mem"))
+}
+
+func TestRawJSONField(t *testing.T) {
+	assertFieldJSON(t, `"foo":{"bar":[1,2]}`, RawJSON("foo", []byte(`{"bar":[1,2]}`)))
+	assertFieldJSON(t, `"foo":null`, RawJSON("foo", nil))
+	withJSONEncoder(func(enc *jsonEncoder) {
+		RawJSON("foo", []byte("not json")).AddTo(enc)
+		assert.Equal(t, `"foo":not json`, string(enc.bytes), "Expected unvalidated data to be written verbatim.")
+	})
+	assertCanBeReused(t, RawJSON("foo", []byte(`{"bar":[1,2]}`)))
+
+	withTextEncoder(func(enc *textEncoder) {
+		RawJSON("foo", []byte(`{"bar":1}`)).AddTo(enc)
+		assert.Equal(t, `foo={"bar":1}`, string(enc.bytes), "Expected text encoder to fall back to a string.")
+	})
+}
+
+func TestRawJSONFieldValidation(t *testing.T) {
+	enc := newJSONEncoder(ValidateRawJSON())
+	defer enc.Free()
+
+	RawJSON("ok", []byte(`[1]`)).AddTo(enc)
+	RawJSON("bad", []byte(`{"unterminated`)).AddTo(enc)
+	assert.Regexp(t, `^"ok":\[1\],"bad":"{\\"unterminated","badError":".+"$`, string(enc.bytes), "Expected malformed JSON to be encoded as a string.")
+}
+
+func TestRawJSONFieldLineBreaks(t *testing.T) {
+	indented, err := json.MarshalIndent(map[string]interface{}{"bar": []int{1, 2}, "baz": "a\nb"}, "", "  ")
+	require.NoError(t, err, "Failed to marshal indented JSON.")
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		logger.Info("indented", RawJSON("foo", indented))
+		logger.Info("encoded", RawJSON("foo", append([]byte(`{"bar":1}`), '\r', '\n')))
+		assert.Equal(t, []string{
+			`{"level":"info","msg":"indented","foo":{"bar":[1,2],"baz":"a\nb"}}`,
+			`{"level":"info","msg":"encoded","foo":{"bar":1}}`,
+		}, buf.Lines(), "Expected line breaks in raw JSON to be compacted.")
+	})
+
+	withJSONEncoder(func(enc *jsonEncoder) {
+		RawJSON("foo", []byte("not\njson")).AddTo(enc)
+		assert.Regexp(t, `^"foo":"not\\njson","fooError":".+"$`, string(enc.bytes), "Expected malformed data with line breaks to be encoded as a string.")
+	})
+}
+
 func TestUnknownField(t *testing.T) {
 	enc := NewJSONEncoder()
 	defer enc.Free()
@@ -0,0 +1,56 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceGlobals(t *testing.T) {
+	initial := L()
+	assert.NotNil(t, initial, "Expected a non-nil default global logger.")
+
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		restore := ReplaceGlobals(logger)
+		L().Info("replaced")
+		assert.Equal(t, `{"level":"info","msg":"replaced"}`, buf.Stripped(), "Unexpected output from replaced global logger.")
+
+		restore()
+		L().Info("restored")
+		assert.Equal(t, `{"level":"info","msg":"replaced"}`, buf.Stripped(), "Expected restored global logger not to write to replacement's output.")
+		assert.Equal(t, initial, L(), "Expected restore func to restore the original global logger.")
+	})
+}
+
+func TestReplaceGlobalsConcurrent(t *testing.T) {
+	logger := New(NullEncoder())
+	wg := &sync.WaitGroup{}
+	runConcurrently(5, 10, wg, func() {
+		L().Info("")
+	})
+	runConcurrently(5, 10, wg, func() {
+		ReplaceGlobals(logger)()
+	})
+	wg.Wait()
+}
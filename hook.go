@@ -21,18 +21,36 @@
 package zap
 
 import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 var (
+	// ErrDropEntry may be returned by a Hook or FieldHook to drop the entry
+	// being logged. It's not reported to the logger's error output. Dropping
+	// Panic- and Fatal-level entries doesn't prevent the logger from panicking
+	// or exiting.
+	ErrDropEntry = errors.New("drop entry")
+
 	errHookNilEntry = errors.New("can't call a hook on a nil *Entry")
 	errCaller       = errors.New("failed to get caller")
+	errGoroutineID  = errors.New("failed to parse goroutine ID")
 	// Skip Caller, Logger.log, and the leveled Logger method when using
 	// runtime.Caller.
 	_callerSkip = 3
+
+	// State for NewEntryID.
+	_entryIDOnce   sync.Once
+	_entryIDPrefix string
+	_entryIDCount  uint64
 )
 
 // A Hook is executed each time the logger writes an Entry. It can modify the
@@ -40,6 +58,19 @@ var (
 // references to the entry or any of its contents. Returned errors are written to
 // the logger's error output.
 //
+// The logger handles each entry in a fixed sequence:
+//  1. It checks whether the entry's level is enabled.
+//  2. It creates the Entry, timestamping it.
+//  3. It runs the FieldHooks, in the order they were supplied.
+//  4. It encodes the fields passed at the log site.
+//  5. It runs the Hooks, in the order they were supplied. Caller and
+//     stacktrace capture (AddCaller, AddStacks, etc.) are ordinary hooks,
+//     so they run in this phase, in the order their options were supplied.
+//  6. It writes the entry to its output.
+//
+// Any hook may return ErrDropEntry to stop processing; the remaining hooks
+// aren't run and nothing is written.
+//
 // Hooks implement the Option interface.
 type Hook func(*Entry) error
 
@@ -48,14 +79,50 @@ func (h Hook) apply(m *Meta) {
 	m.Hooks = append(m.Hooks, h)
 }
 
+// A FieldHook is like a Hook, but it also receives the fields passed at the
+// log site (preceded by the logger's context if the logger was constructed
+// with the DeferFields option). This lets hooks make decisions, like whether
+// to sample an entry, based on field values. FieldHooks run before the fields
+// are encoded, so fields they add to Entry.Fields() come first. They must not
+// modify or retain the fields slice.
+//
+// FieldHooks implement the Option interface.
+type FieldHook func(*Entry, []Field) error
+
+// apply implements the Option interface.
+func (h FieldHook) apply(m *Meta) {
+	m.FieldHooks = append(m.FieldHooks, h)
+}
+
+// A CallerPath controls how much of the caller's file path the AddCallerPath
+// option includes.
+type CallerPath int
+
+const (
+	// ShortCallerPath includes only the file's base name (e.g., logger.go).
+	ShortCallerPath CallerPath = iota
+	// PackageCallerPath includes the file's directory and base name (e.g.,
+	// zap/logger.go), which is usually enough to identify the package.
+	PackageCallerPath
+	// FullCallerPath includes the file's full path.
+	FullCallerPath
+)
+
 // AddCaller configures the Logger to annotate each message with the filename
-// and line number of zap's caller.
+// and line number of zap's caller. It's equivalent to
+// AddCallerPath(ShortCallerPath).
 func AddCaller() Option {
+	return AddCallerPath(ShortCallerPath)
+}
+
+// AddCallerPath is like AddCaller, but it allows callers to choose how much of
+// the file path to include.
+func AddCallerPath(mode CallerPath) Option {
 	return Hook(func(e *Entry) error {
 		if e == nil {
 			return errHookNilEntry
 		}
-		_, filename, line, ok := runtime.Caller(_callerSkip)
+		_, filename, line, ok := runtime.Caller(_callerSkip + e.callerSkip)
 		if !ok {
 			return errCaller
 		}
@@ -64,7 +131,7 @@ func AddCaller() Option {
 		enc := jsonPool.Get().(*jsonEncoder)
 		enc.truncate()
 		buf := enc.bytes
-		buf = append(buf, filepath.Base(filename)...)
+		buf = append(buf, trimCallerPath(filename, mode)...)
 		buf = append(buf, ':')
 		buf = strconv.AppendInt(buf, int64(line), 10)
 		buf = append(buf, ':', ' ')
@@ -77,6 +144,113 @@ func AddCaller() Option {
 	})
 }
 
+func trimCallerPath(filename string, mode CallerPath) string {
+	switch mode {
+	case FullCallerPath:
+		return filename
+	case PackageCallerPath:
+		// runtime.Caller always uses forward slashes.
+		idx := strings.LastIndexByte(filename, '/')
+		if idx < 0 {
+			return filename
+		}
+		if idx = strings.LastIndexByte(filename[:idx], '/'); idx < 0 {
+			return filename
+		}
+		return filename[idx+1:]
+	default:
+		return filepath.Base(filename)
+	}
+}
+
+// AddComponent configures the Logger to annotate each message with a
+// "component" field: the import path of the package that called zap (e.g.,
+// "github.com/uber-go/zap/zwrap"). It saves packages from having to set up
+// their own child loggers just to identify themselves. Package paths are
+// cached by call site, so the runtime's symbol table is consulted only once
+// per logging statement.
+func AddComponent() Option {
+	var (
+		mu    sync.RWMutex
+		cache = make(map[uintptr]string)
+	)
+	return Hook(func(e *Entry) error {
+		if e == nil {
+			return errHookNilEntry
+		}
+		pc, _, _, ok := runtime.Caller(_callerSkip + e.callerSkip)
+		if !ok {
+			return errCaller
+		}
+
+		mu.RLock()
+		pkg, cached := cache[pc]
+		mu.RUnlock()
+		if !cached {
+			pkg = funcPackage(runtime.FuncForPC(pc))
+			mu.Lock()
+			cache[pc] = pkg
+			mu.Unlock()
+		}
+		String("component", pkg).AddTo(e.Fields())
+		return nil
+	})
+}
+
+// AddGoroutineID configures the Logger to annotate each message with a
+// "goroutine" field identifying the goroutine that logged it, which helps when
+// debugging races. The Go runtime deliberately doesn't expose goroutine IDs,
+// so the ID is parsed from the header of a stack trace; this costs about a
+// microsecond per entry, so the option is best suited to development. Parsing
+// is best-effort: if a future Go release changes the stack trace format, the
+// hook reports an error instead of adding the field.
+func AddGoroutineID() Option {
+	return Hook(func(e *Entry) error {
+		if e == nil {
+			return errHookNilEntry
+		}
+		id, ok := goroutineID()
+		if !ok {
+			return errGoroutineID
+		}
+		Uint64("goroutine", id).AddTo(e.Fields())
+		return nil
+	})
+}
+
+// goroutineID parses the current goroutine's ID from a stack trace header,
+// which looks like "goroutine 42 [running]:".
+func goroutineID() (uint64, bool) {
+	var buf [64]byte
+	bs := buf[:runtime.Stack(buf[:], false)]
+	const prefix = "goroutine "
+	if !strings.HasPrefix(string(bs), prefix) {
+		return 0, false
+	}
+	bs = bs[len(prefix):]
+	if i := strings.IndexByte(string(bs), ' '); i > 0 {
+		bs = bs[:i]
+	}
+	id, err := strconv.ParseUint(string(bs), 10, 64)
+	return id, err == nil
+}
+
+// funcPackage returns the import path of the package that defines the
+// function.
+func funcPackage(fn *runtime.Func) string {
+	if fn == nil {
+		return ""
+	}
+	// Function names look like "github.com/uber-go/zap.(*logger).Info"; the
+	// package path ends at the first dot after the last slash.
+	name := fn.Name()
+	slash := strings.LastIndexByte(name, '/')
+	if dot := strings.IndexByte(name[slash+1:], '.'); dot >= 0 {
+		return name[:slash+1+dot]
+	}
+	return name
+}
+
 // AddStacks configures the Logger to record a stack trace for all messages at
 // or above a given level. Keep in mind that this is (relatively speaking) quite
 // expensive.
@@ -91,3 +265,69 @@ func AddStacks(lvl Level) Option {
 		return nil
 	})
 }
+
+// AddStructuredStacks is like AddStacks, but it records the stack trace as a
+// list of frames rather than a single string. See StructuredStack for
+// details.
+func AddStructuredStacks(lvl Level) Option {
+	return Hook(func(e *Entry) error {
+		if e == nil {
+			return errHookNilEntry
+		}
+		if e.Level >= lvl {
+			StructuredStack().AddTo(e.Fields())
+		}
+		return nil
+	})
+}
+
+// AddEntryID configures the Logger to annotate each message with a unique ID
+// under the "entry_id" key, which makes it easy to find a specific entry (for
+// example, from an error ID shown to a user). The supplied function generates
+// the IDs; if it's nil, the logger uses NewEntryID.
+func AddEntryID(gen func() string) Option {
+	if gen == nil {
+		gen = NewEntryID
+	}
+	return Hook(func(e *Entry) error {
+		if e == nil {
+			return errHookNilEntry
+		}
+		String("entry_id", gen()).AddTo(e.Fields())
+		return nil
+	})
+}
+
+// NewEntryID returns an ID that's unique within the process and very likely
+// unique across processes: a random per-process prefix followed by a
+// monotonically increasing counter.
+func NewEntryID() string {
+	_entryIDOnce.Do(func() {
+		var b [8]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			// Fall back to the start time, which is still likely to differ
+			// between processes.
+			binary.BigEndian.PutUint64(b[:], uint64(_timeNow().UnixNano()))
+		}
+		_entryIDPrefix = hex.EncodeToString(b[:]) + "-"
+	})
+	n := atomic.AddUint64(&_entryIDCount, 1)
+	return _entryIDPrefix + strconv.FormatUint(n, 36)
+}
+
+// AddUptime configures the Logger to annotate each message with an "uptime"
+// field: the time elapsed since the logger was constructed. Child loggers
+// share their parent's start time. Since the uptime relies on the monotonic
+// clock, it's unaffected by jumps in the wall clock.
+func AddUptime() Option {
+	return optionFunc(func(m *Meta) {
+		start := _timeNow()
+		m.Hooks = append(m.Hooks, Hook(func(e *Entry) error {
+			if e == nil {
+				return errHookNilEntry
+			}
+			Duration("uptime", _timeNow().Sub(start)).AddTo(e.Fields())
+			return nil
+		}))
+	})
+}
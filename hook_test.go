@@ -21,8 +21,11 @@
 package zap
 
 import (
+	"fmt"
 	"regexp"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -37,6 +40,40 @@ func TestHookAddCaller(t *testing.T) {
 	assert.Regexp(t, re, buf.Stripped(), "Expected to find package name and file name in output.")
 }
 
+func TestHookAddCallerPath(t *testing.T) {
+	tests := []struct {
+		mode     CallerPath
+		expected string
+	}{
+		{ShortCallerPath, `"msg":"hook_test.go:[\d]+: Callers\."`},
+		{PackageCallerPath, `"msg":"[^/]+/hook_test.go:[\d]+: Callers\."`},
+		{FullCallerPath, `"msg":"/.+/[^/]+/hook_test.go:[\d]+: Callers\."`},
+	}
+	for _, tt := range tests {
+		buf := &testBuffer{}
+		logger := New(NewJSONEncoder(), DebugLevel, Output(buf), AddCallerPath(tt.mode))
+		logger.Info("Callers.")
+		assert.Regexp(t, tt.expected, buf.Stripped(), "Unexpected caller for path mode %v.", tt.mode)
+	}
+}
+
+func TestTrimCallerPath(t *testing.T) {
+	tests := []struct {
+		filename string
+		mode     CallerPath
+		expected string
+	}{
+		{"/a/b/c.go", ShortCallerPath, "c.go"},
+		{"/a/b/c.go", PackageCallerPath, "b/c.go"},
+		{"b/c.go", PackageCallerPath, "b/c.go"},
+		{"c.go", PackageCallerPath, "c.go"},
+		{"/a/b/c.go", FullCallerPath, "/a/b/c.go"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, trimCallerPath(tt.filename, tt.mode), "Unexpected result trimming %s in mode %v.", tt.filename, tt.mode)
+	}
+}
+
 func TestHookAddCallerFail(t *testing.T) {
 	buf := &testBuffer{}
 	errBuf := &testBuffer{}
@@ -51,6 +88,35 @@ func TestHookAddCallerFail(t *testing.T) {
 	assert.Contains(t, buf.String(), `"msg":"Failure."`, "Expected original message to survive failures in runtime.Caller.")
 }
 
+func TestHookAddComponent(t *testing.T) {
+	buf := &testBuffer{}
+	logger := New(NewJSONEncoder(NoTime()), Output(buf), AddComponent())
+	for i := 0; i < 2; i++ {
+		logger.Info("Components.")
+	}
+	assert.Equal(t, []string{
+		`{"level":"info","msg":"Components.","component":"github.com/uber-go/zap"}`,
+		`{"level":"info","msg":"Components.","component":"github.com/uber-go/zap"}`,
+	}, buf.Lines(), "Unexpected component.")
+}
+
+func TestHookAddComponentFail(t *testing.T) {
+	errBuf := &testBuffer{}
+	originalSkip := _callerSkip
+	_callerSkip = 1e3
+	defer func() { _callerSkip = originalSkip }()
+
+	logger := New(NewJSONEncoder(), Output(&testBuffer{}), ErrorOutput(errBuf), AddComponent())
+	logger.Info("Failure.")
+	assert.Contains(t, errBuf.String(), "hook error: failed to get caller", "Didn't find expected failure message.")
+}
+
+func TestFuncPackage(t *testing.T) {
+	pc, _, _, _ := runtime.Caller(0)
+	assert.Equal(t, "github.com/uber-go/zap", funcPackage(runtime.FuncForPC(pc)), "Unexpected package for test function.")
+	assert.Equal(t, "", funcPackage(nil), "Expected empty package for nil function.")
+}
+
 func TestHookAddStacks(t *testing.T) {
 	buf := &testBuffer{}
 	logger := New(NewJSONEncoder(), DebugLevel, Output(buf), AddStacks(InfoLevel))
@@ -69,12 +135,64 @@ func TestHookAddStacks(t *testing.T) {
 	assert.NotContains(t, buf.String(), "Unexpected stacktrace at Debug level.")
 }
 
+func TestHookAddStructuredStacks(t *testing.T) {
+	buf := &testBuffer{}
+	logger := New(NewJSONEncoder(), DebugLevel, Output(buf), AddStructuredStacks(InfoLevel))
+
+	logger.Info("Stacks.")
+	output := buf.String()
+	require.Contains(t, output, `"stacktrace":[{"function":`, "Expected structured stacktrace.")
+	assert.Contains(t, output, "zap.TestHookAddStructuredStacks", "Expected to find test function in stacktrace.")
+
+	buf.Reset()
+	logger.Debug("No stacks.")
+	assert.NotContains(t, buf.String(), "stacktrace", "Unexpected stacktrace at Debug level.")
+}
+
+func TestHookAddEntryID(t *testing.T) {
+	buf := &testBuffer{}
+	n := 0
+	gen := func() string {
+		n++
+		return fmt.Sprintf("id-%d", n)
+	}
+	logger := New(NewJSONEncoder(NoTime()), Output(buf), AddEntryID(gen))
+	logger.Info("one")
+	logger.Debug("disabled")
+	logger.Info("two")
+	assert.Equal(t, []string{
+		`{"level":"info","msg":"one","entry_id":"id-1"}`,
+		`{"level":"info","msg":"two","entry_id":"id-2"}`,
+	}, buf.Lines(), "Unexpected entry IDs.")
+}
+
+func TestNewEntryID(t *testing.T) {
+	first, second := NewEntryID(), NewEntryID()
+	assert.NotEqual(t, first, second, "Expected entry IDs to be unique.")
+	assert.Regexp(t, `^[0-9a-f]{16}-[0-9a-z]+$`, first, "Unexpected entry ID format.")
+	assert.Equal(t, first[:17], second[:17], "Expected entry IDs to share a per-process prefix.")
+}
+
+func TestHookAddUptime(t *testing.T) {
+	restore := stubNow(time.Second)
+	buf := &testBuffer{}
+	logger := New(NewJSONEncoder(NoTime()), DebugLevel, Output(buf), AddUptime())
+	restore()
+
+	defer stubNow(3 * time.Second)()
+	logger.With(String("foo", "bar")).Info("Uptime.")
+	assert.Equal(t, `{"level":"info","msg":"Uptime.","foo":"bar","uptime":2000000000}`, buf.Stripped(), "Unexpected uptime.")
+}
+
 func TestHooksNilEntry(t *testing.T) {
 	tests := []struct {
 		name string
 		hook Hook
 	}{
 		{"AddStacks", AddStacks(InfoLevel).(Hook)},
+		{"AddStructuredStacks", AddStructuredStacks(InfoLevel).(Hook)},
+		{"AddEntryID", AddEntryID(nil).(Hook)},
+		{"AddComponent", AddComponent().(Hook)},
 		{"AddCaller", AddCaller().(Hook)},
 	}
 	for _, tt := range tests {
@@ -83,3 +201,81 @@ func TestHooksNilEntry(t *testing.T) {
 		}, "Unexpected panic running hook %s on a nil message.", tt.name)
 	}
 }
+
+func TestHookOrdering(t *testing.T) {
+	var calls []string
+	fieldHook := FieldHook(func(e *Entry, fields []Field) error {
+		calls = append(calls, "fields")
+		assert.Equal(t, []Field{Int("user", 42)}, fields, "Unexpected fields passed to FieldHook.")
+		e.Fields().AddString("hook", "fields")
+		return nil
+	})
+	hook := Hook(func(e *Entry) error {
+		calls = append(calls, "hook")
+		e.Fields().AddString("hook", "plain")
+		return nil
+	})
+
+	buf := &testBuffer{}
+	// Supply the hooks in the opposite order to make sure FieldHooks run first.
+	logger := New(NewJSONEncoder(NoTime()), DebugLevel, Output(buf), hook, fieldHook)
+	logger.Info("hello", Int("user", 42))
+
+	assert.Equal(t, []string{"fields", "hook"}, calls, "Unexpected hook ordering.")
+	assert.Equal(
+		t,
+		`{"level":"info","msg":"hello","hook":"fields","user":42,"hook":"plain"}`,
+		buf.Stripped(),
+		"Expected FieldHooks to run before fields are encoded and Hooks after.",
+	)
+}
+
+func TestHookDropEntry(t *testing.T) {
+	sample := FieldHook(func(e *Entry, fields []Field) error {
+		for _, f := range fields {
+			if f.key == "noisy" {
+				return ErrDropEntry
+			}
+		}
+		return nil
+	})
+	withJSONLogger(t, opts(sample, Hook(func(e *Entry) error {
+		if e.Message == "drop me" {
+			return ErrDropEntry
+		}
+		return nil
+	})), func(logger Logger, buf *testBuffer) {
+		logger.Info("sampled", Bool("noisy", true))
+		logger.Info("drop me")
+		logger.Info("kept")
+		assert.Equal(t, []string{`{"level":"info","msg":"kept"}`}, buf.Lines(), "Expected dropped entries not to be written.")
+	})
+}
+
+func TestHookAddGoroutineID(t *testing.T) {
+	buf := &testBuffer{}
+	logger := New(NewJSONEncoder(NoTime()), Output(buf), AddGoroutineID())
+	logger.Info("Goroutines.")
+
+	done := make(chan struct{})
+	go func() {
+		logger.Info("Goroutines.")
+		close(done)
+	}()
+	<-done
+
+	lines := buf.Lines()
+	require.Equal(t, 2, len(lines), "Expected two log entries.")
+	re := regexp.MustCompile(`^\{"level":"info","msg":"Goroutines\.","goroutine":[1-9]\d*\}$`)
+	for _, line := range lines {
+		assert.Regexp(t, re, line, "Expected a numeric goroutine ID.")
+	}
+	assert.NotEqual(t, lines[0], lines[1], "Expected different goroutines to have different IDs.")
+}
+
+func TestGoroutineID(t *testing.T) {
+	id, ok := goroutineID()
+	assert.True(t, ok, "Expected to parse the goroutine ID.")
+	again, _ := goroutineID()
+	assert.Equal(t, id, again, "Expected a stable ID for the same goroutine.")
+}
@@ -21,6 +21,7 @@
 package zap
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -37,6 +38,11 @@ const (
 	_hex = "0123456789abcdef"
 	// Initial buffer size for encoders.
 	_initialBufSize = 1024
+	// Largest integer that a float64 (and therefore JavaScript) can represent
+	// exactly.
+	_maxSafeUint = 1 << 53
+	// Terminator appended to each entry by default.
+	_defaultLineEnding = "\n"
 )
 
 var (
@@ -62,6 +68,23 @@ type jsonEncoder struct {
 	messageF MessageFormatter
 	timeF    TimeFormatter
 	levelF   LevelFormatter
+	// Optional, in addition to levelF.
+	levelCodeF LevelFormatter
+	localTimes []localTime
+	maxMsg     int
+	limit      fieldLimit
+	// Quote uint64s that can't be represented exactly as a float64.
+	quoteUints bool
+	// Check that RawJSON fields are well-formed.
+	validateRaw bool
+	// Flatten nested objects into prefixed keys.
+	flatten    bool
+	flattenSep string
+	prefix     string
+	// Numeric fields hoisted to the top level.
+	metrics hoistedMetrics
+	// Terminator appended to each entry.
+	lineEnding string
 }
 
 // NewJSONEncoder creates a fast, low-allocation JSON encoder. By default, JSON
@@ -84,6 +107,15 @@ func NewJSONEncoder(options ...JSONOption) Encoder {
 	enc.messageF = defaultMessageF
 	enc.timeF = defaultTimeF
 	enc.levelF = defaultLevelF
+	enc.levelCodeF = nil
+	enc.localTimes = nil
+	enc.maxMsg = 0
+	enc.quoteUints = false
+	enc.validateRaw = false
+	enc.flatten = false
+	enc.flattenSep = ""
+	enc.metrics = hoistedMetrics{}
+	enc.lineEnding = _defaultLineEnding
 	for _, opt := range options {
 		opt.apply(enc)
 	}
@@ -98,15 +130,29 @@ func (enc *jsonEncoder) Free() {
 // AddString adds a string key and value to the encoder's fields. Both key and
 // value are JSON-escaped.
 func (enc *jsonEncoder) AddString(key, val string) {
+	if !enc.limit.admit() {
+		return
+	}
 	enc.addKey(key)
 	enc.bytes = append(enc.bytes, '"')
 	enc.safeAddString(val)
 	enc.bytes = append(enc.bytes, '"')
 }
 
+// addUnlimitedString is like AddString, but the field doesn't count toward the
+// MaxFields limit.
+func (enc *jsonEncoder) addUnlimitedString(key, val string) {
+	enc.limit.depth++
+	enc.AddString(key, val)
+	enc.limit.depth--
+}
+
 // AddBool adds a string key and a boolean value to the encoder's fields. The
 // key is JSON-escaped.
 func (enc *jsonEncoder) AddBool(key string, val bool) {
+	if !enc.limit.admit() {
+		return
+	}
 	enc.addKey(key)
 	enc.bytes = strconv.AppendBool(enc.bytes, val)
 }
@@ -120,6 +166,12 @@ func (enc *jsonEncoder) AddInt(key string, val int) {
 // AddInt64 adds a string key and int64 value to the encoder's fields. The key
 // is JSON-escaped.
 func (enc *jsonEncoder) AddInt64(key string, val int64) {
+	if enc.metrics.set(key, metricValue{kind: metricInt, i: val}) {
+		return
+	}
+	if !enc.limit.admit() {
+		return
+	}
 	enc.addKey(key)
 	enc.bytes = strconv.AppendInt(enc.bytes, val, 10)
 }
@@ -131,9 +183,22 @@ func (enc *jsonEncoder) AddUint(key string, val uint) {
 }
 
 // AddUint64 adds a string key and integer value to the encoder's fields. The key
-// is JSON-escaped.
+// is JSON-escaped. If the encoder was constructed with the QuoteLargeUints
+// option, values above 2^53 are encoded as strings.
 func (enc *jsonEncoder) AddUint64(key string, val uint64) {
+	if enc.metrics.set(key, metricValue{kind: metricUint, u: val}) {
+		return
+	}
+	if !enc.limit.admit() {
+		return
+	}
 	enc.addKey(key)
+	if enc.quoteUints && val > _maxSafeUint {
+		enc.bytes = append(enc.bytes, '"')
+		enc.bytes = strconv.AppendUint(enc.bytes, val, 10)
+		enc.bytes = append(enc.bytes, '"')
+		return
+	}
 	enc.bytes = strconv.AppendUint(enc.bytes, val, 10)
 }
 
@@ -146,6 +211,12 @@ func (enc *jsonEncoder) AddUintptr(key string, val uintptr) {
 // strconv.FormatFloat's 'f' option (always use grade-school notation, even for
 // large exponents).
 func (enc *jsonEncoder) AddFloat64(key string, val float64) {
+	if enc.metrics.set(key, metricValue{kind: metricFloat, f: val}) {
+		return
+	}
+	if !enc.limit.admit() {
+		return
+	}
 	enc.addKey(key)
 	switch {
 	case math.IsNaN(val):
@@ -161,9 +232,23 @@ func (enc *jsonEncoder) AddFloat64(key string, val float64) {
 
 // AddMarshaler adds a LogMarshaler to the encoder's fields.
 func (enc *jsonEncoder) AddMarshaler(key string, obj LogMarshaler) error {
+	if !enc.limit.admit() {
+		return nil
+	}
+	if enc.flatten {
+		prefix := enc.prefix
+		enc.prefix = prefix + key + enc.flattenSep
+		enc.limit.depth++
+		err := obj.MarshalLog(enc)
+		enc.limit.depth--
+		enc.prefix = prefix
+		return err
+	}
 	enc.addKey(key)
 	enc.bytes = append(enc.bytes, '{')
+	enc.limit.depth++
 	err := obj.MarshalLog(enc)
+	enc.limit.depth--
 	enc.bytes = append(enc.bytes, '}')
 	return err
 }
@@ -174,11 +259,53 @@ func (enc *jsonEncoder) AddObject(key string, obj interface{}) error {
 	if err != nil {
 		return err
 	}
+	if !enc.limit.admit() {
+		return nil
+	}
 	enc.addKey(key)
 	enc.bytes = append(enc.bytes, marshaled...)
 	return nil
 }
 
+// AddRawJSON adds pre-serialized JSON to the encoder's fields verbatim. Empty
+// data is encoded as null. Data containing line breaks (e.g., the output of
+// json.MarshalIndent) is compacted, so that each entry stays on one line. If
+// the encoder was constructed with the ValidateRawJSON option, or the data
+// can't be compacted, malformed data is encoded as a string instead, and an
+// error is returned.
+func (enc *jsonEncoder) AddRawJSON(key string, data []byte) error {
+	if enc.validateRaw && len(data) > 0 {
+		var raw json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			enc.AddString(key, string(data))
+			return err
+		}
+	}
+	if bytes.ContainsAny(data, "\r\n") {
+		// Line breaks can only be insignificant whitespace in valid JSON.
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, data); err != nil {
+			enc.AddString(key, string(data))
+			return err
+		}
+		data = compacted.Bytes()
+	}
+	if !enc.limit.admit() {
+		return nil
+	}
+	enc.addKey(key)
+	if len(data) == 0 {
+		enc.bytes = append(enc.bytes, "null"...)
+		return nil
+	}
+	enc.bytes = append(enc.bytes, data...)
+	return nil
+}
+
+func (enc *jsonEncoder) setMessageKey(key string) {
+	enc.messageF = MessageKey(key)
+}
+
 // Clone copies the current encoder, including any data already encoded.
 func (enc *jsonEncoder) Clone() Encoder {
 	clone := jsonPool.Get().(*jsonEncoder)
@@ -187,6 +314,16 @@ func (enc *jsonEncoder) Clone() Encoder {
 	clone.messageF = enc.messageF
 	clone.timeF = enc.timeF
 	clone.levelF = enc.levelF
+	clone.levelCodeF = enc.levelCodeF
+	clone.localTimes = enc.localTimes
+	clone.maxMsg = enc.maxMsg
+	clone.limit = enc.limit
+	clone.quoteUints = enc.quoteUints
+	clone.validateRaw = enc.validateRaw
+	clone.flatten = enc.flatten
+	clone.flattenSep = enc.flattenSep
+	clone.metrics = enc.metrics.clone()
+	clone.lineEnding = enc.lineEnding
 	return clone
 }
 
@@ -203,8 +340,24 @@ func (enc *jsonEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time
 	final.truncate()
 	final.bytes = append(final.bytes, '{')
 	enc.levelF(lvl).AddTo(final)
+	if enc.levelCodeF != nil {
+		enc.levelCodeF(lvl).AddTo(final)
+	}
 	enc.timeF(t).AddTo(final)
-	enc.messageF(msg).AddTo(final)
+	for _, lt := range enc.localTimes {
+		f := enc.timeF(t.In(lt.loc))
+		f.key = lt.key
+		f.AddTo(final)
+	}
+	truncMsg, truncated := truncateMessage(msg, enc.maxMsg)
+	enc.messageF(truncMsg).AddTo(final)
+	if truncated {
+		final.AddInt(_truncatedLengthKey, len(msg))
+	}
+	for i, key := range enc.metrics.keys {
+		final.addKey(key)
+		final.bytes = enc.metrics.appendValue(final.bytes, i)
+	}
 	if len(enc.bytes) > 0 {
 		if len(final.bytes) > 1 {
 			// All the formatters may have been no-ops.
@@ -212,7 +365,11 @@ func (enc *jsonEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time
 		}
 		final.bytes = append(final.bytes, enc.bytes...)
 	}
-	final.bytes = append(final.bytes, '}', '\n')
+	if enc.limit.dropped > 0 {
+		final.AddInt(_fieldsTruncatedKey, enc.limit.dropped)
+	}
+	final.bytes = append(final.bytes, '}')
+	final.bytes = append(final.bytes, enc.lineEnding...)
 
 	expectedBytes := len(final.bytes)
 	n, err := sink.Write(final.bytes)
@@ -228,6 +385,9 @@ func (enc *jsonEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time
 
 func (enc *jsonEncoder) truncate() {
 	enc.bytes = enc.bytes[:0]
+	enc.limit = fieldLimit{}
+	enc.prefix = ""
+	enc.metrics = hoistedMetrics{}
 }
 
 func (enc *jsonEncoder) addKey(key string) {
@@ -237,6 +397,7 @@ func (enc *jsonEncoder) addKey(key string) {
 		enc.bytes = append(enc.bytes, ',')
 	}
 	enc.bytes = append(enc.bytes, '"')
+	enc.safeAddString(enc.prefix)
 	enc.safeAddString(key)
 	enc.bytes = append(enc.bytes, '"', ':')
 }
@@ -0,0 +1,67 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.18
+// +build go1.18
+
+package zap
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzJSONEncoder(f *testing.F) {
+	f.Add("foo", "bar", []byte("baz"))
+	f.Add("", "", []byte(nil))
+	f.Add("k\"e\\y", "\x00\x1f  \t\n\r", []byte{0xff, 0xfe})
+	f.Add("\xed\xa0\x80", "\xc3\x28", []byte("\xf0\x28\x8c\xbc"))
+	f.Add("emoji", "\U0001F600<script>&", []byte("</script>"))
+
+	f.Fuzz(func(t *testing.T, key, val string, bs []byte) {
+		enc := newJSONEncoder()
+		defer enc.Free()
+
+		String(key, val).AddTo(enc)
+		Base64(key+"Base64", bs).AddTo(enc)
+		HexDump(key+"Hex", bs).AddTo(enc)
+		Object(key+"Object", map[string]interface{}{val: string(bs)}).AddTo(enc)
+		Nest(key+"Nested", String(val, key), Object(val, bs)).AddTo(enc)
+
+		buf := &bytes.Buffer{}
+		if err := enc.WriteEntry(buf, val, InfoLevel, epoch); err != nil {
+			t.Fatalf("Unexpected error writing entry: %v", err)
+		}
+		line := buf.Bytes()
+		if !json.Valid(line) {
+			t.Fatalf("Encoder produced invalid JSON: %q", line)
+		}
+
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(line, &decoded); err != nil {
+			t.Fatalf("Failed to decode entry %q: %v", line, err)
+		}
+		if utf8.ValidString(val) && decoded["msg"] != val {
+			t.Errorf("Message didn't round-trip: got %q, want %q.", decoded["msg"], val)
+		}
+	})
+}
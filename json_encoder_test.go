@@ -190,6 +190,134 @@ func TestJSONWriteEntry(t *testing.T) {
 	)
 }
 
+func TestJSONMaxMessageLength(t *testing.T) {
+	tests := []struct {
+		max      int
+		msg      string
+		expected string
+	}{
+		{0, "hello", `{"msg":"hello","foo":"bar"}`},
+		{-1, "hello", `{"msg":"hello","foo":"bar"}`},
+		{5, "hello", `{"msg":"hello","foo":"bar"}`},
+		{4, "hello", `{"msg":"hell...","originalMsgLength":5,"foo":"bar"}`},
+		{2, "héllo", `{"msg":"h...","originalMsgLength":6,"foo":"bar"}`},
+	}
+
+	for _, tt := range tests {
+		enc := NewJSONEncoder(MaxMessageLength(tt.max), NoTime(), LevelFormatter(func(Level) Field { return Skip() }))
+		enc.AddString("foo", "bar")
+		sink := &testBuffer{}
+		require.NoError(t, enc.Clone().WriteEntry(sink, tt.msg, InfoLevel, epoch), "Unexpected error writing entry.")
+		assert.Equal(t, tt.expected, sink.Stripped(), "Unexpected output with max message length %v.", tt.max)
+	}
+}
+
+func TestJSONMaxFields(t *testing.T) {
+	enc := NewJSONEncoder(MaxFields(2), SchemaVersion("v", "1"), NoTime())
+	enc.AddString("foo", "bar")
+	enc.AddMarshaler("nested", loggable{true})
+	context := enc.Clone()
+	context.AddInt("dropped", 42)
+	context.AddObject("alsoDropped", []int{1})
+	sink := &testBuffer{}
+	require.NoError(t, context.WriteEntry(sink, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(
+		t,
+		`{"level":"info","msg":"hello","v":"1","foo":"bar","nested":{"loggable":"yes"},"fieldsTruncated":2}`,
+		sink.Stripped(),
+		"Unexpected output when exceeding max fields.",
+	)
+
+	sink.Reset()
+	require.NoError(t, enc.WriteEntry(sink, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.NotContains(t, sink.String(), "fieldsTruncated", "Expected parent encoder to be unaffected by clone.")
+}
+
+func TestJSONQuoteLargeUints(t *testing.T) {
+	tests := []struct {
+		val      uint64
+		expected string
+	}{
+		{42, `"k":42`},
+		{1 << 53, `"k":9007199254740992`},
+		{1<<53 + 1, `"k":"9007199254740993"`},
+		{math.MaxUint64, fmt.Sprintf(`"k":"%d"`, uint64(math.MaxUint64))},
+	}
+
+	for _, tt := range tests {
+		enc := newJSONEncoder(QuoteLargeUints())
+		enc.AddUint64("k", tt.val)
+		assertJSON(t, tt.expected, enc)
+
+		clone := enc.Clone().(*jsonEncoder)
+		clone.truncate()
+		clone.AddUint64("k", tt.val)
+		assertJSON(t, tt.expected, clone)
+	}
+
+	enc := newJSONEncoder()
+	enc.AddUint64("k", math.MaxUint64)
+	assertJSON(t, fmt.Sprintf(`"k":%d`, uint64(math.MaxUint64)), enc)
+}
+
+func TestJSONFlattenNested(t *testing.T) {
+	enc := newJSONEncoder(FlattenNested("."))
+	enc.AddString("foo", "bar")
+	Nest("user", String("name", "phil"), Nest("address", String("city", "NYC"))).AddTo(enc)
+	Marshaler("failed", loggable{false}).AddTo(enc)
+	enc.AddObject("obj", map[string]int{"n": 1})
+	assertJSON(t, `"foo":"bar","user.name":"phil","user.address.city":"NYC","failedError":"can't marshal","obj":{"n":1}`, enc)
+
+	sink := &testBuffer{}
+	clone := enc.Clone()
+	clone.AddString("baz", "quux")
+	require.NoError(t, clone.WriteEntry(sink, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Contains(t, sink.String(), `"msg":"hello","foo":"bar"`, "Expected entry keys not to be prefixed.")
+	assert.Contains(t, sink.String(), `"obj":{"n":1},"baz":"quux"}`, "Expected fields added to clone not to be prefixed.")
+}
+
+func TestJSONHoistMetrics(t *testing.T) {
+	enc := newJSONEncoder(HoistMetrics("bytes", "latency", "retries", "ratio"), MaxFields(2))
+	enc.AddInt("bytes", 1)
+	enc.AddString("foo", "bar")
+	Nest("req", Int("bytes", 512), Float64("latency", 1.5), String("path", "/")).AddTo(enc)
+	assertJSON(t, `"foo":"bar","req":{"path":"/"}`, enc)
+
+	clone := enc.Clone()
+	clone.AddFloat64("ratio", math.NaN())
+	clone.AddUint("baz", 3)
+
+	sink := &testBuffer{}
+	require.NoError(t, clone.WriteEntry(sink, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(
+		t,
+		`{"level":"info","ts":0,"msg":"hello","bytes":512,"latency":1.5,"retries":0,"ratio":null,"foo":"bar","req":{"path":"/"},"fieldsTruncated":1}`,
+		sink.Stripped(),
+		"Unexpected hoisted metrics.",
+	)
+
+	sink.Reset()
+	require.NoError(t, enc.WriteEntry(sink, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Contains(t, sink.String(), `"msg":"hello","bytes":512,"latency":1.5,"retries":0,"ratio":0,`, "Expected clone not to modify the original encoder's metrics.")
+}
+
+func TestJSONNestedObjectsInheritSettings(t *testing.T) {
+	fields := []Field{
+		Time("time", time.Unix(1, 500000000)),
+		Duration("duration", time.Second),
+		Uint64("uint", 1<<53+1),
+	}
+	enc := newJSONEncoder(QuoteLargeUints())
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	top := string(enc.bytes)
+
+	nested := newJSONEncoder(QuoteLargeUints())
+	Nest("outer", Nest("inner", fields...)).AddTo(nested)
+	assert.Equal(t, `"outer":{"inner":{`+top+`}}`, string(nested.bytes), "Expected nested fields to be encoded like top-level fields.")
+}
+
 func TestJSONWriteEntryLargeTimestamps(t *testing.T) {
 	// Ensure that we don't switch to exponential notation when encoding dates far in the future.
 	sink := &testBuffer{}
@@ -20,7 +20,10 @@
 
 package zap
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // JSONOption is used to set options for a JSON encoder. MessageFormatters,
 // TimeFormatters, and LevelFormatters all implement the JSONOption interface.
@@ -28,6 +31,148 @@ type JSONOption interface {
 	apply(*jsonEncoder)
 }
 
+type jsonOptionFunc func(*jsonEncoder)
+
+func (opt jsonOptionFunc) apply(enc *jsonEncoder) {
+	opt(enc)
+}
+
+// MaxMessageLength truncates log messages longer than the supplied number of
+// bytes, which protects downstream systems from pathologically large entries.
+// Truncated messages end with an ellipsis, and the original length is recorded
+// under the "originalMsgLength" key. Zero or negative limits disable
+// truncation.
+func MaxMessageLength(max int) JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.maxMsg = max
+	})
+}
+
+// MaxFields limits the number of fields encoded in each log entry, which
+// guards downstream systems against runaway chains of With calls. Once the
+// limit is reached, further fields are dropped and the number dropped is
+// recorded under the "fieldsTruncated" key. Fields are admitted in insertion
+// order: fields added to the logger's context with With count toward the
+// limit (and are always encoded before the fields passed at the log site),
+// but fields nested inside a LogMarshaler don't. Zero or negative limits
+// disable the check.
+func MaxFields(max int) JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.limit.max = max
+	})
+}
+
+// FlattenNested encodes the fields of nested objects (e.g., Marshaler and
+// Nest fields) as top-level keys, prefixed with the parent's key and the
+// supplied separator. For example, with a "." separator, a user object with
+// an id field is encoded as "user.id" rather than {"user":{"id":...}}. This
+// helps legacy consumers that can't handle nested JSON. Fields added with
+// Object are serialized by encoding/json, so they aren't flattened.
+//
+// Since the encoder doesn't deduplicate keys, flattening may produce duplicate
+// keys: for example, a "user.id" field logged alongside a nested user object
+// with an id. Like other duplicate keys, both are encoded, and most consumers
+// keep the last.
+func FlattenNested(sep string) JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.flatten = true
+		enc.flattenSep = sep
+	})
+}
+
+// HoistMetrics guarantees that the supplied keys appear as top-level JSON
+// numbers in every entry, which lets metrics scrapers reliably extract
+// counters from newline-delimited JSON logs. The hoisting rules are:
+//
+//   - Numeric fields (ints, uints, and floats) whose key matches one of the
+//     supplied keys are removed from where they were logged, even if nested
+//     inside a LogMarshaler, and encoded at the top level immediately after
+//     the message. Matching uses the field's own key, ignoring the keys of its
+//     parents (and any FlattenNested prefix).
+//   - Hoisted keys are encoded in the order supplied. If a key is logged more
+//     than once, the last value wins.
+//   - Keys that aren't logged are encoded as 0. NaN and infinite values, which
+//     can't be represented as JSON numbers, are encoded as null.
+//   - Non-numeric fields are never hoisted. Logging one under a metric key
+//     produces a duplicate key, so avoid reusing metric keys for other data.
+//
+// Hoisted fields don't count toward the MaxFields limit.
+func HoistMetrics(keys ...string) JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.metrics = hoistedMetrics{keys: keys}
+	})
+}
+
+// ValidateRawJSON checks that the data in RawJSON fields is well-formed JSON
+// before writing it. Malformed data is encoded as a string, with the parsing
+// error under the field's key plus "Error". Since validation parses the data,
+// it's relatively expensive; it's most useful in development, to catch
+// corrupted payloads.
+func ValidateRawJSON() JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.validateRaw = true
+	})
+}
+
+// AddLevelCode adds a numeric representation of each entry's level under the
+// provided key, alongside the level added by the encoder's LevelFormatter.
+// Levels missing from the supplied map are encoded as their underlying integer
+// values. To replace the textual level altogether, use the LevelCode
+// formatter instead.
+func AddLevelCode(key string, codes map[Level]int) JSONOption {
+	lf := LevelCode(key, codes)
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.levelCodeF = lf
+	})
+}
+
+// AddLocalTime adds the entry time in the supplied location under the
+// provided key, alongside the time added by the encoder's TimeFormatter. The
+// local time is formatted with the encoder's TimeFormatter, so it's most
+// useful with formatters that include a zone offset (e.g., RFC3339Formatter).
+// A nil location is treated as UTC. The option may be used more than once to
+// add several zones.
+func AddLocalTime(loc *time.Location, key string) JSONOption {
+	lt := newLocalTime(loc, key)
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.localTimes = append(enc.localTimes, lt)
+	})
+}
+
+// SchemaVersion adds a constant schema version to every entry, under the
+// provided key. The version is serialized once, when the encoder is
+// constructed, so it's cheaper than adding it with the Fields option. It
+// always appears before any other context fields.
+func SchemaVersion(key, version string) JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		// The schema version shouldn't count toward MaxFields.
+		enc.addUnlimitedString(key, version)
+	})
+}
+
+// QuoteLargeUints encodes unsigned integers above 2^53 as JSON strings rather
+// than numbers. Many JSON parsers (including JavaScript's) represent all
+// numbers as float64s, which silently loses precision for larger values.
+func QuoteLargeUints() JSONOption {
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.quoteUints = true
+	})
+}
+
+// LineEnding sets the terminator appended to each entry by the JSON encoders
+// (including the canonical and signed ones), which is "\n" by default; the
+// other encoders always use "\n". Since other line endings could corrupt the
+// framing of newline-delimited JSON, only "\n" and "\r\n" are supported, and
+// LineEnding panics if passed anything else.
+func LineEnding(ending string) JSONOption {
+	if ending != "\n" && ending != "\r\n" {
+		panic(fmt.Sprintf("unsupported line ending %q: use \"\\n\" or \"\\r\\n\"", ending))
+	}
+	return jsonOptionFunc(func(enc *jsonEncoder) {
+		enc.lineEnding = ending
+	})
+}
+
 // A MessageFormatter defines how to convert a log message into a Field.
 // MessageFormatters implement the JSONOption interface.
 type MessageFormatter func(string) Field
@@ -83,6 +228,34 @@ func (lf LevelFormatter) apply(enc *jsonEncoder) {
 	enc.levelF = lf
 }
 
+// LevelCode encodes the entry's level as a number under the provided key,
+// which is useful for tools that expect numeric severities. Levels missing
+// from the supplied map are encoded as their underlying integer values. See
+// SyslogSeverities for a common mapping.
+func LevelCode(key string, codes map[Level]int) LevelFormatter {
+	copied := make(map[Level]int, len(codes))
+	for k, v := range codes {
+		copied[k] = v
+	}
+	return LevelFormatter(func(l Level) Field {
+		if code, ok := copied[l]; ok {
+			return Int(key, code)
+		}
+		return Int(key, int(l))
+	})
+}
+
+// SyslogSeverities returns a mapping of levels to RFC5424 syslog severities
+// (0 for emergencies through 7 for debug messages), for use with LevelCode and
+// AddLevelCode. Each call returns a new map, so callers may modify the result.
+func SyslogSeverities() map[Level]int {
+	m := make(map[Level]int, 6)
+	for l := DebugLevel; l <= FatalLevel; l++ {
+		m[l] = syslogSeverity(l)
+	}
+	return m
+}
+
 // LevelString encodes the entry's level under the provided key. It uses the
 // level's String method to serialize it.
 func LevelString(key string) LevelFormatter {
@@ -21,7 +21,9 @@
 package zap
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -68,6 +70,8 @@ func TestLevelFormatters(t *testing.T) {
 		expected  Field
 	}{
 		{"LevelString", LevelString("the-level"), String("the-level", "info")},
+		{"LevelCode", LevelCode("severity", SyslogSeverities()), Int("severity", 6)},
+		{"LevelCode unmapped", LevelCode("severity", map[Level]int{}), Int("severity", int(InfoLevel))},
 		{"Default", defaultLevelF, String("level", "info")},
 	}
 
@@ -75,3 +79,122 @@ func TestLevelFormatters(t *testing.T) {
 		assert.Equal(t, tt.expected, tt.formatter(lvl), "Unexpected output from LevelFormatter %s.", tt.name)
 	}
 }
+
+func TestAddLevelCode(t *testing.T) {
+	codes := map[Level]int{InfoLevel: 42}
+	enc := NewJSONEncoder(NoTime(), AddLevelCode("severity", codes))
+	codes[InfoLevel] = 0
+
+	sink := &testBuffer{}
+	assert.NoError(t, enc.Clone().WriteEntry(sink, "foo", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.NoError(t, enc.WriteEntry(sink, "foo", WarnLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, []string{
+		`{"level":"info","severity":42,"msg":"foo"}`,
+		`{"level":"warn","severity":1,"msg":"foo"}`,
+	}, sink.Lines(), "Unexpected output with AddLevelCode.")
+}
+
+func TestAddLocalTime(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	enc := NewJSONEncoder(
+		RFC3339Formatter("ts"),
+		AddLocalTime(tokyo, "tsTokyo"),
+		AddLocalTime(nil, "tsUTC"),
+	)
+	sink := &testBuffer{}
+	assert.NoError(t, enc.Clone().WriteEntry(sink, "foo", InfoLevel, epoch.UTC()), "Unexpected error writing entry.")
+	assert.Equal(
+		t,
+		`{"level":"info","ts":"1970-01-01T00:00:00Z","tsTokyo":"1970-01-01T09:00:00+09:00","tsUTC":"1970-01-01T00:00:00Z","msg":"foo"}`,
+		sink.Stripped(),
+		"Unexpected output with AddLocalTime.",
+	)
+
+	sink.Reset()
+	enc = NewJSONEncoder(NoTime(), AddLocalTime(tokyo, "tsTokyo"))
+	assert.NoError(t, enc.WriteEntry(sink, "foo", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, `{"level":"info","msg":"foo"}`, sink.Stripped(), "Expected NoTime to omit local times.")
+}
+
+func TestSyslogSeveritiesMapping(t *testing.T) {
+	assert.Equal(t, map[Level]int{
+		DebugLevel: 7,
+		InfoLevel:  6,
+		WarnLevel:  4,
+		ErrorLevel: 3,
+		PanicLevel: 2,
+		FatalLevel: 2,
+	}, SyslogSeverities(), "Unexpected syslog severity mapping.")
+}
+
+func TestSchemaVersion(t *testing.T) {
+	buf := &testBuffer{}
+	logger := New(
+		NewJSONEncoder(NoTime(), SchemaVersion("schema", "v2")),
+		Output(buf),
+		Fields(String("foo", "bar")),
+	)
+	logger.With(Int("n", 1)).Info("hello")
+	assert.Equal(t, `{"level":"info","msg":"hello","schema":"v2","foo":"bar","n":1}`, buf.Stripped(),
+		"Expected schema version before all other fields.")
+}
+
+func TestSchemaVersionSurvivesZeroFields(t *testing.T) {
+	buf := &testBuffer{}
+	logger := New(
+		NewJSONEncoder(NoTime(), SchemaVersion("schema", "v2")),
+		Output(buf),
+		Fields(String("foo", "bar")),
+	)
+	ZeroFields(logger.With(Int("n", 1))).Info("hello")
+	assert.Equal(t, `{"level":"info","msg":"hello","schema":"v2"}`, buf.Stripped(),
+		"Expected schema version to survive ZeroFields, unlike other context.")
+}
+
+func TestLineEnding(t *testing.T) {
+	tests := []struct {
+		ending   string
+		expected string
+	}{
+		{"\n", "\n"},
+		{"\r\n", "\r\n"},
+	}
+	for _, tt := range tests {
+		buf := &testBuffer{}
+		enc := NewJSONEncoder(NoTime(), LineEnding(tt.ending))
+		assert.NoError(t, enc.WriteEntry(buf, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+		assert.Equal(t, `{"level":"info","msg":"hello"}`+tt.expected, buf.String(), "Unexpected line ending for %q.", tt.ending)
+
+		buf.Reset()
+		clone := enc.Clone()
+		assert.NoError(t, clone.WriteEntry(buf, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+		assert.Equal(t, `{"level":"info","msg":"hello"}`+tt.expected, buf.String(), "Expected clones to keep the line ending for %q.", tt.ending)
+	}
+}
+
+func TestLineEndingInvalid(t *testing.T) {
+	for _, ending := range []string{"", "\n\n", "}\n{", "\r"} {
+		assert.Panics(t, func() { LineEnding(ending) }, "Expected a panic for unsupported line ending %q.", ending)
+	}
+}
+
+func TestLineEndingWrappedEncoders(t *testing.T) {
+	tests := []struct {
+		desc   string
+		enc    Encoder
+		ending string
+	}{
+		{"canonical", NewCanonicalJSONEncoder(NoTime(), LineEnding("\r\n")), "\r\n"},
+		{"signed", NewSignedJSONEncoder(NewSigner("k1", []byte("secret")), NoTime(), LineEnding("\r\n")), "\r\n"},
+		// The text encoder always ends entries with "\n".
+		{"combined", CombineEncoders(NewJSONEncoder(NoTime(), LineEnding("\r\n")), NewTextEncoder(TextNoTime()), " # "), "\n"},
+	}
+	for _, tt := range tests {
+		buf := &testBuffer{}
+		assert.NoError(t, tt.enc.WriteEntry(buf, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+		out := buf.String()
+		assert.True(t, strings.HasSuffix(out, tt.ending), "Expected the %s encoder to end with %q, got %q.", tt.desc, tt.ending, out)
+		assert.Equal(t, 1, strings.Count(out, "\n"), "Expected a single line from the %s encoder.", tt.desc)
+		assert.NotContains(t, strings.TrimSuffix(out, "\r\n"), "\r", "Unexpected interior CR from the %s encoder.", tt.desc)
+	}
+}
@@ -39,3 +39,130 @@ type KeyValue interface {
 	AddObject(key string, value interface{}) error
 	AddString(key, value string)
 }
+
+// teeKeyValue adds each field to two KeyValues.
+type teeKeyValue struct {
+	a, b KeyValue
+}
+
+func (t teeKeyValue) AddBool(key string, value bool) {
+	t.a.AddBool(key, value)
+	t.b.AddBool(key, value)
+}
+
+func (t teeKeyValue) AddFloat64(key string, value float64) {
+	t.a.AddFloat64(key, value)
+	t.b.AddFloat64(key, value)
+}
+
+func (t teeKeyValue) AddInt(key string, value int) {
+	t.a.AddInt(key, value)
+	t.b.AddInt(key, value)
+}
+
+func (t teeKeyValue) AddInt64(key string, value int64) {
+	t.a.AddInt64(key, value)
+	t.b.AddInt64(key, value)
+}
+
+func (t teeKeyValue) AddUint(key string, value uint) {
+	t.a.AddUint(key, value)
+	t.b.AddUint(key, value)
+}
+
+func (t teeKeyValue) AddUint64(key string, value uint64) {
+	t.a.AddUint64(key, value)
+	t.b.AddUint64(key, value)
+}
+
+func (t teeKeyValue) AddUintptr(key string, value uintptr) {
+	t.a.AddUintptr(key, value)
+	t.b.AddUintptr(key, value)
+}
+
+func (t teeKeyValue) AddMarshaler(key string, marshaler LogMarshaler) error {
+	errA := t.a.AddMarshaler(key, marshaler)
+	errB := t.b.AddMarshaler(key, marshaler)
+	if errA != nil {
+		return errA
+	}
+	return errB
+}
+
+func (t teeKeyValue) AddObject(key string, value interface{}) error {
+	errA := t.a.AddObject(key, value)
+	errB := t.b.AddObject(key, value)
+	if errA != nil {
+		return errA
+	}
+	return errB
+}
+
+// AddRawJSON writes the data verbatim to each KeyValue that supports it, and
+// as a string to the others.
+func (t teeKeyValue) AddRawJSON(key string, data []byte) error {
+	errA := addRawJSON(t.a, key, data)
+	errB := addRawJSON(t.b, key, data)
+	if errA != nil {
+		return errA
+	}
+	return errB
+}
+
+func (t teeKeyValue) AddString(key, value string) {
+	t.a.AddString(key, value)
+	t.b.AddString(key, value)
+}
+
+// AddFields passes the fields to each KeyValue separately, so that encoders
+// implementing FieldsAdder see them all at once.
+func (t teeKeyValue) AddFields(fields []Field) {
+	addFields(t.a, fields)
+	addFields(t.b, fields)
+}
+
+// fieldsKeyValue records everything added to it as Fields, so that loggers
+// without an encoder (like ChannelLogger) can run hooks.
+type fieldsKeyValue []Field
+
+func (fs *fieldsKeyValue) AddBool(key string, value bool) {
+	*fs = append(*fs, Bool(key, value))
+}
+
+func (fs *fieldsKeyValue) AddFloat64(key string, value float64) {
+	*fs = append(*fs, Float64(key, value))
+}
+
+func (fs *fieldsKeyValue) AddInt(key string, value int) {
+	*fs = append(*fs, Int(key, value))
+}
+
+func (fs *fieldsKeyValue) AddInt64(key string, value int64) {
+	*fs = append(*fs, Int64(key, value))
+}
+
+func (fs *fieldsKeyValue) AddUint(key string, value uint) {
+	*fs = append(*fs, Uint(key, value))
+}
+
+func (fs *fieldsKeyValue) AddUint64(key string, value uint64) {
+	*fs = append(*fs, Uint64(key, value))
+}
+
+func (fs *fieldsKeyValue) AddUintptr(key string, value uintptr) {
+	*fs = append(*fs, Uintptr(key, value))
+}
+
+func (fs *fieldsKeyValue) AddMarshaler(key string, marshaler LogMarshaler) error {
+	*fs = append(*fs, Marshaler(key, marshaler))
+	return nil
+}
+
+func (fs *fieldsKeyValue) AddObject(key string, value interface{}) error {
+	*fs = append(*fs, Object(key, value))
+	return nil
+}
+
+func (fs *fieldsKeyValue) AddString(key, value string) {
+	*fs = append(*fs, String(key, value))
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "sync"
+
+// WithLazy creates a child logger that defers adding context. Unlike
+// Logger.With, which eagerly serializes the supplied fields into a copy of the
+// parent's encoder, WithLazy only stores them; the child is materialized the
+// first time it writes an enabled log entry, and then reused for all subsequent
+// entries. This makes WithLazy a good choice for child loggers that are shared
+// widely but rarely write.
+//
+// Since serialization is deferred, any lazily-marshaled fields (e.g.,
+// Marshaler or Object) reflect the state of their values at the time of the
+// first write rather than the time of the call to WithLazy. Parents that
+// aren't LevelEnablers can't be asked about levels without side effects, so
+// their children are materialized on first use.
+func WithLazy(parent Logger, fields ...Field) Logger {
+	return &lazyLogger{
+		parent: parent,
+		fields: fields,
+	}
+}
+
+type lazyLogger struct {
+	parent Logger
+	fields []Field
+
+	once  sync.Once
+	child Logger
+}
+
+func (l *lazyLogger) materialize() Logger {
+	l.once.Do(func() {
+		l.child = l.parent.With(l.fields...)
+	})
+	return l.child
+}
+
+// Enabled reports whether the parent writes entries at the level, without
+// materializing the child. It implements LevelEnabler.
+func (l *lazyLogger) Enabled(lvl Level) bool {
+	return enabled(l.parent, lvl)
+}
+
+func (l *lazyLogger) With(fields ...Field) Logger {
+	all := make([]Field, 0, len(l.fields)+len(fields))
+	all = append(all, l.fields...)
+	all = append(all, fields...)
+	return WithLazy(l.parent, all...)
+}
+
+func (l *lazyLogger) Check(lvl Level, msg string) *CheckedMessage {
+	if !l.Enabled(lvl) {
+		return nil
+	}
+	return l.materialize().Check(lvl, msg)
+}
+
+func (l *lazyLogger) Log(lvl Level, msg string, fields ...Field) {
+	if l.Enabled(lvl) {
+		l.materialize().Log(lvl, msg, fields...)
+	}
+}
+
+func (l *lazyLogger) Debug(msg string, fields ...Field) {
+	if l.Enabled(DebugLevel) {
+		l.materialize().Debug(msg, fields...)
+	}
+}
+
+func (l *lazyLogger) Info(msg string, fields ...Field) {
+	if l.Enabled(InfoLevel) {
+		l.materialize().Info(msg, fields...)
+	}
+}
+
+func (l *lazyLogger) Warn(msg string, fields ...Field) {
+	if l.Enabled(WarnLevel) {
+		l.materialize().Warn(msg, fields...)
+	}
+}
+
+func (l *lazyLogger) Error(msg string, fields ...Field) {
+	if l.Enabled(ErrorLevel) {
+		l.materialize().Error(msg, fields...)
+	}
+}
+
+func (l *lazyLogger) Panic(msg string, fields ...Field) {
+	l.materialize().Panic(msg, fields...)
+}
+
+func (l *lazyLogger) Fatal(msg string, fields ...Field) {
+	l.materialize().Fatal(msg, fields...)
+}
+
+func (l *lazyLogger) DFatal(msg string, fields ...Field) {
+	// Whether DFatal logs at Error or Fatal depends on the underlying logger's
+	// development flag, so always defer to the child.
+	l.materialize().DFatal(msg, fields...)
+}
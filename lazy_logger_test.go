@@ -0,0 +1,101 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingMarshaler struct{ calls int }
+
+func (c *countingMarshaler) MarshalLog(kv KeyValue) error {
+	c.calls++
+	kv.AddInt("calls", c.calls)
+	return nil
+}
+
+func TestWithLazy(t *testing.T) {
+	withJSONLogger(t, opts(InfoLevel), func(logger Logger, buf *testBuffer) {
+		counter := &countingMarshaler{}
+		child := WithLazy(logger, Marshaler("counter", counter), String("foo", "bar"))
+
+		child.Debug("disabled")
+		assert.Equal(t, 0, counter.calls, "Expected disabled log levels not to materialize the child.")
+		assert.Nil(t, child.Check(DebugLevel, "disabled"), "Expected a nil CheckedMessage at disabled levels.")
+		assert.Equal(t, 0, counter.calls, "Expected checking disabled levels not to materialize the child.")
+
+		child.Info("one")
+		child.Warn("two")
+		child.Check(ErrorLevel, "three").Write()
+		child.Log(ErrorLevel, "four")
+		assert.Equal(t, 1, counter.calls, "Expected fields to be marshaled exactly once.")
+		assert.Equal(t, []string{
+			`{"level":"info","msg":"one","counter":{"calls":1},"foo":"bar"}`,
+			`{"level":"warn","msg":"two","counter":{"calls":1},"foo":"bar"}`,
+			`{"level":"error","msg":"three","counter":{"calls":1},"foo":"bar"}`,
+			`{"level":"error","msg":"four","counter":{"calls":1},"foo":"bar"}`,
+		}, buf.Lines(), "Unexpected output from lazy logger.")
+
+		buf.Reset()
+		logger.Info("parent")
+		assert.Equal(t, `{"level":"info","msg":"parent"}`, buf.Stripped(), "Expected lazy child not to affect parent.")
+	})
+}
+
+func TestWithLazyWith(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		parent := WithLazy(logger, Int("generation", 1))
+		parent.With(Int("generation", 2)).Info("child")
+		parent.Info("parent")
+		assert.Equal(t, []string{
+			`{"level":"info","msg":"child","generation":1,"generation":2}`,
+			`{"level":"info","msg":"parent","generation":1}`,
+		}, buf.Lines(), "Unexpected output from nested lazy loggers.")
+	})
+}
+
+func TestWithLazyPanicFatal(t *testing.T) {
+	stub := stubExit()
+	defer stub.Unstub()
+
+	withJSONLogger(t, opts(FatalLevel+1), func(logger Logger, buf *testBuffer) {
+		child := WithLazy(logger, String("foo", "bar"))
+		assert.Panics(t, func() { child.Panic("foo") }, "Expected lazy logger to panic.")
+		child.Fatal("foo")
+		stub.AssertStatus(t, 1)
+		assert.Empty(t, buf.String(), "Expected disabled Panic and Fatal not to be logged.")
+	})
+}
+
+func TestWithLazyConcurrent(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		counter := &countingMarshaler{}
+		child := WithLazy(logger, Marshaler("counter", counter))
+		wg := &sync.WaitGroup{}
+		runConcurrently(5, 10, wg, func() { child.Info("info") })
+		wg.Wait()
+		assert.Equal(t, 1, counter.calls, "Expected concurrent use to materialize the child once.")
+		assert.Len(t, buf.Lines(), 50, "Unexpected number of log lines.")
+	})
+}
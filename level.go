@@ -23,11 +23,19 @@ package zap
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/uber-go/atomic"
 )
 
-var errMarshalNilLevel = errors.New("can't marshal a nil *Level to text")
+var (
+	errMarshalNilLevel = errors.New("can't marshal a nil *Level to text")
+	errEmptyLevelName  = errors.New("can't register a level with an empty name")
+
+	_customLevelsMu sync.RWMutex
+	_customNames    = make(map[Level]string)
+	_customLevels   = make(map[string]Level)
+)
 
 // A Level is a logging priority. Higher levels are more important.
 //
@@ -85,10 +93,57 @@ func (l Level) String() string {
 	case FatalLevel:
 		return "fatal"
 	default:
+		if name, ok := customLevelName(l); ok {
+			return name
+		}
 		return fmt.Sprintf("Level(%d)", l)
 	}
 }
 
+// RegisterLevel names a custom Level so that it's rendered by encoders and
+// parsed by UnmarshalText (and LevelFlag) like the built-in levels. Custom
+// levels are ordered by their numeric value, so Enabled gates them the same
+// way it gates the built-in ones.
+//
+// Since the built-in levels occupy consecutive values from DebugLevel to
+// FatalLevel, custom levels must be registered below DebugLevel or above
+// FatalLevel; there's no room for a level between two built-in ones, like a
+// "notice" level between Info and Warn. Systems that use such levels should
+// map them onto the nearest built-in level. Registering a value or name
+// that's already in use returns an error. RegisterLevel is typically called
+// from an init function.
+func RegisterLevel(lvl Level, name string) error {
+	if name == "" {
+		return errEmptyLevelName
+	}
+	if lvl >= DebugLevel && lvl <= FatalLevel {
+		return fmt.Errorf("can't register %v: value is used by the built-in level %v", name, lvl)
+	}
+	var builtin Level
+	if builtin.unmarshalBuiltin(name) {
+		return fmt.Errorf("can't register %v: name is used by a built-in level", name)
+	}
+
+	_customLevelsMu.Lock()
+	defer _customLevelsMu.Unlock()
+	if existing, ok := _customNames[lvl]; ok {
+		return fmt.Errorf("can't register %v: Level(%d) is already registered as %v", name, lvl, existing)
+	}
+	if _, ok := _customLevels[name]; ok {
+		return fmt.Errorf("can't register %v: name is already registered", name)
+	}
+	_customNames[lvl] = name
+	_customLevels[name] = lvl
+	return nil
+}
+
+func customLevelName(lvl Level) (string, bool) {
+	_customLevelsMu.RLock()
+	name, ok := _customNames[lvl]
+	_customLevelsMu.RUnlock()
+	return name, ok
+}
+
 // MarshalText marshals the Level to text. Note that the text representation
 // drops the -Level suffix (see example).
 func (l *Level) MarshalText() ([]byte, error) {
@@ -100,12 +155,26 @@ func (l *Level) MarshalText() ([]byte, error) {
 
 // UnmarshalText unmarshals text to a level. Like MarshalText, UnmarshalText
 // expects the text representation of a Level to drop the -Level suffix (see
-// example).
+// example). Names added with RegisterLevel are also recognized.
 //
 // In particular, this makes it easy to configure logging levels using YAML,
 // TOML, or JSON files.
 func (l *Level) UnmarshalText(text []byte) error {
-	switch string(text) {
+	if l.unmarshalBuiltin(string(text)) {
+		return nil
+	}
+	_customLevelsMu.RLock()
+	lvl, ok := _customLevels[string(text)]
+	_customLevelsMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unrecognized level: %v", string(text))
+	}
+	*l = lvl
+	return nil
+}
+
+func (l *Level) unmarshalBuiltin(text string) bool {
+	switch text {
 	case "debug":
 		*l = DebugLevel
 	case "info":
@@ -119,9 +188,9 @@ func (l *Level) UnmarshalText(text []byte) error {
 	case "fatal":
 		*l = FatalLevel
 	default:
-		return fmt.Errorf("unrecognized level: %v", string(text))
+		return false
 	}
-	return nil
+	return true
 }
 
 // Enabled returns true if the given level is at or above this level.
@@ -89,3 +89,54 @@ func TestLevelUnmarshalUnknownText(t *testing.T) {
 	err := l.UnmarshalText([]byte("foo"))
 	assert.Contains(t, err.Error(), "unrecognized level", "Expected unmarshaling arbitrary text to fail.")
 }
+
+func withCustomLevels(t *testing.T, f func()) {
+	defer func() {
+		_customLevelsMu.Lock()
+		_customNames = make(map[Level]string)
+		_customLevels = make(map[string]Level)
+		_customLevelsMu.Unlock()
+	}()
+	f()
+}
+
+func TestRegisterLevel(t *testing.T) {
+	withCustomLevels(t, func() {
+		notice := FatalLevel + 1
+		assert.NoError(t, RegisterLevel(notice, "notice"), "Unexpected error registering a custom level.")
+		assert.Equal(t, "notice", notice.String(), "Unexpected name for a registered level.")
+
+		marshaled, err := notice.MarshalText()
+		assert.NoError(t, err, "Unexpected error marshaling a custom level.")
+		assert.Equal(t, "notice", string(marshaled), "Unexpected text for a custom level.")
+
+		var lvl Level
+		assert.NoError(t, lvl.UnmarshalText([]byte("notice")), "Unexpected error unmarshaling a custom level.")
+		assert.Equal(t, notice, lvl, "Unexpected level unmarshaled from a registered name.")
+		assert.True(t, WarnLevel.Enabled(lvl), "Expected custom levels to be ordered numerically.")
+		assert.False(t, lvl.Enabled(FatalLevel), "Expected custom levels to be ordered numerically.")
+
+		assert.Error(t, lvl.UnmarshalText([]byte("trace")), "Expected an error unmarshaling an unregistered name.")
+	})
+}
+
+func TestRegisterLevelErrors(t *testing.T) {
+	withCustomLevels(t, func() {
+		assert.NoError(t, RegisterLevel(Level(-2), "trace"), "Unexpected error registering a custom level.")
+
+		tests := []struct {
+			lvl  Level
+			name string
+		}{
+			{Level(-3), ""},
+			{InfoLevel, "notice"},
+			{Level(10), "warn"},
+			{Level(-2), "finest"},
+			{Level(-3), "trace"},
+		}
+		for _, tt := range tests {
+			assert.Error(t, RegisterLevel(tt.lvl, tt.name), "Expected an error registering %v as Level(%d).", tt.name, tt.lvl)
+		}
+		assert.Equal(t, "Level(-3)", Level(-3).String(), "Failed registrations shouldn't name levels.")
+	})
+}
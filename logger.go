@@ -21,12 +21,37 @@
 package zap
 
 import (
+	"io"
 	"os"
 )
 
-// For tests.
+// For tests; see ReplaceExit.
 var _exit = os.Exit
 
+// ReplaceExit replaces the function that the Fatal methods of this package's
+// loggers (and other loggers that call Exit) use to terminate the process
+// (os.Exit by default), and returns a function to restore the original. It
+// lets tests exercise fatal paths without exiting; see the spy package's
+// RecordExits for a ready-made recorder. Since code following a call to Fatal
+// normally never runs, the replacement may want to stop the calling goroutine
+// with runtime.Goexit.
+//
+// Unlike ReplaceGlobals, ReplaceExit isn't safe to call concurrently with
+// logging, so call it before starting any goroutines that log.
+func ReplaceExit(exit func(int)) func() {
+	prev := _exit
+	_exit = exit
+	return func() { _exit = prev }
+}
+
+// Exit terminates the process with the supplied status code, using the
+// function installed with ReplaceExit. Loggers implemented outside this
+// package should call it from their Fatal methods, so that ReplaceExit (and
+// the spy package's RecordExits) intercepts their exits too.
+func Exit(code int) {
+	_exit(code)
+}
+
 // A Logger enables leveled, structured logging. All methods are safe for
 // concurrent use.
 type Logger interface {
@@ -59,7 +84,14 @@ type Logger interface {
 	DFatal(string, ...Field)
 }
 
-type logger struct{ Meta }
+type logger struct {
+	Meta
+
+	// Context added with With, if the DeferFields option is set.
+	context []Field
+	// A copy of the encoder before any context was added, for ZeroFields.
+	base Encoder
+}
 
 // New constructs a logger that uses the provided encoder. By default, the
 // logger will write Info logs or higher to standard out. Any errors during logging
@@ -69,49 +101,165 @@ type logger struct{ Meta }
 // that should be added as context, and many other behaviors.
 func New(enc Encoder, options ...Option) Logger {
 	return &logger{
-		Meta: MakeMeta(enc, options...),
+		Meta: MakeMeta(enc.Clone(), options...),
+		base: enc,
+	}
+}
+
+// ZeroFields creates a child logger with the same configuration (level,
+// hooks, outputs, and so on) as the supplied logger, but without any context.
+// Both fields added with With and those supplied with the Fields option are
+// dropped. It's useful for branching a clean per-request logger from a shared
+// base logger.
+//
+// ZeroFields supports loggers created with New and Tee; other loggers are
+// returned unchanged.
+func ZeroFields(log Logger) Logger {
+	if zf, ok := log.(interface {
+		zeroFields() Logger
+	}); ok {
+		return zf.zeroFields()
+	}
+	return log
+}
+
+// WithMessageKey creates a child logger that encodes log messages under the
+// supplied key instead of the encoder's configured message key, while the
+// parent logger is unaffected. It's useful for event-style logging, where the
+// message names an event:
+//
+//	events := zap.WithMessageKey(logger, "event")
+//	events.Info("user.signup", zap.String("plan", "pro"))
+//
+// The child keeps the parent's context, and its own children (including those
+// created with ZeroFields) keep the new key. Only encoders with a configurable
+// message key, like the JSON encoder, are affected. WithMessageKey supports
+// loggers created with New and Tee; other loggers are returned unchanged.
+func WithMessageKey(log Logger, key string) Logger {
+	if mk, ok := log.(interface {
+		withMessageKey(string) Logger
+	}); ok {
+		return mk.withMessageKey(key)
+	}
+	return log
+}
+
+// LogErr logs the message and error at the Error level, then returns the
+// error, so that guard clauses can log and return in one statement:
+//
+//	if err := f(); err != nil {
+//		return zap.LogErr(logger, err, "f failed")
+//	}
+//
+// The error is logged with the Error field, after any other fields. If err is
+// nil, LogErr logs nothing and returns nil. With loggers created by New or
+// NewChannelLogger, caller-capturing hooks (AddCaller, AddComponent, etc.)
+// report LogErr's caller rather than LogErr itself.
+func LogErr(log Logger, err error, msg string, fields ...Field) error {
+	if err == nil {
+		return nil
 	}
+	all := make([]Field, 0, len(fields)+1)
+	all = append(all, fields...)
+	all = append(all, Error(err))
+	if es, ok := log.(errorSkipper); ok {
+		es.errorSkip(1, msg, all)
+	} else {
+		log.Error(msg, all...)
+	}
+	return err
+}
+
+// An errorSkipper logs Error-level entries on behalf of helpers like LogErr
+// and Recover. The helper passes the number of its own frames that
+// caller-capturing hooks should skip, in addition to errorSkip itself, which
+// stands in for the Error method.
+type errorSkipper interface {
+	errorSkip(skip int, msg string, fields []Field)
 }
 
 func (log *logger) With(fields ...Field) Logger {
+	if log.DeferFields {
+		context := make([]Field, 0, len(log.context)+len(fields))
+		context = append(context, log.context...)
+		context = append(context, fields...)
+		return &logger{
+			Meta:    log.Meta,
+			context: context,
+			base:    log.base,
+		}
+	}
 	clone := &logger{
 		Meta: log.Meta.Clone(),
+		base: log.base,
+	}
+	clone.Meta.addFields(fields)
+	return clone
+}
+
+func (log *logger) withMessageKey(key string) Logger {
+	clone := &logger{
+		Meta:    log.Meta.Clone(),
+		context: log.context,
+		base:    log.base.Clone(),
+	}
+	setMessageKey(clone.Encoder, key)
+	setMessageKey(clone.base, key)
+	if clone.DebugEncoder != nil {
+		clone.debugBase = clone.debugBase.Clone()
+		setMessageKey(clone.DebugEncoder, key)
+		setMessageKey(clone.debugBase, key)
 	}
-	addFields(clone.Encoder, fields)
 	return clone
 }
 
+func (log *logger) zeroFields() Logger {
+	m := log.Meta
+	m.Encoder = log.base.Clone()
+	if m.DebugEncoder != nil {
+		m.DebugEncoder = m.debugBase.Clone()
+	}
+	return &logger{
+		Meta: m,
+		base: log.base,
+	}
+}
+
 func (log *logger) Check(lvl Level, msg string) *CheckedMessage {
 	return log.Meta.Check(log, lvl, msg)
 }
 
 func (log *logger) Log(lvl Level, msg string, fields ...Field) {
-	log.log(lvl, msg, fields)
+	log.log(lvl, msg, fields, 0)
 }
 
 func (log *logger) Debug(msg string, fields ...Field) {
-	log.log(DebugLevel, msg, fields)
+	log.log(DebugLevel, msg, fields, 0)
 }
 
 func (log *logger) Info(msg string, fields ...Field) {
-	log.log(InfoLevel, msg, fields)
+	log.log(InfoLevel, msg, fields, 0)
 }
 
 func (log *logger) Warn(msg string, fields ...Field) {
-	log.log(WarnLevel, msg, fields)
+	log.log(WarnLevel, msg, fields, 0)
 }
 
 func (log *logger) Error(msg string, fields ...Field) {
-	log.log(ErrorLevel, msg, fields)
+	log.log(ErrorLevel, msg, fields, 0)
+}
+
+func (log *logger) errorSkip(skip int, msg string, fields []Field) {
+	log.log(ErrorLevel, msg, fields, skip)
 }
 
 func (log *logger) Panic(msg string, fields ...Field) {
-	log.log(PanicLevel, msg, fields)
+	log.log(PanicLevel, msg, fields, 0)
 	panic(msg)
 }
 
 func (log *logger) Fatal(msg string, fields ...Field) {
-	log.log(FatalLevel, msg, fields)
+	log.log(FatalLevel, msg, fields, 0)
 	_exit(1)
 }
 
@@ -123,29 +271,82 @@ func (log *logger) DFatal(msg string, fields ...Field) {
 	log.Error(msg, fields...)
 }
 
-func (log *logger) log(lvl Level, msg string, fields []Field) {
+func (log *logger) log(lvl Level, msg string, fields []Field, callerSkip int) {
 	if !log.Meta.Enabled(lvl) {
 		return
 	}
 
 	temp := log.Encoder.Clone()
-	addFields(temp, fields)
+	if log.DeferFields {
+		fields = MergeFields(log.context, fields)
+	}
+	// Fields are encoded by both encoders if a DebugEncoder is configured.
+	var kv KeyValue = temp
+	var debugTemp Encoder
+	if log.DebugEncoder != nil {
+		debugTemp = log.DebugEncoder.Clone()
+		kv = teeKeyValue{temp, debugTemp}
+	}
 
-	entry := newEntry(lvl, msg, temp)
+	// Run the hooks in the sequence documented on Hook. They're run inline,
+	// rather than in a helper, so that caller-capturing hooks skip a
+	// predictable number of frames.
+	entry := newEntry(lvl, msg, kv)
+	entry.callerSkip = callerSkip
+	for _, hook := range log.FieldHooks {
+		if err := hook(entry, fields); err == ErrDropEntry {
+			freeEntry(entry, temp, debugTemp)
+			return
+		} else if err != nil {
+			log.InternalError("hook", err)
+		}
+	}
+	addFields(kv, fields)
 	for _, hook := range log.Hooks {
-		if err := hook(entry); err != nil {
+		if err := hook(entry); err == ErrDropEntry {
+			freeEntry(entry, temp, debugTemp)
+			return
+		} else if err != nil {
 			log.InternalError("hook", err)
 		}
 	}
 
-	if err := temp.WriteEntry(log.Output, entry.Message, entry.Level, entry.Time); err != nil {
+	out := log.Output
+	secondary := log.SecondaryOutput != nil && lvl >= log.SecondaryLevel
+	if secondary && log.SecondaryOnly {
+		out = log.SecondaryOutput
+	} else if secondary {
+		// Write the same encoded bytes to both outputs.
+		out = MultiWriteSyncer(log.Output, log.SecondaryOutput)
+	}
+	var sink io.Writer = out
+	if log.ByteLimiter != nil {
+		sink = limitedEntryWriter{log.ByteLimiter, entry.Level, out}
+	}
+	if err := temp.WriteEntry(sink, entry.Message, entry.Level, entry.Time); err != nil && err != errEntryDropped {
 		log.InternalError("encoder", err)
 	}
-	temp.Free()
-	entry.free()
+	if debugTemp != nil {
+		if err := debugTemp.WriteEntry(log.DebugOutput, entry.Message, entry.Level, entry.Time); err != nil {
+			log.InternalError("debug encoder", err)
+		}
+	}
+	freeEntry(entry, temp, debugTemp)
 
-	if lvl > ErrorLevel {
-		// Sync on Panic and Fatal, since they may crash the program.
-		log.Output.Sync()
+	if lvl >= log.SyncLevel {
+		// By default, sync on Panic and Fatal, since they may crash the
+		// program.
+		out.Sync()
+		if log.DebugOutput != nil {
+			log.DebugOutput.Sync()
+		}
 	}
 }
+
+func freeEntry(entry *Entry, temp, debugTemp Encoder) {
+	temp.Free()
+	if debugTemp != nil {
+		debugTemp.Free()
+	}
+	entry.free()
+}
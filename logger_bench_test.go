@@ -53,6 +53,7 @@ func withBenchedLogger(b *testing.B, f func(zap.Logger)) {
 		zap.DebugLevel,
 		zap.DiscardOutput,
 	)
+	b.ReportAllocs()
 	b.ResetTimer()
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
@@ -21,9 +21,16 @@
 package zap
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
+	"runtime"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"testing"
@@ -31,6 +38,7 @@ import (
 	"github.com/uber-go/zap/spywrite"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func opts(opts ...Option) []Option {
@@ -161,6 +169,73 @@ func TestJSONLoggerInitialFields(t *testing.T) {
 	})
 }
 
+func TestReplaceExit(t *testing.T) {
+	var codes []int
+	restore := ReplaceExit(func(code int) { codes = append(codes, code) })
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		logger.Fatal("fatal")
+		assert.Equal(t, `{"level":"fatal","msg":"fatal"}`, buf.Stripped(), "Expected Fatal to log before exiting.")
+	})
+	restore()
+	assert.Equal(t, []int{1}, codes, "Expected the replacement to be called with the exit code.")
+	assert.Equal(t, reflect.ValueOf(os.Exit).Pointer(), reflect.ValueOf(_exit).Pointer(), "Expected restore to reinstate os.Exit.")
+}
+
+func TestJSONLoggerCustomLevel(t *testing.T) {
+	withCustomLevels(t, func() {
+		notice := FatalLevel + 1
+		require.NoError(t, RegisterLevel(notice, "notice"), "Unexpected error registering a custom level.")
+		withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+			logger.Log(notice, "")
+			assert.Equal(t, `{"level":"notice","msg":""}`, buf.Stripped(), "Expected the registered name in JSON output.")
+		})
+	})
+}
+
+func TestJSONLoggerAddBuildInfo(t *testing.T) {
+	defer func() {
+		_readBuildInfo = debug.ReadBuildInfo
+		_goVersion = runtime.Version
+	}()
+	_goVersion = func() string { return "go1.7" }
+
+	tests := []struct {
+		info     *debug.BuildInfo
+		ok       bool
+		expected string
+	}{
+		{nil, false, `{"level":"info","msg":"","build":{"goVersion":"go1.7"}}`},
+		{&debug.BuildInfo{}, true, `{"level":"info","msg":"","build":{"goVersion":"go1.7"}}`},
+		{
+			&debug.BuildInfo{GoVersion: "go1.20", Main: debug.Module{Version: "(devel)"}},
+			true,
+			`{"level":"info","msg":"","build":{"version":"(devel)","goVersion":"go1.20"}}`,
+		},
+		{
+			&debug.BuildInfo{
+				GoVersion: "go1.20",
+				Main:      debug.Module{Version: "v1.2.3"},
+				Settings: []debug.BuildSetting{
+					{Key: "vcs", Value: "git"},
+					{Key: "vcs.revision", Value: "abc123"},
+					{Key: "vcs.time", Value: "2016-01-01T00:00:00Z"},
+				},
+			},
+			true,
+			`{"level":"info","msg":"","build":{"version":"v1.2.3","revision":"abc123","time":"2016-01-01T00:00:00Z","goVersion":"go1.20"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		info, ok := tt.info, tt.ok
+		_readBuildInfo = func() (*debug.BuildInfo, bool) { return info, ok }
+		withJSONLogger(t, opts(AddBuildInfo()), func(logger Logger, buf *testBuffer) {
+			logger.Info("")
+			assert.Equal(t, tt.expected, buf.Stripped(), "Unexpected build info.")
+		})
+	}
+}
+
 func TestJSONLoggerWith(t *testing.T) {
 	fieldOpts := opts(Fields(Int("foo", 42)))
 	withJSONLogger(t, fieldOpts, func(logger Logger, buf *testBuffer) {
@@ -177,6 +252,167 @@ func TestJSONLoggerWith(t *testing.T) {
 	})
 }
 
+func TestJSONLoggerZeroFields(t *testing.T) {
+	for _, deferred := range []bool{false, true} {
+		fieldOpts := opts(Fields(Int("foo", 42)), WarnLevel)
+		if deferred {
+			fieldOpts = append(fieldOpts, DeferFields())
+		}
+		withJSONLogger(t, fieldOpts, func(logger Logger, buf *testBuffer) {
+			child := logger.With(String("one", "two"))
+			fresh := ZeroFields(child)
+			fresh.Info("dropped")
+			fresh.With(String("three", "four")).Warn("fresh")
+			child.Warn("child")
+			assert.Equal(t, []string{
+				`{"level":"warn","msg":"fresh","three":"four"}`,
+				`{"level":"warn","msg":"child","foo":42,"one":"two"}`,
+			}, buf.Lines(), "Unexpected output from logger without fields (DeferFields: %v).", deferred)
+		})
+	}
+}
+
+func TestZeroFieldsTee(t *testing.T) {
+	withJSONLogger(t, opts(Fields(Int("foo", 42))), func(logger Logger, buf *testBuffer) {
+		ZeroFields(Tee(logger, logger.With(String("one", "two")))).Info("")
+		assert.Equal(t, []string{
+			`{"level":"info","msg":""}`,
+			`{"level":"info","msg":""}`,
+		}, buf.Lines(), "Expected all loggers in the Tee to drop their fields.")
+	})
+}
+
+func TestZeroFieldsUnsupported(t *testing.T) {
+	log := WithLazy(New(NullEncoder()))
+	assert.Equal(t, log, ZeroFields(log), "Expected unsupported loggers to be returned unchanged.")
+}
+
+func TestJSONLoggerDeferFieldsMerges(t *testing.T) {
+	withJSONLogger(t, opts(DeferFields()), func(logger Logger, buf *testBuffer) {
+		logger.With(String("user", "alice"), Int("n", 1)).With(String("user", "bob")).Info("merged", Int("n", 2))
+		assert.Equal(t, `{"level":"info","msg":"merged","user":"bob","n":2}`, buf.Stripped(), "Expected later fields to replace earlier ones.")
+	})
+}
+
+func TestJSONLoggerDeferFieldsMergesWithoutContext(t *testing.T) {
+	withJSONLogger(t, opts(DeferFields()), func(logger Logger, buf *testBuffer) {
+		logger.Info("merged", String("user", "alice"), String("user", "bob"))
+		assert.Equal(t, `{"level":"info","msg":"merged","user":"bob"}`, buf.Stripped(), "Expected duplicate keys to be merged without any context.")
+	})
+}
+
+func TestJSONLoggerWithMarshalerContext(t *testing.T) {
+	for _, deferred := range []bool{false, true} {
+		var fieldOpts []Option
+		if deferred {
+			fieldOpts = opts(DeferFields())
+		}
+		withJSONLogger(t, fieldOpts, func(logger Logger, buf *testBuffer) {
+			calls := 0
+			request := LogMarshalerFunc(func(kv KeyValue) error {
+				calls++
+				kv.AddString("id", "abc")
+				return kv.AddMarshaler("user", fakeUser{"phil"})
+			})
+			child := logger.With(Marshaler("request", request))
+			child.Info("received")
+			child.With(Int("attempt", 2)).Info("retried")
+			assert.Equal(t, []string{
+				`{"level":"info","msg":"received","request":{"id":"abc","user":{"name":"phil"}}}`,
+				`{"level":"info","msg":"retried","request":{"id":"abc","user":{"name":"phil"}},"attempt":2}`,
+			}, buf.Lines(), "Expected nested context in every entry.")
+			if deferred {
+				assert.Equal(t, 2, calls, "Expected deferred context to be marshaled for each entry.")
+			} else {
+				assert.Equal(t, 1, calls, "Expected context to be marshaled once, by With.")
+			}
+		})
+	}
+}
+
+func TestJSONLoggerWithMessageKey(t *testing.T) {
+	for _, deferred := range []bool{false, true} {
+		fieldOpts := opts(Fields(Int("foo", 42)))
+		if deferred {
+			fieldOpts = append(fieldOpts, DeferFields())
+		}
+		withJSONLogger(t, fieldOpts, func(logger Logger, buf *testBuffer) {
+			child := logger.With(String("one", "two"))
+			events := WithMessageKey(child, "event")
+			events.Info("signup")
+			events.With(String("three", "four")).Info("login")
+			ZeroFields(events).Info("logout")
+			child.Info("parent")
+			assert.Equal(t, []string{
+				`{"level":"info","event":"signup","foo":42,"one":"two"}`,
+				`{"level":"info","event":"login","foo":42,"one":"two","three":"four"}`,
+				`{"level":"info","event":"logout"}`,
+				`{"level":"info","msg":"parent","foo":42,"one":"two"}`,
+			}, buf.Lines(), "Unexpected output with a custom message key (DeferFields: %v).", deferred)
+		})
+	}
+}
+
+func TestWithMessageKeyTee(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		WithMessageKey(Tee(logger, logger), "event").Info("signup")
+		assert.Equal(t, []string{
+			`{"level":"info","event":"signup"}`,
+			`{"level":"info","event":"signup"}`,
+		}, buf.Lines(), "Expected all loggers in the Tee to use the new message key.")
+	})
+}
+
+func TestWithMessageKeyUnsupported(t *testing.T) {
+	log := WithLazy(New(NullEncoder()))
+	assert.Equal(t, log, WithMessageKey(log, "event"), "Expected unsupported loggers to be returned unchanged.")
+}
+
+// dedupingEncoder keeps only the last value for each key, which is only
+// possible if it sees all the fields at once.
+type dedupingEncoder struct {
+	Encoder
+}
+
+func (enc dedupingEncoder) AddFields(fields []Field) {
+	last := make(map[string]int, len(fields))
+	for i, f := range fields {
+		last[f.key] = i
+	}
+	for i, f := range fields {
+		if last[f.key] == i {
+			f.AddTo(enc.Encoder)
+		}
+	}
+}
+
+func (enc dedupingEncoder) Clone() Encoder {
+	return dedupingEncoder{enc.Encoder.Clone()}
+}
+
+func TestJSONLoggerDeferFields(t *testing.T) {
+	withJSONLogger(t, opts(DeferFields(), Fields(Int("foo", 42))), func(logger Logger, buf *testBuffer) {
+		parent := logger.With(String("one", "two"))
+		parent.With(String("three", "four")).Debug("", Int("five", 5))
+		parent.Debug("")
+		logger.Debug("")
+		assert.Equal(t, []string{
+			`{"level":"debug","msg":"","foo":42,"one":"two","three":"four","five":5}`,
+			`{"level":"debug","msg":"","foo":42,"one":"two"}`,
+			`{"level":"debug","msg":"","foo":42}`,
+		}, buf.Lines(), "Unexpected output with deferred fields.")
+	})
+}
+
+func TestLoggerFieldsAdder(t *testing.T) {
+	buf := &testBuffer{}
+	enc := dedupingEncoder{NewJSONEncoder(NoTime())}
+	logger := New(enc, DeferFields(), Output(buf))
+	logger.With(String("user", "jane"), Int("n", 1)).Info("", Int("n", 2), Nest("nested", Int("n", 3), Int("n", 4)))
+	assert.Equal(t, `{"level":"info","msg":"","user":"jane","n":2,"nested":{"n":3,"n":4}}`, buf.Stripped(),
+		"Expected encoder to see context and log-site fields at once.")
+}
+
 func TestJSONLoggerLog(t *testing.T) {
 	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
 		logger.Log(DebugLevel, "foo")
@@ -350,6 +586,22 @@ func TestJSONLoggerDFatal(t *testing.T) {
 	})
 }
 
+func TestJSONLoggerDevelopmentMode(t *testing.T) {
+	stub := stubExit()
+	defer stub.Unstub()
+
+	withJSONLogger(t, opts(Development(), DevelopmentMode(false)), func(logger Logger, buf *testBuffer) {
+		logger.DFatal("foo")
+		assert.Equal(t, `{"level":"error","msg":"foo"}`, buf.Stripped(), "Expected DevelopmentMode(false) to override Development.")
+		stub.AssertNoExit(t)
+	})
+	withJSONLogger(t, opts(DevelopmentMode(true)), func(logger Logger, buf *testBuffer) {
+		logger.DFatal("foo")
+		assert.Equal(t, `{"level":"fatal","msg":"foo"}`, buf.Stripped(), "Expected DevelopmentMode(true) to enable development mode.")
+		stub.AssertStatus(t, 1)
+	})
+}
+
 func TestJSONLoggerNoOpsDisabledLevels(t *testing.T) {
 	withJSONLogger(t, opts(WarnLevel), func(logger Logger, buf *testBuffer) {
 		logger.Info("silence!")
@@ -384,6 +636,105 @@ func TestJSONLoggerSyncsOutput(t *testing.T) {
 	assert.True(t, sink.Called(), "Expected logging at panic level to Sync underlying WriteSyncer.")
 }
 
+func TestJSONLoggerSyncsAtLevelBoundary(t *testing.T) {
+	buf := &testBuffer{}
+	sink := &spywrite.WriteSyncer{Writer: buf}
+	logger := New(newJSONEncoder(NoTime()), DebugLevel, Output(sink))
+
+	for _, tt := range []struct {
+		lvl        Level
+		shouldSync bool
+	}{
+		{DebugLevel, false},
+		{InfoLevel, false},
+		{WarnLevel, false},
+		{ErrorLevel, false},
+		{PanicLevel, true},
+		{FatalLevel, true},
+	} {
+		buf.Reset()
+		sink.Reset()
+		// Log doesn't panic or exit, so it exercises the sync behavior directly.
+		logger.Log(tt.lvl, "foo")
+		assert.NotEmpty(t, buf.String(), "Expected logging at %v to reach the output.", tt.lvl)
+		assert.Equal(t, tt.shouldSync, sink.Called(), "Unexpected sync behavior logging at %v.", tt.lvl)
+	}
+}
+
+func TestUnsafeOutput(t *testing.T) {
+	buf := &testBuffer{}
+	unsafe := New(newJSONEncoder(NoTime()), UnsafeOutput(buf)).(*logger)
+	assert.Equal(t, buf, unsafe.Output, "Expected UnsafeOutput not to wrap the WriteSyncer.")
+
+	unsafe.Info("foo")
+	assert.Equal(t, `{"level":"info","msg":"foo"}`, buf.Stripped(), "Unexpected output.")
+
+	locked := New(newJSONEncoder(NoTime()), Output(buf)).(*logger)
+	_, ok := locked.Output.(*lockedWriteSyncer)
+	assert.True(t, ok, "Expected Output to wrap the WriteSyncer.")
+}
+
+func TestJSONLoggerSecondaryOutput(t *testing.T) {
+	errBuf := &testBuffer{}
+	errSink := &spywrite.WriteSyncer{Writer: errBuf}
+	withJSONLogger(t, opts(SecondaryOutput(errSink, ErrorLevel)), func(logger Logger, buf *testBuffer) {
+		logger.Warn("warn")
+		logger.Error("error", Int("n", 1))
+		logger.Log(PanicLevel, "panic")
+		assert.Equal(t, []string{
+			`{"level":"warn","msg":"warn"}`,
+			`{"level":"error","msg":"error","n":1}`,
+			`{"level":"panic","msg":"panic"}`,
+		}, buf.Lines(), "Unexpected output in primary output.")
+		assert.Equal(t, []string{
+			`{"level":"error","msg":"error","n":1}`,
+			`{"level":"panic","msg":"panic"}`,
+		}, errBuf.Lines(), "Expected only error-level entries in secondary output.")
+		assert.True(t, errSink.Called(), "Expected Panic-level entries to sync the secondary output.")
+	})
+}
+
+func TestJSONLoggerSecondaryOutputFailure(t *testing.T) {
+	errBuf := &testBuffer{}
+	logger := New(
+		newJSONEncoder(NoTime()),
+		Output(&testBuffer{}),
+		ErrorOutput(errBuf),
+		SecondaryOutput(AddSync(spywrite.FailWriter{}), ErrorLevel),
+	)
+	logger.Error("foo")
+	assert.Contains(t, errBuf.String(), "encoder error: failed", "Expected secondary write failures to be reported.")
+}
+
+func TestJSONLoggerSplitOutput(t *testing.T) {
+	errBuf := &testBuffer{}
+	errSink := &spywrite.WriteSyncer{Writer: errBuf}
+	withJSONLogger(t, opts(SplitOutput(errSink, ErrorLevel)), func(logger Logger, buf *testBuffer) {
+		logger.Info("info")
+		logger.Warn("warn")
+		logger.Error("error", Int("n", 1))
+		logger.Log(PanicLevel, "panic")
+		assert.Equal(t, []string{
+			`{"level":"info","msg":"info"}`,
+			`{"level":"warn","msg":"warn"}`,
+		}, buf.Lines(), "Expected only entries below the split level in primary output.")
+		assert.Equal(t, []string{
+			`{"level":"error","msg":"error","n":1}`,
+			`{"level":"panic","msg":"panic"}`,
+		}, errBuf.Lines(), "Expected only error-level entries in split output.")
+		assert.True(t, errSink.Called(), "Expected Panic-level entries to sync the split output.")
+	})
+}
+
+func TestJSONLoggerSplitOutputOverridesSecondary(t *testing.T) {
+	errBuf := &testBuffer{}
+	withJSONLogger(t, opts(SplitOutput(errBuf, ErrorLevel), SecondaryOutput(errBuf, ErrorLevel)), func(logger Logger, buf *testBuffer) {
+		logger.Error("error")
+		assert.Equal(t, `{"level":"error","msg":"error"}`, buf.Stripped(), "Expected the last option to copy rather than split entries.")
+		assert.Equal(t, `{"level":"error","msg":"error"}`, errBuf.Stripped(), "Unexpected secondary output.")
+	})
+}
+
 func TestLoggerConcurrent(t *testing.T) {
 	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
 		child := logger.With(String("foo", "bar"))
@@ -415,3 +766,100 @@ func runConcurrently(goroutines, iterations int, wg *sync.WaitGroup, f func()) {
 		}()
 	}
 }
+
+func TestJSONLoggerDebugOutput(t *testing.T) {
+	defer stubNow(0)()
+	debug := &testBuffer{}
+	hook := Hook(func(e *Entry) error {
+		e.Fields().AddString("hook", "ran")
+		return nil
+	})
+	withJSONLogger(t, opts(
+		Fields(Int("before", 1)),
+		DebugOutput(NewTextEncoder(TextNoTime()), debug),
+		Fields(Int("after", 2)),
+		hook,
+	), func(logger Logger, buf *testBuffer) {
+		logger.With(String("user", "alice")).Info("hello", Bool("ok", true))
+		ZeroFields(logger).Warn("zeroed")
+
+		assert.Equal(t, []string{
+			`{"level":"info","msg":"hello","before":1,"after":2,"user":"alice","ok":true,"hook":"ran"}`,
+			`{"level":"warn","msg":"zeroed","hook":"ran"}`,
+		}, buf.Lines(), "Unexpected output from the primary encoder.")
+		assert.Equal(t, []string{
+			`[I] hello before=1 after=2 user=alice ok=true hook=ran`,
+			`[W] zeroed hook=ran`,
+		}, debug.Lines(), "Unexpected output from the debug encoder.")
+	})
+}
+
+func TestJSONLoggerDebugOutputSync(t *testing.T) {
+	debug := &spywrite.WriteSyncer{Writer: &bytes.Buffer{}}
+	logger := New(NewJSONEncoder(), DebugOutput(NewJSONEncoder(), debug), Output(&testBuffer{}))
+	assert.Panics(t, func() { logger.Panic("boom") }, "Expected Panic to panic.")
+	assert.True(t, debug.Called(), "Expected Panic to sync the debug output.")
+}
+
+func TestLogErr(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		err := errors.New("fail")
+		assert.Equal(t, err, LogErr(logger, err, "failed", Int("n", 1)), "Expected LogErr to return the error.")
+		assert.Nil(t, LogErr(logger, nil, "not logged"), "Expected LogErr to return nil errors.")
+		assert.Equal(t, []string{`{"level":"error","msg":"failed","n":1,"error":"fail"}`}, buf.Lines(), "Unexpected output from LogErr.")
+	})
+}
+
+func TestLogErrCaller(t *testing.T) {
+	buf := &testBuffer{}
+	logger := New(NewJSONEncoder(NoTime()), Output(buf), AddCaller())
+	channel, entries := NewChannelLogger(1, AddCaller())
+
+	_, _, line, _ := runtime.Caller(0)
+	LogErr(logger, errors.New("fail"), "failed")
+	LogErr(channel, errors.New("fail"), "failed")
+
+	expected := fmt.Sprintf("logger_test.go:%d: failed", line+1)
+	assert.Contains(t, buf.Stripped(), `"msg":"`+expected+`"`, "Expected the caller of LogErr.")
+	require.Equal(t, 1, len(entries), "Expected one buffered entry.")
+	e := <-entries
+	assert.Equal(t, fmt.Sprintf("logger_test.go:%d: failed", line+2), e.Message, "Expected the caller of LogErr.")
+}
+
+func TestJSONLoggerAtomicLinesOverPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err, "Failed to create pipe.")
+	defer r.Close()
+
+	logger := New(NewJSONEncoder(), Output(w), SyncLevel(DebugLevel))
+	// Entries larger than the pipe buffer, to catch any partial writes.
+	payload := strings.Repeat("x", 64*1024)
+	const goroutines, perGoroutine = 4, 10
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				logger.Info("relay", Int("goroutine", i), String("payload", payload))
+			}
+		}(i)
+	}
+	go func() {
+		wg.Wait()
+		w.Close()
+	}()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 128*1024), 128*1024)
+	lines := 0
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry), "Expected every line to be a complete JSON entry.")
+		assert.Equal(t, payload, entry["payload"], "Unexpected payload.")
+		lines++
+	}
+	require.NoError(t, scanner.Err(), "Unexpected error reading pipe.")
+	assert.Equal(t, goroutines*perGoroutine, lines, "Unexpected number of lines.")
+}
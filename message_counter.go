@@ -0,0 +1,220 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// A MessageCount is the number of times a message was logged.
+type MessageCount struct {
+	Msg   string
+	Count uint64
+}
+
+// A MessageCounter is a Logger that counts how often each message is logged,
+// which helps with capacity planning: it shows which statements dominate log
+// volume without parsing the output. Only entries that the underlying logger
+// accepts are counted, so entries at disabled levels or dropped by a sampler
+// aren't. Child loggers created with With share their parent's counts.
+//
+// To bound memory use when messages have high cardinality (e.g., because
+// they include request IDs), the counter tracks at most a fixed number of
+// distinct messages. Messages first seen after the limit is reached are
+// tallied together, and reported by Untracked.
+type MessageCounter struct {
+	Logger
+
+	counts *messageCounts
+}
+
+type messageCounts struct {
+	sync.RWMutex
+
+	max       int
+	counts    map[string]*uint64
+	untracked uint64
+}
+
+// CountMessages wraps a logger with a MessageCounter that tracks up to max
+// distinct messages.
+func CountMessages(log Logger, max int) *MessageCounter {
+	return &MessageCounter{
+		Logger: log,
+		counts: &messageCounts{
+			max:    max,
+			counts: make(map[string]*uint64),
+		},
+	}
+}
+
+// TopMessages returns the n most frequently logged messages, most frequent
+// first. Messages with equal counts are sorted alphabetically.
+func (mc *MessageCounter) TopMessages(n int) []MessageCount {
+	mc.counts.RLock()
+	top := make([]MessageCount, 0, len(mc.counts.counts))
+	for msg, count := range mc.counts.counts {
+		top = append(top, MessageCount{Msg: msg, Count: atomic.LoadUint64(count)})
+	}
+	mc.counts.RUnlock()
+
+	sort.Sort(byCount(top))
+	if n >= 0 && n < len(top) {
+		top = top[:n]
+	}
+	return top
+}
+
+// Untracked returns the number of entries whose messages weren't tracked
+// individually because the limit on distinct messages had been reached.
+func (mc *MessageCounter) Untracked() uint64 {
+	return atomic.LoadUint64(&mc.counts.untracked)
+}
+
+// With creates a child logger that shares the parent's counts.
+func (mc *MessageCounter) With(fields ...Field) Logger {
+	return &MessageCounter{
+		Logger: mc.Logger.With(fields...),
+		counts: mc.counts,
+	}
+}
+
+// Check returns a CheckedMessage that counts the message when it's written.
+func (mc *MessageCounter) Check(lvl Level, msg string) *CheckedMessage {
+	switch lvl {
+	case PanicLevel, FatalLevel:
+		// Make sure that Write calls our Panic and Fatal methods.
+		return NewCheckedMessage(mc, lvl, msg)
+	}
+	return wrapCheckedMessage(mc, mc.Logger.Check(lvl, msg), func(cm *CheckedMessage, fields []Field) {
+		mc.count(msg)
+		cm.Write(fields...)
+	})
+}
+
+// Log counts and logs a message at the specified level.
+func (mc *MessageCounter) Log(lvl Level, msg string, fields ...Field) {
+	switch lvl {
+	case PanicLevel, FatalLevel:
+		mc.countIfEnabled(lvl, msg)
+		mc.Logger.Log(lvl, msg, fields...)
+	default:
+		mc.write(lvl, msg, fields)
+	}
+}
+
+// Debug counts and logs a message at the Debug level.
+func (mc *MessageCounter) Debug(msg string, fields ...Field) {
+	mc.write(DebugLevel, msg, fields)
+}
+
+// Info counts and logs a message at the Info level.
+func (mc *MessageCounter) Info(msg string, fields ...Field) {
+	mc.write(InfoLevel, msg, fields)
+}
+
+// Warn counts and logs a message at the Warn level.
+func (mc *MessageCounter) Warn(msg string, fields ...Field) {
+	mc.write(WarnLevel, msg, fields)
+}
+
+// Error counts and logs a message at the Error level.
+func (mc *MessageCounter) Error(msg string, fields ...Field) {
+	mc.write(ErrorLevel, msg, fields)
+}
+
+// Panic counts and logs a message at the Panic level, then panics.
+func (mc *MessageCounter) Panic(msg string, fields ...Field) {
+	mc.countIfEnabled(PanicLevel, msg)
+	mc.Logger.Panic(msg, fields...)
+}
+
+// Fatal counts and logs a message at the Fatal level, then exits.
+func (mc *MessageCounter) Fatal(msg string, fields ...Field) {
+	mc.countIfEnabled(FatalLevel, msg)
+	mc.Logger.Fatal(msg, fields...)
+}
+
+// DFatal counts the message and passes it to the underlying logger's DFatal.
+// Since the underlying logger decides the level, it's counted if the Error
+// level is enabled.
+func (mc *MessageCounter) DFatal(msg string, fields ...Field) {
+	mc.countIfEnabled(ErrorLevel, msg)
+	mc.Logger.DFatal(msg, fields...)
+}
+
+// Close closes the underlying logger. See Close for details.
+func (mc *MessageCounter) Close() error {
+	return Close(mc.Logger)
+}
+
+// write counts and logs the entry only if the underlying logger accepts it,
+// so that entries at disabled levels (or dropped by a sampler) aren't counted.
+func (mc *MessageCounter) write(lvl Level, msg string, fields []Field) {
+	if cm := mc.Logger.Check(lvl, msg); cm.OK() {
+		mc.count(msg)
+		cm.Write(fields...)
+	}
+}
+
+func (mc *MessageCounter) countIfEnabled(lvl Level, msg string) {
+	if enabled(mc.Logger, lvl) {
+		mc.count(msg)
+	}
+}
+
+func (mc *MessageCounter) count(msg string) {
+	c := mc.counts
+	c.RLock()
+	count, ok := c.counts[msg]
+	c.RUnlock()
+	if ok {
+		atomic.AddUint64(count, 1)
+		return
+	}
+
+	c.Lock()
+	count, ok = c.counts[msg]
+	if !ok {
+		if len(c.counts) >= c.max {
+			c.Unlock()
+			atomic.AddUint64(&c.untracked, 1)
+			return
+		}
+		count = new(uint64)
+		c.counts[msg] = count
+	}
+	c.Unlock()
+	atomic.AddUint64(count, 1)
+}
+
+type byCount []MessageCount
+
+func (mc byCount) Len() int      { return len(mc) }
+func (mc byCount) Swap(i, j int) { mc[i], mc[j] = mc[j], mc[i] }
+func (mc byCount) Less(i, j int) bool {
+	if mc[i].Count != mc[j].Count {
+		return mc[i].Count > mc[j].Count
+	}
+	return mc[i].Msg < mc[j].Msg
+}
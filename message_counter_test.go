@@ -0,0 +1,71 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageCounter(t *testing.T) {
+	withJSONLogger(t, opts(InfoLevel), func(logger Logger, buf *testBuffer) {
+		mc := CountMessages(logger, 10)
+		child := mc.With(Int("n", 1))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				child.Info("hot")
+			}()
+		}
+		wg.Wait()
+		mc.Warn("warm")
+		mc.Warn("warm")
+		mc.Check(ErrorLevel, "cool").Write()
+		mc.Debug("disabled")
+
+		assert.Equal(t, []MessageCount{
+			{Msg: "hot", Count: 10},
+			{Msg: "warm", Count: 2},
+			{Msg: "cool", Count: 1},
+		}, mc.TopMessages(-1), "Unexpected message counts.")
+		assert.Equal(t, []MessageCount{{Msg: "hot", Count: 10}}, mc.TopMessages(1), "Unexpected top message.")
+		assert.Equal(t, 13, len(buf.Lines()), "Expected all enabled entries to be logged.")
+	})
+}
+
+func TestMessageCounterBounded(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		mc := CountMessages(logger, 2)
+		for _, msg := range []string{"a", "b", "c", "a", "d", "c"} {
+			mc.Info(msg)
+		}
+		assert.Equal(t, []MessageCount{
+			{Msg: "a", Count: 2},
+			{Msg: "b", Count: 1},
+		}, mc.TopMessages(10), "Expected only the first messages to be tracked.")
+		assert.Equal(t, uint64(3), mc.Untracked(), "Unexpected number of untracked entries.")
+	})
+}
@@ -34,21 +34,46 @@ type Meta struct {
 	LevelEnabler
 
 	Development bool
+	DeferFields bool
 	Encoder     Encoder
 	Hooks       []Hook
+	FieldHooks  []FieldHook
 	Output      WriteSyncer
 	ErrorOutput WriteSyncer
+	// The logger syncs its output after writing entries at or above this
+	// level; see the SyncLevel option.
+	SyncLevel Level
+
+	// Optional; see the SecondaryOutput and SplitOutput options.
+	SecondaryOutput WriteSyncer
+	SecondaryLevel  Level
+	SecondaryOnly   bool
+
+	// Optional; see NewByteLimiter.
+	ByteLimiter *ByteLimiter
+
+	// Optional; see the DebugOutput option.
+	DebugEncoder Encoder
+	DebugOutput  WriteSyncer
+
+	// Fields supplied with the Fields option, so that a DebugEncoder
+	// configured later can encode them too.
+	initialFields []Field
+	// A copy of the DebugEncoder before any context was added, for
+	// ZeroFields.
+	debugBase Encoder
 }
 
 // MakeMeta returns a new meta struct with sensible defaults: logging at
-// InfoLevel, development mode off, and writing to standard error and standard
-// out.
+// InfoLevel, development mode off, writing to standard error and standard
+// out, and syncing after Panic- and Fatal-level entries.
 func MakeMeta(enc Encoder, options ...Option) Meta {
 	m := Meta{
 		Encoder:      enc,
 		Output:       newLockedWriteSyncer(os.Stdout),
 		ErrorOutput:  newLockedWriteSyncer(os.Stderr),
 		LevelEnabler: InfoLevel,
+		SyncLevel:    PanicLevel,
 	}
 	for _, opt := range options {
 		opt.apply(&m)
@@ -56,13 +81,25 @@ func MakeMeta(enc Encoder, options ...Option) Meta {
 	return m
 }
 
-// Clone creates a copy of the meta struct. It deep-copies the encoder, but not
+// Clone creates a copy of the meta struct. It deep-copies the encoders, but not
 // the hooks (since they rarely change).
 func (m Meta) Clone() Meta {
 	m.Encoder = m.Encoder.Clone()
+	if m.DebugEncoder != nil {
+		m.DebugEncoder = m.DebugEncoder.Clone()
+	}
 	return m
 }
 
+// addFields adds fields to the encoder and, if one is configured, to the
+// DebugEncoder.
+func (m Meta) addFields(fields []Field) {
+	addFields(m.Encoder, fields)
+	if m.DebugEncoder != nil {
+		addFields(m.DebugEncoder, fields)
+	}
+}
+
 // Check returns a CheckedMessage logging the given message is Enabled, nil
 // otherwise.
 func (m Meta) Check(log Logger, lvl Level, msg string) *CheckedMessage {
@@ -79,6 +116,39 @@ func (m Meta) Check(log Logger, lvl Level, msg string) *CheckedMessage {
 	return NewCheckedMessage(log, lvl, msg)
 }
 
+// RunHooks lets Logger implementations outside this package honor the
+// FieldHooks and Hooks options. It creates an Entry and runs the hooks in the
+// sequence documented on Hook, adding the fields to kv after the FieldHooks
+// and before the Hooks. Errors returned by the hooks are reported with
+// InternalError. It returns the entry as the hooks left it, or false if a
+// hook dropped it.
+//
+// So that caller-capturing hooks (AddCaller, AddComponent, etc.) report the
+// right frame, RunHooks must be called from the function that the leveled
+// methods (Info, Error, etc.) call, and not from a helper.
+func (m Meta) RunHooks(lvl Level, msg string, fields []Field, kv KeyValue) (Entry, bool) {
+	entry := newEntry(lvl, msg, kv)
+	defer entry.free()
+	// Skip RunHooks itself, which sits between the hooks and the logger.
+	entry.callerSkip = 1
+	for _, hook := range m.FieldHooks {
+		if err := hook(entry, fields); err == ErrDropEntry {
+			return Entry{}, false
+		} else if err != nil {
+			m.InternalError("hook", err)
+		}
+	}
+	addFields(kv, fields)
+	for _, hook := range m.Hooks {
+		if err := hook(entry); err == ErrDropEntry {
+			return Entry{}, false
+		} else if err != nil {
+			m.InternalError("hook", err)
+		}
+	}
+	return Entry{Level: entry.Level, Time: entry.Time, Message: entry.Message}, true
+}
+
 // InternalError prints an internal error message to the configured
 // ErrorOutput. This method should only be used to report internal logger
 // problems and should not be used to report user-caused problems.
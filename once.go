@@ -0,0 +1,181 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "sync"
+
+// Once constructs a field that sets the deduplication key used by loggers
+// wrapped with LogOnce. It's useful when the message varies but should still
+// only be logged once. The field itself isn't encoded.
+func Once(key string) Field {
+	return Field{fieldType: onceType, str: key}
+}
+
+// LogOnce wraps a logger so that each unique message is logged at most once,
+// which prevents one-time warnings (e.g., deprecation notices) from flooding
+// the logs when they're triggered in a loop. Messages are deduplicated by the
+// key set with the Once field (either at the log site or with With) or, if
+// there isn't one, by their text. Level and other fields are ignored.
+//
+// The returned logger and all its children share one set of seen keys, which
+// is retained for the lifetime of the logger; keep the number of distinct
+// keys bounded. Entries the underlying logger doesn't accept (e.g., at
+// disabled levels or dropped by a sampler) don't count, so such a message is
+// still logged once the underlying logger accepts it. Panic
+// and Fatal always panic and exit, even if the message is suppressed.
+func LogOnce(log Logger) Logger {
+	return &onceLogger{
+		log:  log,
+		seen: &seenKeys{keys: make(map[string]struct{})},
+	}
+}
+
+type seenKeys struct {
+	sync.Mutex
+	keys map[string]struct{}
+}
+
+// add records the key, reporting whether it's new.
+func (s *seenKeys) add(key string) bool {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.keys[key]; ok {
+		return false
+	}
+	s.keys[key] = struct{}{}
+	return true
+}
+
+type onceLogger struct {
+	log  Logger
+	seen *seenKeys
+
+	// The deduplication key from the logger's context, if any.
+	key    string
+	hasKey bool
+}
+
+func (o *onceLogger) With(fields ...Field) Logger {
+	clone := &onceLogger{
+		log:    o.log.With(fields...),
+		seen:   o.seen,
+		key:    o.key,
+		hasKey: o.hasKey,
+	}
+	if key, ok := findOnceKey(fields); ok {
+		clone.key, clone.hasKey = key, true
+	}
+	return clone
+}
+
+func (o *onceLogger) Check(lvl Level, msg string) *CheckedMessage {
+	switch lvl {
+	case PanicLevel, FatalLevel:
+		// Like Tee, make sure that Write calls our Panic and Fatal methods.
+		return NewCheckedMessage(o, lvl, msg)
+	}
+	// We can't check for duplicates until Write supplies the fields. Then,
+	// write through the underlying logger's CheckedMessage.
+	return wrapCheckedMessage(o, o.log.Check(lvl, msg), func(cm *CheckedMessage, fields []Field) {
+		if o.first(msg, fields) {
+			cm.Write(fields...)
+		}
+	})
+}
+
+func (o *onceLogger) Log(lvl Level, msg string, fields ...Field) {
+	switch lvl {
+	case PanicLevel, FatalLevel:
+		if enabled(o.log, lvl) && o.first(msg, fields) {
+			o.log.Log(lvl, msg, fields...)
+		}
+	default:
+		o.write(lvl, msg, fields)
+	}
+}
+
+func (o *onceLogger) Debug(msg string, fields ...Field) {
+	o.write(DebugLevel, msg, fields)
+}
+
+func (o *onceLogger) Info(msg string, fields ...Field) {
+	o.write(InfoLevel, msg, fields)
+}
+
+func (o *onceLogger) Warn(msg string, fields ...Field) {
+	o.write(WarnLevel, msg, fields)
+}
+
+func (o *onceLogger) Error(msg string, fields ...Field) {
+	o.write(ErrorLevel, msg, fields)
+}
+
+func (o *onceLogger) Panic(msg string, fields ...Field) {
+	if o.first(msg, fields) {
+		o.log.Log(PanicLevel, msg, fields...)
+	}
+	panic(msg)
+}
+
+func (o *onceLogger) Fatal(msg string, fields ...Field) {
+	if o.first(msg, fields) {
+		o.log.Log(FatalLevel, msg, fields...)
+	}
+	_exit(1)
+}
+
+func (o *onceLogger) DFatal(msg string, fields ...Field) {
+	// DFatal logs at Error or Fatal, so it's enabled if Error is.
+	if enabled(o.log, ErrorLevel) && o.first(msg, fields) {
+		o.log.DFatal(msg, fields...)
+	}
+}
+
+// write logs the entry if the underlying logger accepts it and the message
+// hasn't been logged before. Only accepted entries mark their key as seen, so
+// a message that's disabled or dropped by a sampler can still be logged later.
+func (o *onceLogger) write(lvl Level, msg string, fields []Field) {
+	if cm := o.log.Check(lvl, msg); cm.OK() && o.first(msg, fields) {
+		cm.Write(fields...)
+	}
+}
+
+// first reports whether this is the first time the message has been logged.
+func (o *onceLogger) first(msg string, fields []Field) bool {
+	key, ok := findOnceKey(fields)
+	if !ok {
+		key, ok = o.key, o.hasKey
+	}
+	if !ok {
+		key = msg
+	}
+	return o.seen.add(key)
+}
+
+// findOnceKey returns the key of the last Once field.
+func findOnceKey(fields []Field) (string, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].fieldType == onceType {
+			return fields[i].str, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogOnce(t *testing.T) {
+	inner, sink := spy.New(zap.DebugLevel)
+	log := zap.LogOnce(inner)
+
+	for i := 0; i < 3; i++ {
+		log.Warn("deprecated", zap.Int("i", i))
+		log.With(zap.Once("config")).Info("config is deprecated", zap.Int("i", i))
+		log.Info("changing message", zap.Int("i", i), zap.Once("changing"))
+	}
+	log.Check(zap.WarnLevel, "deprecated").Write()
+	log.Check(zap.WarnLevel, "checked").Write()
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.WarnLevel, Msg: "deprecated", Fields: []zap.Field{zap.Int("i", 0)}},
+		{Level: zap.InfoLevel, Msg: "config is deprecated", Fields: []zap.Field{zap.Once("config"), zap.Int("i", 0)}},
+		{Level: zap.InfoLevel, Msg: "changing message", Fields: []zap.Field{zap.Int("i", 0), zap.Once("changing")}},
+		{Level: zap.WarnLevel, Msg: "checked", Fields: []zap.Field{}},
+	}, sink.Logs(), "Expected each key to be logged once.")
+}
+
+func TestLogOncePanic(t *testing.T) {
+	inner, sink := spy.New(zap.DebugLevel)
+	log := zap.LogOnce(inner)
+
+	assert.Panics(t, func() { log.Panic("boom") }, "Expected Panic to panic.")
+	assert.Panics(t, func() { log.Panic("boom") }, "Expected Panic to panic even when suppressed.")
+	assert.Equal(t, 1, len(sink.Logs()), "Expected repeated panic message to be suppressed.")
+}
+
+func TestOnceFieldNotEncoded(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := zap.New(zap.NewJSONEncoder(zap.NoTime()), zap.Output(zap.AddSync(buf)))
+	log.Info("hello", zap.Once("key"))
+	assert.Equal(t, `{"level":"info","msg":"hello"}`+"\n", buf.String(), "Expected Once field to be omitted.")
+}
+
+func TestLogOnceIgnoresDisabledLevels(t *testing.T) {
+	lvl := zap.DynamicLevel()
+	lvl.SetLevel(zap.WarnLevel)
+	inner, sink := spy.New(lvl)
+	log := zap.LogOnce(inner)
+
+	log.Debug("deprecated")
+	log.Warn("deprecated")
+	log.Info("changed")
+	lvl.SetLevel(zap.DebugLevel)
+	log.Log(zap.InfoLevel, "changed")
+	log.Info("changed")
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.WarnLevel, Msg: "deprecated", Fields: []zap.Field{}},
+		{Level: zap.InfoLevel, Msg: "changed", Fields: []zap.Field{}},
+	}, sink.Logs(), "Expected disabled entries not to mark messages as seen.")
+}
@@ -20,6 +20,17 @@
 
 package zap
 
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// For tests.
+var (
+	_readBuildInfo = debug.ReadBuildInfo
+	_goVersion     = runtime.Version
+)
+
 // Option is used to set options for the logger.
 type Option interface {
 	apply(*Meta)
@@ -39,19 +50,80 @@ func (lvl AtomicLevel) apply(m *Meta) { m.LevelEnabler = lvl }
 // Fields sets the initial fields for the logger.
 func Fields(fields ...Field) Option {
 	return optionFunc(func(m *Meta) {
-		addFields(m.Encoder, fields)
+		m.addFields(fields)
+		m.initialFields = append(m.initialFields, fields...)
+	})
+}
+
+// AddBuildInfo adds a "build" object to the logger's initial fields, holding
+// the main module's version, the VCS revision and commit time it was built
+// from, and the Go version it was built with, which makes each entry
+// correlatable to a build. The build information is read once, when the
+// logger is constructed. Fields that aren't available (e.g., the VCS details
+// for binaries built with go run) are omitted; if the binary doesn't embed
+// build information at all, only the Go version is added.
+func AddBuildInfo() Option {
+	return optionFunc(func(m *Meta) {
+		Fields(Nest("build", buildInfoFields()...)).apply(m)
 	})
 }
 
+func buildInfoFields() []Field {
+	info, ok := _readBuildInfo()
+	if !ok || info == nil {
+		return []Field{String("goVersion", _goVersion())}
+	}
+	var fields []Field
+	if v := info.Main.Version; v != "" {
+		fields = append(fields, String("version", v))
+	}
+	for _, s := range info.Settings {
+		if s.Value == "" {
+			continue
+		}
+		switch s.Key {
+		case "vcs.revision":
+			fields = append(fields, String("revision", s.Value))
+		case "vcs.time":
+			fields = append(fields, String("time", s.Value))
+		}
+	}
+	goVersion := info.GoVersion
+	if goVersion == "" {
+		goVersion = _goVersion()
+	}
+	return append(fields, String("goVersion", goVersion))
+}
+
 // Output sets the destination for the logger's output. The supplied WriteSyncer
 // is automatically wrapped with a mutex, so it need not be safe for concurrent
 // use.
+//
+// Together with this package's encoders, which write each entry (including
+// its trailing newline) with a single call to Write, the mutex guarantees
+// that entries are written atomically with respect to each other: lines from
+// concurrent goroutines never interleave, and every entry is a complete line.
+// This makes the output safe to stream as newline-delimited JSON over a pipe.
+// Loggers that share a WriteSyncer should share a single logger (or children
+// created with With), since each call to Output adds a separate mutex. To
+// flush each entry as soon as it's written, add SyncLevel(DebugLevel).
 func Output(w WriteSyncer) Option {
 	return optionFunc(func(m *Meta) {
 		m.Output = newLockedWriteSyncer(w)
 	})
 }
 
+// UnsafeOutput is like Output, but it doesn't wrap the supplied WriteSyncer
+// with a mutex. It avoids the cost of locking when the WriteSyncer is already
+// safe for concurrent use, or when the logger is only used from a single
+// goroutine. The caller is responsible for making sure that concurrent writes
+// and syncs are safe; otherwise, output may be interleaved or corrupted.
+func UnsafeOutput(w WriteSyncer) Option {
+	return optionFunc(func(m *Meta) {
+		m.Output = w
+	})
+}
+
 // ErrorOutput sets the destination for errors generated by the logger. The
 // supplied WriteSyncer is automatically wrapped with a mutex, so it need not be
 // safe for concurrent use.
@@ -61,10 +133,95 @@ func ErrorOutput(w WriteSyncer) Option {
 	})
 }
 
+// SecondaryOutput additionally writes entries at or above the given level to
+// the supplied WriteSyncer (for example, to persist errors to a durable file).
+// Each entry is encoded only once and the same bytes are written to both
+// outputs, so it's cheaper than using Tee to achieve the same result. Like
+// Output, the WriteSyncer is automatically wrapped with a mutex.
+func SecondaryOutput(ws WriteSyncer, lvl Level) Option {
+	return optionFunc(func(m *Meta) {
+		m.SecondaryOutput = newLockedWriteSyncer(ws)
+		m.SecondaryLevel = lvl
+		m.SecondaryOnly = false
+	})
+}
+
+// SplitOutput writes entries at or above the given level to the supplied
+// WriteSyncer instead of the logger's Output. Following the Unix convention,
+// it's most often used to send errors to standard error and everything else
+// to standard out:
+//
+//	zap.New(enc, zap.Output(os.Stdout), zap.SplitOutput(os.Stderr, zap.ErrorLevel))
+//
+// Like SecondaryOutput, the WriteSyncer is automatically wrapped with a mutex.
+// The two options share their configuration, so only the last one applied
+// takes effect.
+func SplitOutput(ws WriteSyncer, lvl Level) Option {
+	return optionFunc(func(m *Meta) {
+		m.SecondaryOutput = newLockedWriteSyncer(ws)
+		m.SecondaryLevel = lvl
+		m.SecondaryOnly = true
+	})
+}
+
+// DebugOutput additionally writes every entry through a second encoder to the
+// supplied WriteSyncer: for example, compact JSON to the main output and a
+// verbose console format to a troubleshooting file. Unlike Tee, both outputs
+// share a single logger, so each entry is timestamped and passed through the
+// hooks only once, and fields added by hooks are encoded by both encoders.
+// Initial fields are encoded by both encoders regardless of the order in which
+// the options are supplied. Like Output, the WriteSyncer is automatically
+// wrapped with a mutex.
+//
+// Whenever the logger syncs its output (after Panic- and Fatal-level entries,
+// and on Close), it syncs the debug output too.
+func DebugOutput(enc Encoder, ws WriteSyncer) Option {
+	return optionFunc(func(m *Meta) {
+		m.debugBase = enc
+		m.DebugEncoder = enc.Clone()
+		m.DebugOutput = newLockedWriteSyncer(ws)
+		addFields(m.DebugEncoder, m.initialFields)
+	})
+}
+
+// SyncLevel makes the logger sync its output immediately after writing each
+// entry at or above the supplied level. By default, loggers only sync after
+// Panic- and Fatal-level entries, since they may crash the program. Paired
+// with a BufferedWriteSyncer, a lower level (e.g., ErrorLevel) writes
+// important entries durably while routine entries are batched.
+func SyncLevel(lvl Level) Option {
+	return optionFunc(func(m *Meta) {
+		m.SyncLevel = lvl
+	})
+}
+
 // Development puts the logger in development mode, which alters the behavior
-// of the DFatal method.
+// of the DFatal method. It's shorthand for DevelopmentMode(true).
 func Development() Option {
+	return DevelopmentMode(true)
+}
+
+// DevelopmentMode explicitly turns development mode on or off. Since later
+// options override earlier ones, it's useful for overriding presets in
+// config-driven setups.
+func DevelopmentMode(enabled bool) Option {
+	return optionFunc(func(m *Meta) {
+		m.Development = enabled
+	})
+}
+
+// DeferFields changes how the logger handles context added with With. By
+// default, With eagerly encodes fields into a copy of the logger's encoder.
+// With this option, child loggers instead store an ordered list of their
+// context fields and pass them to the encoder, along with the fields supplied
+// at the log site, each time they write an entry.
+//
+// Deferring fields makes logging somewhat slower, but it lets encoders that
+// implement FieldsAdder see every field in an entry at once (e.g., to reorder
+// them). Since the logger has all the fields on hand, it also resolves
+// duplicate keys, letting later fields replace earlier ones; see MergeFields.
+func DeferFields() Option {
 	return optionFunc(func(m *Meta) {
-		m.Development = true
+		m.DeferFields = true
 	})
 }
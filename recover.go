@@ -0,0 +1,81 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Recover logs panics in goroutines and handlers. It must be deferred
+// directly, since recover only stops a panic when called by a deferred
+// function:
+//
+//	defer zap.Recover(logger, false)
+//
+// If the surrounding function panics, Recover logs an Error-level entry with
+// the recovered value under the "panic" key and a stacktrace (taken where the
+// panic occurred). If the recovered value is an error, it's also logged with
+// the Error field, so any structured fields it carries are included. If
+// repanic is true, Recover then panics again with the same value, which
+// preserves the original crash behavior; otherwise, the panic is swallowed
+// and the surrounding function returns normally.
+//
+// With loggers created by New or NewChannelLogger, caller-capturing hooks
+// (AddCaller, AddComponent, etc.) report where the panic occurred rather than
+// Recover itself.
+func Recover(log Logger, repanic bool) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	fields := []Field{String("panic", fmt.Sprint(r))}
+	if err, ok := r.(error); ok {
+		fields = append(fields, Error(err))
+	}
+	fields = append(fields, Stack())
+	if es, ok := log.(errorSkipper); ok {
+		es.errorSkip(1+panicFrames(), "recovered from panic", fields)
+	} else {
+		log.Error("recovered from panic", fields...)
+	}
+	if repanic {
+		panic(r)
+	}
+}
+
+// panicFrames returns the number of runtime frames (runtime.gopanic and the
+// like) between Recover and the function that panicked.
+func panicFrames() int {
+	pcs := make([]uintptr, 16)
+	// Skip runtime.Callers, panicFrames, and Recover.
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	skip := 0
+	for {
+		frame, more := frames.Next()
+		if !more || !strings.HasPrefix(frame.Function, "runtime.") {
+			return skip
+		}
+		skip++
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecover(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		func() {
+			defer Recover(logger, false)
+			panic("boom")
+		}()
+		lines := buf.Lines()
+		require.Equal(t, 1, len(lines), "Expected one log entry.")
+		assert.Contains(t, lines[0], `{"level":"error","msg":"recovered from panic","panic":"boom","stacktrace":"`, "Unexpected log entry.")
+		assert.Contains(t, lines[0], "zap.TestRecover", "Expected stacktrace to include the panicking function.")
+	})
+}
+
+func TestRecoverCaller(t *testing.T) {
+	buf := &testBuffer{}
+	logger := New(NewJSONEncoder(NoTime()), Output(buf), AddCaller())
+
+	var explicit, implicit int
+	func() {
+		defer Recover(logger, false)
+		_, _, explicit, _ = runtime.Caller(0)
+		panic("boom")
+	}()
+	func() {
+		defer Recover(logger, false)
+		var m map[string]int
+		_, _, implicit, _ = runtime.Caller(0)
+		m["boom"]++
+	}()
+
+	lines := buf.Lines()
+	require.Equal(t, 2, len(lines), "Expected two log entries.")
+	assert.Contains(t, lines[0], fmt.Sprintf(`"msg":"recover_test.go:%d: recovered from panic"`, explicit+1), "Expected the line that called panic.")
+	assert.Contains(t, lines[1], fmt.Sprintf(`"msg":"recover_test.go:%d: recovered from panic"`, implicit+1), "Expected the line that caused a runtime panic.")
+}
+
+func TestRecoverRepanic(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		err := errors.New("fail")
+		assert.Panics(t, func() {
+			defer Recover(logger, true)
+			panic(err)
+		}, "Expected Recover to panic again.")
+		assert.Contains(t, buf.Stripped(), `"panic":"fail","error":"fail","stacktrace":`, "Expected errors to be logged with the Error field.")
+	})
+}
+
+func TestRecoverNoPanic(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		func() {
+			defer Recover(logger, true)
+		}()
+		assert.Empty(t, buf.String(), "Expected no output without a panic.")
+	})
+}
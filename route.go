@@ -0,0 +1,161 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "fmt"
+
+// RouteByField creates a Logger that dispatches each log call to one of
+// several loggers, based on the value of a string field. For example, routing
+// by a "tenant" field makes it easy to separate each tenant's logs.
+//
+// The router looks for the named field among the fields passed to the log
+// call, then among the fields added with With; if the field appears more than
+// once, the last value wins. Entries are written by the logger in routes whose
+// name matches the field's value. Entries without the field, and entries whose
+// value has no matching route, are written by the fallback logger.
+//
+// Calling With on the router calls With on all the routes and the fallback.
+// Like Tee, the router makes an exception for the Panic and Fatal methods:
+// it calls Log(PanicLevel, ...) or Log(FatalLevel, ...) on the chosen logger,
+// then panics or exits itself.
+func RouteByField(key string, routes map[string]Logger, fallback Logger) Logger {
+	copied := make(map[string]Logger, len(routes))
+	for name, log := range routes {
+		copied[name] = log
+	}
+	return &fieldRouter{
+		key:      key,
+		routes:   copied,
+		fallback: fallback,
+	}
+}
+
+type fieldRouter struct {
+	key      string
+	routes   map[string]Logger
+	fallback Logger
+
+	// The routing field's value from the logger's context, if any.
+	value    string
+	hasValue bool
+}
+
+func (r *fieldRouter) With(fields ...Field) Logger {
+	clone := &fieldRouter{
+		key:      r.key,
+		routes:   make(map[string]Logger, len(r.routes)),
+		fallback: r.fallback.With(fields...),
+		value:    r.value,
+		hasValue: r.hasValue,
+	}
+	for name, log := range r.routes {
+		clone.routes[name] = log.With(fields...)
+	}
+	if v, ok := r.find(fields); ok {
+		clone.value, clone.hasValue = v, true
+	}
+	return clone
+}
+
+func (r *fieldRouter) Check(lvl Level, msg string) *CheckedMessage {
+	switch lvl {
+	case PanicLevel, FatalLevel:
+		// Like Tee, make sure that Write calls our Panic and Fatal methods.
+		return NewCheckedMessage(r, lvl, msg)
+	}
+	// We don't know which route will handle the message until Write supplies
+	// the fields, so check whether any route's level allows it. Don't call
+	// Check on the routes: the chosen route decides again when it logs, and
+	// probing would spend (e.g.) a sampler's budget.
+	if enabled(r.fallback, lvl) {
+		return NewCheckedMessage(r, lvl, msg)
+	}
+	for _, log := range r.routes {
+		if enabled(log, lvl) {
+			return NewCheckedMessage(r, lvl, msg)
+		}
+	}
+	return nil
+}
+
+func (r *fieldRouter) Log(lvl Level, msg string, fields ...Field) {
+	r.route(fields).Log(lvl, msg, fields...)
+}
+
+func (r *fieldRouter) Debug(msg string, fields ...Field) {
+	r.route(fields).Debug(msg, fields...)
+}
+
+func (r *fieldRouter) Info(msg string, fields ...Field) {
+	r.route(fields).Info(msg, fields...)
+}
+
+func (r *fieldRouter) Warn(msg string, fields ...Field) {
+	r.route(fields).Warn(msg, fields...)
+}
+
+func (r *fieldRouter) Error(msg string, fields ...Field) {
+	r.route(fields).Error(msg, fields...)
+}
+
+func (r *fieldRouter) Panic(msg string, fields ...Field) {
+	r.route(fields).Log(PanicLevel, msg, fields...)
+	panic(msg)
+}
+
+func (r *fieldRouter) Fatal(msg string, fields ...Field) {
+	r.route(fields).Log(FatalLevel, msg, fields...)
+	_exit(1)
+}
+
+func (r *fieldRouter) DFatal(msg string, fields ...Field) {
+	r.route(fields).DFatal(msg, fields...)
+}
+
+func (r *fieldRouter) route(fields []Field) Logger {
+	v, ok := r.find(fields)
+	if !ok {
+		v, ok = r.value, r.hasValue
+	}
+	if ok {
+		if log, found := r.routes[v]; found {
+			return log
+		}
+	}
+	return r.fallback
+}
+
+// find returns the value of the last routing field.
+func (r *fieldRouter) find(fields []Field) (string, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		f := fields[i]
+		if f.key != r.key {
+			continue
+		}
+		switch f.fieldType {
+		case stringType:
+			return f.str, true
+		case stringerType:
+			return f.obj.(fmt.Stringer).String(), true
+		}
+	}
+	return "", false
+}
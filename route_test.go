@@ -0,0 +1,103 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap_test
+
+import (
+	"testing"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteByField(t *testing.T) {
+	acme, acmeSink := spy.New(zap.DebugLevel)
+	globex, globexSink := spy.New(zap.DebugLevel)
+	fallback, fallbackSink := spy.New(zap.DebugLevel)
+	log := zap.RouteByField("tenant", map[string]zap.Logger{"acme": acme, "globex": globex}, fallback)
+
+	log.Info("no tenant")
+	log.Info("acme", zap.String("tenant", "acme"))
+	log.Warn("unknown tenant", zap.String("tenant", "initech"))
+	log.With(zap.String("tenant", "globex")).Error("globex context")
+	log.With(zap.String("tenant", "globex")).Debug("call site wins", zap.String("tenant", "acme"))
+	log.Log(zap.InfoLevel, "not a string", zap.Int("tenant", 1))
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.InfoLevel, Msg: "acme", Fields: []zap.Field{zap.String("tenant", "acme")}},
+		{Level: zap.DebugLevel, Msg: "call site wins", Fields: []zap.Field{zap.String("tenant", "acme")}},
+	}, acmeSink.Logs(), "Unexpected entries routed to acme.")
+	assert.Equal(t, []spy.Log{
+		{Level: zap.ErrorLevel, Msg: "globex context", Fields: []zap.Field{zap.String("tenant", "globex")}},
+	}, globexSink.Logs(), "Unexpected entries routed to globex.")
+	assert.Equal(t, []spy.Log{
+		{Level: zap.InfoLevel, Msg: "no tenant", Fields: []zap.Field{}},
+		{Level: zap.WarnLevel, Msg: "unknown tenant", Fields: []zap.Field{zap.String("tenant", "initech")}},
+		{Level: zap.InfoLevel, Msg: "not a string", Fields: []zap.Field{zap.Int("tenant", 1)}},
+	}, fallbackSink.Logs(), "Unexpected entries routed to fallback.")
+}
+
+func TestRouteByFieldWithPropagates(t *testing.T) {
+	acme, acmeSink := spy.New(zap.DebugLevel)
+	fallback, fallbackSink := spy.New(zap.DebugLevel)
+	log := zap.RouteByField("tenant", map[string]zap.Logger{"acme": acme}, fallback).With(zap.Int("request", 42))
+
+	log.Info("one")
+	log.Info("two", zap.String("tenant", "acme"))
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.InfoLevel, Msg: "two", Fields: []zap.Field{zap.Int("request", 42), zap.String("tenant", "acme")}},
+	}, acmeSink.Logs(), "Expected context to propagate to routes.")
+	assert.Equal(t, []spy.Log{
+		{Level: zap.InfoLevel, Msg: "one", Fields: []zap.Field{zap.Int("request", 42)}},
+	}, fallbackSink.Logs(), "Expected context to propagate to the fallback.")
+}
+
+func TestRouteByFieldCheck(t *testing.T) {
+	acme, acmeSink := spy.New(zap.DebugLevel)
+	fallback, _ := spy.New(zap.WarnLevel)
+	log := zap.RouteByField("tenant", map[string]zap.Logger{"acme": acme}, fallback)
+
+	log.Check(zap.DebugLevel, "checked").Write(zap.String("tenant", "acme"))
+	assert.Equal(t, []spy.Log{
+		{Level: zap.DebugLevel, Msg: "checked", Fields: []zap.Field{zap.String("tenant", "acme")}},
+	}, acmeSink.Logs(), "Expected checked message to be routed on Write.")
+
+	acme, _ = spy.New(zap.ErrorLevel)
+	log = zap.RouteByField("tenant", map[string]zap.Logger{"acme": acme}, fallback)
+	assert.Nil(t, log.Check(zap.InfoLevel, "disabled"), "Expected nil CheckedMessage when all routes are disabled.")
+}
+
+func TestRouteByFieldPanic(t *testing.T) {
+	acme, acmeSink := spy.New(zap.DebugLevel)
+	fallback, fallbackSink := spy.New(zap.DebugLevel)
+	log := zap.RouteByField("tenant", map[string]zap.Logger{"acme": acme}, fallback)
+
+	assert.Panics(t, func() { log.Panic("foo", zap.String("tenant", "acme")) }, "Expected router to panic.")
+	assert.Panics(t, func() { log.Check(zap.PanicLevel, "bar").Write() }, "Expected checked message to panic.")
+	assert.Equal(t, []spy.Log{
+		{Level: zap.PanicLevel, Msg: "foo", Fields: []zap.Field{zap.String("tenant", "acme")}},
+	}, acmeSink.Logs(), "Unexpected entries routed to acme.")
+	assert.Equal(t, []spy.Log{
+		{Level: zap.PanicLevel, Msg: "bar", Fields: []zap.Field{}},
+	}, fallbackSink.Logs(), "Unexpected entries routed to fallback.")
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// StartRuntimeStats starts a goroutine that logs a snapshot of the Go
+// runtime's statistics at InfoLevel once per interval. Each entry has the
+// message "runtime stats" and includes a MemStats field ("memory") and the
+// number of goroutines ("goroutines"). Since MemStats is encoded lazily, ticks
+// that the logger doesn't write don't call runtime.ReadMemStats.
+//
+// The returned function stops the goroutine and waits for it to exit; it's
+// safe to call more than once. Zero or negative intervals don't start a
+// goroutine at all, and the returned function does nothing. Since
+// runtime.ReadMemStats briefly stops the world, avoid very short intervals in
+// production.
+func StartRuntimeStats(log Logger, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	ticker := time.NewTicker(interval)
+	quit := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				logRuntimeStats(log)
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(quit)
+			<-done
+		})
+	}
+}
+
+func logRuntimeStats(log Logger) {
+	log.Info("runtime stats",
+		MemStats("memory"),
+		Int("goroutines", runtime.NumGoroutine()),
+	)
+}
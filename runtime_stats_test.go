@@ -0,0 +1,80 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartRuntimeStats(t *testing.T) {
+	logged := make(chan struct{}, 1)
+	signal := Hook(func(*Entry) error {
+		select {
+		case logged <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+
+	withJSONLogger(t, opts(signal), func(logger Logger, buf *testBuffer) {
+		stop := StartRuntimeStats(logger, time.Millisecond)
+		select {
+		case <-logged:
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for runtime stats to be logged.")
+		}
+		stop()
+		stop()
+
+		lines := buf.Lines()
+		require.True(t, len(lines) > 0, "Expected at least one log entry.")
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry), "Expected valid JSON.")
+		assert.Equal(t, "runtime stats", entry["msg"], "Unexpected message.")
+		assert.Equal(t, "info", entry["level"], "Unexpected level.")
+		require.Contains(t, entry, "memory", "Expected runtime stats to include memory statistics.")
+		memory, ok := entry["memory"].(map[string]interface{})
+		require.True(t, ok, "Expected memory statistics to be an object.")
+		for _, key := range []string{"alloc", "heapInuse", "sys", "numGC"} {
+			assert.Contains(t, memory, key, "Expected memory statistics to include %q.", key)
+		}
+		assert.True(t, entry["goroutines"].(float64) >= 1, "Expected at least one goroutine.")
+
+		n := len(buf.Lines())
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, n, len(buf.Lines()), "Expected no entries after stopping.")
+	})
+}
+
+func TestStartRuntimeStatsNonPositiveInterval(t *testing.T) {
+	withJSONLogger(t, nil, func(logger Logger, buf *testBuffer) {
+		for _, interval := range []time.Duration{0, -time.Second} {
+			stop := StartRuntimeStats(logger, interval)
+			stop()
+		}
+		assert.Equal(t, "", buf.String(), "Expected no entries without a positive interval.")
+	})
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// Layout for the suffix added to rolled shard files.
+const _shardRollLayout = "20060102T150405.000000000"
+
+var errNoShards = errors.New("can't shard output across zero files")
+
+// A ShardedWriteSyncer spreads writes round-robin across several files, so
+// that multiple readers (e.g., parallel log shippers) can each consume a
+// shard. Since loggers write each entry with a single call to Write, every
+// entry lands entirely in one file. ShardedWriteSyncers are safe for
+// concurrent use, and writes to different shards proceed in parallel; to
+// benefit, pass them to loggers with UnsafeOutput rather than Output, which
+// serializes all writes.
+//
+// Each shard rolls independently: when a write would grow a shard beyond the
+// size limit, the shard's file is renamed with a timestamp suffix (e.g.,
+// "app-0.log.20160102T150405.000000000") and a fresh file is opened in its
+// place. A single write larger than the limit still goes to an empty file.
+// Call Rotate to roll every shard on demand, for example from an
+// administrative endpoint.
+//
+// Sharding gives up the ordering of a single file. Consecutive entries go to
+// different shards, and concurrent writes to the same shard may land in
+// either order, so consumers that need a total order should sort entries by
+// timestamp.
+type ShardedWriteSyncer struct {
+	shards   []*fileShard
+	maxBytes int64
+	next     uint64
+}
+
+type fileShard struct {
+	sync.Mutex
+
+	path string
+	file *os.File
+	size int64
+}
+
+// NewShardedWriteSyncer opens (or creates) the supplied files for appending
+// and returns a ShardedWriteSyncer that rolls each one once it reaches
+// maxBytes. Zero or negative limits disable rolling.
+func NewShardedWriteSyncer(maxBytes int64, paths ...string) (*ShardedWriteSyncer, error) {
+	if len(paths) == 0 {
+		return nil, errNoShards
+	}
+	s := &ShardedWriteSyncer{
+		shards:   make([]*fileShard, 0, len(paths)),
+		maxBytes: maxBytes,
+	}
+	for _, path := range paths {
+		shard := &fileShard{path: path}
+		if err := shard.open(); err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.shards = append(s.shards, shard)
+	}
+	return s, nil
+}
+
+// Write writes the data to the next shard, rolling the shard first if
+// necessary.
+func (s *ShardedWriteSyncer) Write(bs []byte) (int, error) {
+	i := (atomic.AddUint64(&s.next, 1) - 1) % uint64(len(s.shards))
+	shard := s.shards[i]
+	shard.Lock()
+	defer shard.Unlock()
+
+	var rollErr error
+	if s.maxBytes > 0 && shard.size > 0 && shard.size+int64(len(bs)) > s.maxBytes {
+		// If the shard can't roll, it keeps its current file; don't lose the
+		// entry, but report the failure.
+		rollErr = shard.roll()
+	}
+	n, err := shard.file.Write(bs)
+	shard.size += int64(n)
+	if err == nil {
+		err = rollErr
+	}
+	return n, err
+}
+
+// Sync syncs every shard.
+func (s *ShardedWriteSyncer) Sync() error {
+	var errs multiError
+	for _, shard := range s.shards {
+		shard.Lock()
+		if err := shard.file.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+		shard.Unlock()
+	}
+	return errs.asError()
+}
+
+// Rotate syncs and rolls every non-empty shard immediately, regardless of its
+// size. Each shard is locked while it rolls, so concurrent writes either
+// complete before the shard rolls or go to the fresh file. If a shard fails to
+// roll, Rotate still attempts the rest and returns all the errors.
+//
+// Loggers don't expose their outputs, so keep a reference to the
+// ShardedWriteSyncer to rotate it.
+func (s *ShardedWriteSyncer) Rotate() error {
+	var errs multiError
+	for _, shard := range s.shards {
+		shard.Lock()
+		if shard.size > 0 {
+			if err := shard.file.Sync(); err != nil {
+				errs = append(errs, err)
+			} else if err := shard.roll(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		shard.Unlock()
+	}
+	return errs.asError()
+}
+
+// Close syncs and closes every shard. The ShardedWriteSyncer must not be used
+// afterwards.
+func (s *ShardedWriteSyncer) Close() error {
+	var errs multiError
+	for _, shard := range s.shards {
+		shard.Lock()
+		if err := shard.file.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+		if err := shard.file.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		shard.Unlock()
+	}
+	return errs.asError()
+}
+
+// open opens the shard's file for appending. The caller must hold the lock.
+func (f *fileShard) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// roll renames the shard's file and opens a fresh one in its place, closing
+// the old file only once the new one is open. If either step fails, the shard
+// keeps writing to its current file. The caller must hold the lock.
+func (f *fileShard) roll() error {
+	old := f.file
+	rolled := f.path + "." + _timeNow().UTC().Format(_shardRollLayout)
+	if err := os.Rename(f.path, rolled); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		// Put the file back, so that the next roll starts from a clean slate.
+		os.Rename(rolled, f.path)
+		return err
+	}
+	return old.Close()
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readShardDir(t *testing.T, dir string) map[string]string {
+	infos, err := ioutil.ReadDir(dir)
+	require.NoError(t, err, "Failed to read directory.")
+	contents := make(map[string]string, len(infos))
+	for _, info := range infos {
+		bs, err := ioutil.ReadFile(filepath.Join(dir, info.Name()))
+		require.NoError(t, err, "Failed to read file.")
+		contents[info.Name()] = string(bs)
+	}
+	return contents
+}
+
+func TestShardedWriteSyncer(t *testing.T) {
+	defer stubNow(time.Second)()
+	dir, err := ioutil.TempDir("", "zap-shards")
+	require.NoError(t, err, "Failed to create temporary directory.")
+	defer os.RemoveAll(dir)
+
+	ws, err := NewShardedWriteSyncer(9, filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log"))
+	require.NoError(t, err, "Unexpected error constructing ShardedWriteSyncer.")
+	for _, entry := range []string{"one\n", "two\n", "three\n", "four\n"} {
+		_, err := ws.Write([]byte(entry))
+		require.NoError(t, err, "Unexpected error writing.")
+	}
+	assert.NoError(t, ws.Sync(), "Unexpected error syncing.")
+	assert.NoError(t, ws.Close(), "Unexpected error closing.")
+
+	suffix := "." + time.Unix(1, 0).UTC().Format(_shardRollLayout)
+	assert.Equal(t, map[string]string{
+		"a.log":          "three\n",
+		"a.log" + suffix: "one\n",
+		"b.log":          "two\nfour\n",
+	}, readShardDir(t, dir), "Unexpected shard contents.")
+}
+
+func TestShardedWriteSyncerAppends(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zap-shards")
+	require.NoError(t, err, "Failed to create temporary directory.")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "a.log")
+	require.NoError(t, ioutil.WriteFile(path, []byte("existing\n"), 0644), "Failed to write file.")
+
+	ws, err := NewShardedWriteSyncer(0, path)
+	require.NoError(t, err, "Unexpected error constructing ShardedWriteSyncer.")
+	logger := New(NewJSONEncoder(NoTime()), Output(ws))
+	logger.Info("hello")
+	assert.NoError(t, Close(logger), "Unexpected error closing logger.")
+
+	names := make([]string, 0)
+	for name := range readShardDir(t, dir) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"a.log"}, names, "Expected no rolled files without a size limit.")
+	assert.Equal(t, "existing\n{\"level\":\"info\",\"msg\":\"hello\"}\n", readShardDir(t, dir)["a.log"], "Expected entries to be appended.")
+}
+
+func TestShardedWriteSyncerRotate(t *testing.T) {
+	defer stubNow(time.Second)()
+	dir, err := ioutil.TempDir("", "zap-shards")
+	require.NoError(t, err, "Failed to create temporary directory.")
+	defer os.RemoveAll(dir)
+
+	ws, err := NewShardedWriteSyncer(0, filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log"))
+	require.NoError(t, err, "Unexpected error constructing ShardedWriteSyncer.")
+	_, err = ws.Write([]byte("before\n"))
+	require.NoError(t, err, "Unexpected error writing.")
+	require.NoError(t, ws.Rotate(), "Unexpected error rotating.")
+	_, err = ws.Write([]byte("after\n"))
+	require.NoError(t, err, "Unexpected error writing.")
+	assert.NoError(t, ws.Close(), "Unexpected error closing.")
+
+	suffix := "." + time.Unix(1, 0).UTC().Format(_shardRollLayout)
+	assert.Equal(t, map[string]string{
+		"a.log":          "",
+		"a.log" + suffix: "before\n",
+		"b.log":          "after\n",
+	}, readShardDir(t, dir), "Expected Rotate to roll only non-empty shards.")
+}
+
+func TestShardedWriteSyncerRotateConcurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zap-shards")
+	require.NoError(t, err, "Failed to create temporary directory.")
+	defer os.RemoveAll(dir)
+
+	ws, err := NewShardedWriteSyncer(0, filepath.Join(dir, "a.log"))
+	require.NoError(t, err, "Unexpected error constructing ShardedWriteSyncer.")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ws.Write([]byte("entry\n"))
+			}
+		}()
+	}
+	// Each rotation needs a distinct suffix.
+	for i := 0; i < 5; i++ {
+		func() {
+			defer stubNow(time.Duration(i+1) * time.Second)()
+			assert.NoError(t, ws.Rotate(), "Unexpected error rotating.")
+		}()
+	}
+	wg.Wait()
+	assert.NoError(t, ws.Close(), "Unexpected error closing.")
+
+	var total int
+	for _, contents := range readShardDir(t, dir) {
+		total += len(contents)
+	}
+	assert.Equal(t, 400*len("entry\n"), total, "Expected no entries to be lost while rotating.")
+}
+
+func TestShardedWriteSyncerRollFailure(t *testing.T) {
+	defer stubNow(time.Second)()
+	dir, err := ioutil.TempDir("", "zap-shards")
+	require.NoError(t, err, "Failed to create temporary directory.")
+	defer os.RemoveAll(dir)
+
+	// Occupy the rolled file's name with a non-empty directory, so the rename
+	// fails.
+	suffix := "." + time.Unix(1, 0).UTC().Format(_shardRollLayout)
+	blocker := filepath.Join(dir, "a.log"+suffix)
+	require.NoError(t, os.Mkdir(blocker, 0755), "Failed to create directory.")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(blocker, "x"), nil, 0644), "Failed to write file.")
+
+	ws, err := NewShardedWriteSyncer(5, filepath.Join(dir, "a.log"))
+	require.NoError(t, err, "Unexpected error constructing ShardedWriteSyncer.")
+	_, err = ws.Write([]byte("one\n"))
+	require.NoError(t, err, "Unexpected error writing.")
+	n, err := ws.Write([]byte("two\n"))
+	assert.Error(t, err, "Expected an error when the shard can't roll.")
+	assert.Equal(t, 4, n, "Expected the entry to be written to the current file.")
+
+	require.NoError(t, os.RemoveAll(blocker), "Failed to remove directory.")
+	_, err = ws.Write([]byte("three\n"))
+	require.NoError(t, err, "Unexpected error writing after the failure cleared.")
+	assert.NoError(t, ws.Close(), "Unexpected error closing.")
+	assert.Equal(t, map[string]string{
+		"a.log":          "three\n",
+		"a.log" + suffix: "one\ntwo\n",
+	}, readShardDir(t, dir), "Expected the shard to keep its file until it could roll.")
+}
+
+func TestShardedWriteSyncerErrors(t *testing.T) {
+	_, err := NewShardedWriteSyncer(0)
+	assert.Equal(t, errNoShards, err, "Expected an error without any paths.")
+
+	_, err = NewShardedWriteSyncer(0, filepath.Join(os.TempDir(), "does-not-exist", "a.log"))
+	assert.Error(t, err, "Expected an error opening a file in a missing directory.")
+}
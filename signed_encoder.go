@@ -0,0 +1,231 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Keys added to each entry by a signed JSON encoder.
+const (
+	_signedKeyIDKey   = "keyID"
+	_signedPrevSigKey = "prevSig"
+	_signedSigKey     = "sig"
+)
+
+// _sigSuffixLen is the length of the `,"sig":"<hex>"}` suffix that ends each
+// signed entry, not counting the newline.
+var _sigSuffixLen = len(`,"`+_signedSigKey+`":""}`) + hex.EncodedLen(sha256.Size)
+
+var errSignedFormat = errors.New("entry doesn't end with a signature")
+
+// A Signer holds the key and chain state for a tamper-evident log. It's
+// shared by all the encoders created with NewSignedJSONEncoder from it, and
+// by their clones, so every entry written by a logger joins the same chain.
+// Signers are safe for concurrent use.
+type Signer struct {
+	sync.Mutex
+
+	keyID string
+	key   []byte
+	prev  string
+}
+
+// NewSigner creates a Signer that signs entries with the supplied HMAC key.
+// The key ID is recorded in each entry so that verifiers can find the right
+// key; it must not be secret.
+func NewSigner(keyID string, key []byte) *Signer {
+	return &Signer{keyID: keyID, key: key}
+}
+
+// Rotate replaces the signing key. Subsequent entries are signed with the new
+// key and record the new key ID, but the chain continues unbroken: the first
+// entry signed with the new key still includes the signature of the last
+// entry signed with the old one.
+func (s *Signer) Rotate(keyID string, key []byte) {
+	s.Lock()
+	s.keyID, s.key = keyID, key
+	s.Unlock()
+}
+
+// NewSignedJSONEncoder creates an encoder for tamper-evident audit logs. It
+// accepts the same options as NewJSONEncoder, and writes canonical JSON (see
+// NewCanonicalJSONEncoder) with three extra fields:
+//
+//   - "keyID" is the ID of the key used to sign the entry.
+//   - "prevSig" is the signature of the previous entry in the chain, or an
+//     empty string for the first entry.
+//   - "sig" is the hex-encoded HMAC-SHA256, using the signer's key, of the
+//     canonical entry without the "sig" field. It's always the entry's last
+//     field.
+//
+// Chaining each entry to its predecessor means that deleting, reordering, or
+// modifying any entry invalidates the rest of the log; VerifySignedLog checks
+// a log written by this encoder. The encoder holds the signer's lock while
+// writing, so entries reach the output in chain order. The chain only
+// advances when an entry is written successfully.
+//
+// The key names above are reserved; don't add fields with the same names.
+func NewSignedJSONEncoder(signer *Signer, options ...JSONOption) Encoder {
+	return signedEncoder{
+		Encoder: NewCanonicalJSONEncoder(options...),
+		signer:  signer,
+	}
+}
+
+type signedEncoder struct {
+	Encoder
+
+	signer *Signer
+}
+
+func (enc signedEncoder) Clone() Encoder {
+	return signedEncoder{
+		Encoder: enc.Encoder.Clone(),
+		signer:  enc.signer,
+	}
+}
+
+// AddRawJSON passes the data to the canonical encoder, so that it's signed as
+// JSON rather than as a quoted string.
+func (enc signedEncoder) AddRawJSON(key string, data []byte) error {
+	return addRawJSON(enc.Encoder, key, data)
+}
+
+func (enc signedEncoder) setMessageKey(key string) {
+	setMessageKey(enc.Encoder, key)
+}
+
+func (enc signedEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time.Time) error {
+	if sink == nil {
+		return errNilSink
+	}
+	s := enc.signer
+	s.Lock()
+	defer s.Unlock()
+
+	unsigned := enc.Encoder.Clone()
+	// The chain fields must survive MaxFields, or the log can't be verified.
+	addUnlimitedString(unsigned, _signedKeyIDKey, s.keyID)
+	addUnlimitedString(unsigned, _signedPrevSigKey, s.prev)
+	var buf bytes.Buffer
+	err := unsigned.WriteEntry(&buf, msg, lvl, t)
+	unsigned.Free()
+	if err != nil {
+		return err
+	}
+
+	payload, ending := splitLineEnding(buf.Bytes())
+	sig := signEntry(s.key, payload)
+	line := make([]byte, 0, len(payload)+_sigSuffixLen+len(ending))
+	line = append(line, payload[:len(payload)-1]...)
+	line = append(line, `,"`+_signedSigKey+`":"`...)
+	line = append(line, sig...)
+	line = append(line, '"', '}')
+	line = append(line, ending...)
+
+	n, err := sink.Write(line)
+	if err != nil {
+		return err
+	}
+	if n != len(line) {
+		return fmt.Errorf("incomplete write: only wrote %v of %v bytes", n, len(line))
+	}
+	s.prev = sig
+	return nil
+}
+
+func signEntry(key, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignedLog checks a log written by an encoder created with
+// NewSignedJSONEncoder, one entry per line. It looks up each entry's key by
+// its key ID, checks the entry's signature, and checks that the entry's
+// "prevSig" matches the signature of the entry before it.
+//
+// The first entry's "prevSig" must match prevSig: pass an empty string to
+// verify a complete log, or the result of verifying the previous file to
+// verify a log that's split across several files. VerifySignedLog returns the
+// signature of the last entry, or an error identifying the first line that
+// fails verification.
+func VerifySignedLog(r io.Reader, keys map[string][]byte, prevSig string) (string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<30)
+	for line := 1; scanner.Scan(); line++ {
+		sig, err := verifySignedEntry(scanner.Bytes(), keys, prevSig)
+		if err != nil {
+			return prevSig, fmt.Errorf("line %v: %v", line, err)
+		}
+		prevSig = sig
+	}
+	return prevSig, scanner.Err()
+}
+
+func verifySignedEntry(entry []byte, keys map[string][]byte, prevSig string) (string, error) {
+	if len(entry) < _sigSuffixLen {
+		return "", errSignedFormat
+	}
+	split := len(entry) - _sigSuffixLen
+	suffix := entry[split:]
+	prefix := `,"` + _signedSigKey + `":"`
+	if !bytes.HasPrefix(suffix, []byte(prefix)) || !bytes.HasSuffix(suffix, []byte(`"}`)) {
+		return "", errSignedFormat
+	}
+	sig := string(suffix[len(prefix) : len(suffix)-2])
+
+	payload := make([]byte, 0, split+1)
+	payload = append(payload, entry[:split]...)
+	payload = append(payload, '}')
+	var fields struct {
+		KeyID   *string `json:"keyID"`
+		PrevSig *string `json:"prevSig"`
+	}
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return "", err
+	}
+	if fields.KeyID == nil || fields.PrevSig == nil {
+		return "", errors.New("entry is missing its key ID or previous signature")
+	}
+	key, ok := keys[*fields.KeyID]
+	if !ok {
+		return "", fmt.Errorf("unknown key ID %q", *fields.KeyID)
+	}
+	if !hmac.Equal([]byte(sig), []byte(signEntry(key, payload))) {
+		return "", errors.New("signature mismatch")
+	}
+	if *fields.PrevSig != prevSig {
+		return "", errors.New("chain broken: previous signature doesn't match")
+	}
+	return sig, nil
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/uber-go/zap/spywrite"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSignedLogger(signer *Signer, buf *bytes.Buffer) Logger {
+	return New(NewSignedJSONEncoder(signer, NoTime()), Output(AddSync(buf)))
+}
+
+func TestSignedJSONEncoder(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := newSignedLogger(NewSigner("k1", []byte("secret")), buf)
+	logger.Info("first", String("user", "alice"))
+	logger.With(Int("attempt", 2)).Warn("second")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Equal(t, 2, len(lines), "Expected two entries.")
+	assert.Regexp(t, `^\{"keyID":"k1","level":"info","msg":"first","prevSig":"","user":"alice","sig":"[0-9a-f]{64}"\}$`, lines[0], "Unexpected first entry.")
+	firstSig := lines[0][len(lines[0])-66 : len(lines[0])-2]
+	assert.Contains(t, lines[1], `"prevSig":"`+firstSig+`"`, "Expected the second entry to chain to the first.")
+
+	last, err := VerifySignedLog(strings.NewReader(buf.String()), map[string][]byte{"k1": []byte("secret")}, "")
+	require.NoError(t, err, "Expected the log to verify.")
+	assert.Equal(t, lines[1][len(lines[1])-66:len(lines[1])-2], last, "Expected the last signature to be returned.")
+}
+
+func TestVerifySignedLogTampering(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := newSignedLogger(NewSigner("k1", []byte("secret")), buf)
+	for _, user := range []string{"alice", "bob", "carol"} {
+		logger.Info("login", String("user", user))
+	}
+	keys := map[string][]byte{"k1": []byte("secret")}
+	lines := strings.SplitAfter(buf.String(), "\n")
+
+	tests := []struct {
+		desc     string
+		log      string
+		expected string
+	}{
+		{"modified entry", lines[0] + strings.Replace(lines[1], "bob", "eve", 1) + lines[2], "line 2: signature mismatch"},
+		{"deleted entry", lines[0] + lines[2], "line 2: chain broken"},
+		{"reordered entries", lines[1] + lines[0] + lines[2], "line 1: chain broken"},
+		{"truncated start", lines[1] + lines[2], "line 1: chain broken"},
+		{"unsigned entry", lines[0] + `{"level":"info","msg":"forged"}` + "\n", "line 2: entry doesn't end with a signature"},
+	}
+	for _, tt := range tests {
+		_, err := VerifySignedLog(strings.NewReader(tt.log), keys, "")
+		if assert.Error(t, err, "Expected verification to fail for %s.", tt.desc) {
+			assert.Contains(t, err.Error(), tt.expected, "Unexpected error for %s.", tt.desc)
+		}
+	}
+
+	_, err := VerifySignedLog(strings.NewReader(buf.String()), map[string][]byte{"k1": []byte("wrong")}, "")
+	assert.Error(t, err, "Expected verification with the wrong key to fail.")
+}
+
+func TestSignerRotate(t *testing.T) {
+	signer := NewSigner("k1", []byte("old"))
+	first, second := &bytes.Buffer{}, &bytes.Buffer{}
+	newSignedLogger(signer, first).Info("before")
+	signer.Rotate("k2", []byte("new"))
+	newSignedLogger(signer, second).Info("after")
+	assert.Contains(t, second.String(), `"keyID":"k2"`, "Expected entries to record the new key ID.")
+
+	keys := map[string][]byte{"k1": []byte("old"), "k2": []byte("new")}
+	last, err := VerifySignedLog(strings.NewReader(first.String()), keys, "")
+	require.NoError(t, err, "Expected the first file to verify.")
+	_, err = VerifySignedLog(strings.NewReader(second.String()), keys, last)
+	assert.NoError(t, err, "Expected the chain to continue across files and key rotations.")
+
+	_, err = VerifySignedLog(strings.NewReader(second.String()), map[string][]byte{"k1": []byte("old")}, last)
+	if assert.Error(t, err, "Expected unknown key IDs to fail verification.") {
+		assert.Contains(t, err.Error(), `unknown key ID "k2"`, "Unexpected error message.")
+	}
+}
+
+func TestSignedJSONEncoderConcurrent(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := newSignedLogger(NewSigner("k1", []byte("secret")), buf)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				logger.Info("concurrent", Int("goroutine", i), Int("n", j))
+			}
+		}(i)
+	}
+	wg.Wait()
+	_, err := VerifySignedLog(buf, map[string][]byte{"k1": []byte("secret")}, "")
+	assert.NoError(t, err, "Expected entries logged concurrently to form a valid chain.")
+}
+
+func TestSignedJSONEncoderWriteErrors(t *testing.T) {
+	signer := NewSigner("k1", []byte("secret"))
+	enc := NewSignedJSONEncoder(signer)
+	assert.Equal(t, errNilSink, enc.WriteEntry(nil, "hello", InfoLevel, epoch), "Expected an error writing to a nil sink.")
+	assert.Error(t, enc.WriteEntry(spywrite.FailWriter{}, "hello", InfoLevel, epoch), "Expected write errors to propagate.")
+	assert.Equal(t, "", signer.prev, "Expected failed writes not to advance the chain.")
+}
+
+func TestVerifySignedLogCRLF(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(NewSignedJSONEncoder(NewSigner("k1", []byte("secret")), NoTime(), LineEnding("\r\n")), Output(AddSync(buf)))
+	logger.Info("first")
+	logger.Info("second")
+
+	assert.Equal(t, 2, strings.Count(buf.String(), "\r\n"), "Expected CRLF-terminated entries.")
+	_, err := VerifySignedLog(strings.NewReader(buf.String()), map[string][]byte{"k1": []byte("secret")}, "")
+	assert.NoError(t, err, "Expected a CRLF-terminated log to verify.")
+}
+
+func TestSignedJSONEncoderMaxFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(NewSignedJSONEncoder(NewSigner("k1", []byte("secret")), NoTime(), MaxFields(1)), Output(AddSync(buf)))
+	logger.Info("first", String("user", "alice"), Int("attempt", 2))
+	logger.Info("second")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Equal(t, 2, len(lines), "Expected two entries.")
+	assert.Regexp(t, `^\{"fieldsTruncated":1,"keyID":"k1","level":"info","msg":"first","prevSig":"","user":"alice","sig":"[0-9a-f]{64}"\}$`, lines[0], "Expected the chain fields not to count toward MaxFields.")
+	_, err := VerifySignedLog(strings.NewReader(buf.String()), map[string][]byte{"k1": []byte("secret")}, "")
+	assert.NoError(t, err, "Expected the log to verify.")
+}
+
+func TestSignedJSONEncoderByteLimiter(t *testing.T) {
+	defer stubNow(0)()
+	buf := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	limiter := NewByteLimiter(400)
+	logger := New(
+		NewSignedJSONEncoder(NewSigner("k1", []byte("secret")), NoTime()),
+		Output(AddSync(buf)),
+		ErrorOutput(AddSync(errBuf)),
+		limiter,
+	)
+	for i := 0; i < 4; i++ {
+		logger.Info("entry", Int("i", i))
+	}
+	logger.Error("kept")
+
+	assert.NotEmpty(t, limiter.DroppedByLevel(), "Expected the limiter to drop entries.")
+	assert.Empty(t, errBuf.String(), "Expected dropped entries not to be reported as errors.")
+	_, err := VerifySignedLog(strings.NewReader(buf.String()), map[string][]byte{"k1": []byte("secret")}, "")
+	assert.NoError(t, err, "Expected dropped entries not to break the chain.")
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// Socket types tried, in order, when connecting to a Unix domain socket. Like
+// the standard library's log/syslog package, prefer datagrams, which preserve
+// entry boundaries.
+var _unixSocketNetworks = []string{"unixgram", "unix"}
+
+// socketWriteSyncer writes to a Unix domain socket, reconnecting as necessary.
+type socketWriteSyncer struct {
+	sync.Mutex
+
+	path string
+	conn net.Conn
+}
+
+// UnixSocketWriteSyncer returns a WriteSyncer that writes each entry to the
+// Unix domain socket at the supplied path, which is useful for shipping logs
+// to a local agent. It connects with a datagram (unixgram) socket if the
+// listener supports it, and a stream (unix) socket otherwise. Datagram
+// listeners receive one entry per datagram, so entries larger than the
+// system's datagram size limit can't be written.
+//
+// If the socket doesn't exist yet or isn't accepting connections (e.g., because
+// the agent hasn't started), UnixSocketWriteSyncer still succeeds, and each
+// write retries the connection until the socket is available. Other
+// connection errors, including permission errors, are returned immediately.
+// If a write fails, the WriteSyncer reconnects and retries it once; entries
+// that still can't be written return an error, which loggers report to their
+// ErrorOutput.
+//
+// Like other WriteSyncers, it should be passed to loggers with the Output
+// option. Closing the logger closes the socket.
+func UnixSocketWriteSyncer(path string) (WriteSyncer, error) {
+	s := &socketWriteSyncer{path: path}
+	if err := s.connect(); err != nil && !isSocketUnavailable(err) {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write writes the data to the socket, connecting first if necessary.
+func (s *socketWriteSyncer) Write(bs []byte) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := s.conn.Write(bs)
+	if err == nil {
+		return n, nil
+	}
+
+	// The listener may have restarted, so reconnect and try again.
+	s.disconnect()
+	if err := s.connect(); err != nil {
+		return 0, err
+	}
+	return s.conn.Write(bs)
+}
+
+// Sync is a no-op, since sockets don't buffer writes.
+func (s *socketWriteSyncer) Sync() error {
+	return nil
+}
+
+// Close closes the socket. Later writes reconnect.
+func (s *socketWriteSyncer) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// connect dials the socket. The caller must hold the lock.
+func (s *socketWriteSyncer) connect() error {
+	var err error
+	for _, network := range _unixSocketNetworks {
+		var conn net.Conn
+		if conn, err = net.Dial(network, s.path); err == nil {
+			s.conn = conn
+			return nil
+		}
+	}
+	if isSocketPermissionError(err) {
+		return fmt.Errorf("permission denied connecting to log socket %v: %v", s.path, err)
+	}
+	return err
+}
+
+// disconnect closes the current connection, ignoring errors. The caller must
+// hold the lock.
+func (s *socketWriteSyncer) disconnect() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+func isSocketUnavailable(err error) bool {
+	err = unwrapSocketError(err)
+	return os.IsNotExist(err) || isConnRefused(err)
+}
+
+func isSocketPermissionError(err error) bool {
+	return os.IsPermission(unwrapSocketError(err))
+}
+
+// unwrapSocketError unwraps the system error from an error returned by the
+// net package.
+func unwrapSocketError(err error) error {
+	if opErr, ok := err.(*net.OpError); ok {
+		err = opErr.Err
+	}
+	if sysErr, ok := err.(*os.SyscallError); ok {
+		err = sysErr.Err
+	}
+	return err
+}
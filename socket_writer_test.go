@@ -0,0 +1,167 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withSocketDir(t *testing.T, f func(dir string)) {
+	dir, err := ioutil.TempDir("", "zap-socket")
+	require.NoError(t, err, "Failed to create temporary directory.")
+	defer os.RemoveAll(dir)
+	f(dir)
+}
+
+func listenUnixgram(t *testing.T, path string) *net.UnixConn {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err, "Failed to listen on datagram socket.")
+	return conn
+}
+
+func readDatagram(t *testing.T, conn *net.UnixConn) string {
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	require.NoError(t, err, "Failed to read from datagram socket.")
+	return string(buf[:n])
+}
+
+func TestUnixSocketWriteSyncerDatagram(t *testing.T) {
+	withSocketDir(t, func(dir string) {
+		path := filepath.Join(dir, "log.sock")
+		server := listenUnixgram(t, path)
+		defer server.Close()
+
+		ws, err := UnixSocketWriteSyncer(path)
+		require.NoError(t, err, "Unexpected error connecting to socket.")
+		logger := New(newJSONEncoder(NoTime()), Output(ws))
+		logger.Info("first")
+		logger.Info("second")
+
+		assert.Equal(t, `{"level":"info","msg":"first"}`+"\n", readDatagram(t, server), "Unexpected first datagram.")
+		assert.Equal(t, `{"level":"info","msg":"second"}`+"\n", readDatagram(t, server), "Unexpected second datagram.")
+		assert.NoError(t, Close(logger), "Unexpected error closing logger.")
+	})
+}
+
+func TestUnixSocketWriteSyncerStream(t *testing.T) {
+	withSocketDir(t, func(dir string) {
+		path := filepath.Join(dir, "log.sock")
+		ln, err := net.Listen("unix", path)
+		require.NoError(t, err, "Failed to listen on stream socket.")
+		defer ln.Close()
+
+		lines := make(chan string, 2)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			scanner := bufio.NewScanner(conn)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+		}()
+
+		ws, err := UnixSocketWriteSyncer(path)
+		require.NoError(t, err, "Unexpected error connecting to socket.")
+		logger := New(newJSONEncoder(NoTime()), Output(ws))
+		logger.Info("first")
+		logger.Info("second")
+		assert.Equal(t, `{"level":"info","msg":"first"}`, <-lines, "Unexpected first line.")
+		assert.Equal(t, `{"level":"info","msg":"second"}`, <-lines, "Unexpected second line.")
+		assert.NoError(t, Close(logger), "Unexpected error closing logger.")
+	})
+}
+
+func TestUnixSocketWriteSyncerRetriesMissingSocket(t *testing.T) {
+	withSocketDir(t, func(dir string) {
+		path := filepath.Join(dir, "log.sock")
+		ws, err := UnixSocketWriteSyncer(path)
+		require.NoError(t, err, "Expected a missing socket to be retried later.")
+
+		_, err = ws.Write([]byte("dropped\n"))
+		assert.Error(t, err, "Expected an error writing before the socket exists.")
+
+		server := listenUnixgram(t, path)
+		defer server.Close()
+		_, err = ws.Write([]byte("delivered\n"))
+		require.NoError(t, err, "Unexpected error writing once the socket exists.")
+		assert.Equal(t, "delivered\n", readDatagram(t, server), "Expected the write to connect.")
+	})
+}
+
+func TestUnixSocketWriteSyncerReconnects(t *testing.T) {
+	withSocketDir(t, func(dir string) {
+		path := filepath.Join(dir, "log.sock")
+		server := listenUnixgram(t, path)
+		ws, err := UnixSocketWriteSyncer(path)
+		require.NoError(t, err, "Unexpected error connecting to socket.")
+		_, err = ws.Write([]byte("first\n"))
+		require.NoError(t, err, "Unexpected error writing to socket.")
+		assert.Equal(t, "first\n", readDatagram(t, server), "Unexpected first datagram.")
+
+		// Restart the listener.
+		server.Close()
+		require.NoError(t, os.Remove(path), "Failed to remove socket.")
+		server = listenUnixgram(t, path)
+		defer server.Close()
+
+		_, err = ws.Write([]byte("second\n"))
+		require.NoError(t, err, "Expected the write to reconnect.")
+		assert.Equal(t, "second\n", readDatagram(t, server), "Unexpected datagram after reconnecting.")
+	})
+}
+
+func TestUnixSocketWriteSyncerPermissionError(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("Permission checks don't apply to root.")
+	}
+	withSocketDir(t, func(dir string) {
+		path := filepath.Join(dir, "log.sock")
+		server := listenUnixgram(t, path)
+		defer server.Close()
+		require.NoError(t, os.Chmod(path, 0), "Failed to restrict socket permissions.")
+
+		_, err := UnixSocketWriteSyncer(path)
+		require.Error(t, err, "Expected an error connecting without permission.")
+		assert.Contains(t, err.Error(), "permission denied connecting to log socket", "Unexpected error message.")
+	})
+}
+
+func TestUnixSocketWriteSyncerErrors(t *testing.T) {
+	withSocketDir(t, func(dir string) {
+		// Socket paths are limited to about a hundred bytes.
+		path := filepath.Join(dir, strings.Repeat("x", 200))
+		_, err := UnixSocketWriteSyncer(path)
+		assert.Error(t, err, "Expected an error connecting to an invalid path.")
+	})
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package spy
+
+import (
+	"sync"
+
+	"github.com/uber-go/zap"
+)
+
+// An ExitRecorder records the exit codes passed to the function that zap
+// loggers' Fatal methods call to terminate the process. It covers loggers in
+// other packages only if they exit with zap.Exit, as zotel's loggers do.
+type ExitRecorder struct {
+	sync.Mutex
+
+	codes []int
+}
+
+// RecordExits replaces zap's exit function (see zap.ReplaceExit) with one that
+// records each exit code rather than exiting, and returns the recorder along
+// with a function to restore the original. Typical usage:
+//
+//	exits, restore := spy.RecordExits()
+//	defer restore()
+//	logger.Fatal("can't start")
+//	assert.Equal(t, []int{1}, exits.Codes())
+//
+// Since the process doesn't exit, Fatal returns normally and code following
+// it keeps running. Like zap.ReplaceExit, RecordExits isn't safe to call
+// concurrently with logging, but the returned recorder is.
+func RecordExits() (*ExitRecorder, func()) {
+	r := &ExitRecorder{}
+	restore := zap.ReplaceExit(r.exit)
+	return r, restore
+}
+
+// Exited reports whether any exit was recorded.
+func (r *ExitRecorder) Exited() bool {
+	r.Lock()
+	defer r.Unlock()
+	return len(r.codes) > 0
+}
+
+// Codes returns a copy of the recorded exit codes, in order.
+func (r *ExitRecorder) Codes() []int {
+	r.Lock()
+	defer r.Unlock()
+	return append([]int(nil), r.codes...)
+}
+
+func (r *ExitRecorder) exit(code int) {
+	r.Lock()
+	r.codes = append(r.codes, code)
+	r.Unlock()
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package spy
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/uber-go/zap"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordExits(t *testing.T) {
+	exits, restore := RecordExits()
+	defer restore()
+
+	buf := &bytes.Buffer{}
+	logger := zap.New(zap.NewJSONEncoder(zap.NoTime()), zap.Output(zap.AddSync(buf)))
+	assert.False(t, exits.Exited(), "Unexpected exit before calling Fatal.")
+	logger.Fatal("can't start")
+	zap.Tee(logger, logger).Fatal("can't start either")
+
+	assert.True(t, exits.Exited(), "Expected Fatal to exit.")
+	assert.Equal(t, []int{1, 1}, exits.Codes(), "Unexpected exit codes.")
+	assert.Contains(t, buf.String(), `"msg":"can't start"`, "Expected Fatal to log before exiting.")
+}
+
+func TestRecordExitsRestore(t *testing.T) {
+	first, restoreFirst := RecordExits()
+	second, restoreSecond := RecordExits()
+	logger := zap.New(zap.NullEncoder())
+
+	logger.Fatal("second")
+	restoreSecond()
+	logger.Fatal("first")
+	restoreFirst()
+
+	assert.Equal(t, []int{1}, second.Codes(), "Expected the innermost recorder to see the first exit.")
+	assert.Equal(t, []int{1}, first.Codes(), "Expected restoring to reinstate the previous recorder.")
+}
+
+func TestRecordExitsConcurrent(t *testing.T) {
+	exits, restore := RecordExits()
+	defer restore()
+	logger := zap.New(zap.NullEncoder())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Fatal("concurrent")
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 10, len(exits.Codes()), "Expected every exit to be recorded.")
+}
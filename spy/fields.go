@@ -0,0 +1,69 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package spy
+
+import "github.com/uber-go/zap"
+
+// FieldMap renders fields into a map, which makes assertions about them easier
+// to read than comparisons of zap.Field structs. Values keep the types passed
+// to the zap.KeyValue methods (e.g., Int fields are stored as ints), and
+// nested objects, like those added by Nest and Marshaler, are rendered as
+// nested maps. As in an encoded log entry, fields that fail to marshal are
+// replaced with an error string under the field's key plus "Error", and later
+// fields overwrite earlier ones with the same key.
+func FieldMap(fields []zap.Field) map[string]interface{} {
+	m := make(fieldMap, len(fields))
+	for _, f := range fields {
+		f.AddTo(m)
+	}
+	return m
+}
+
+// FieldMap renders the log's fields into a map. See the package-level
+// FieldMap function for details.
+func (l Log) FieldMap() map[string]interface{} {
+	return FieldMap(l.Fields)
+}
+
+// fieldMap implements zap.KeyValue backed by a map. Unlike zwrap.KeyValueMap,
+// it stores nested objects as plain maps, so they can be compared against map
+// literals.
+type fieldMap map[string]interface{}
+
+func (m fieldMap) AddBool(k string, v bool)       { m[k] = v }
+func (m fieldMap) AddFloat64(k string, v float64) { m[k] = v }
+func (m fieldMap) AddInt(k string, v int)         { m[k] = v }
+func (m fieldMap) AddInt64(k string, v int64)     { m[k] = v }
+func (m fieldMap) AddUint(k string, v uint)       { m[k] = v }
+func (m fieldMap) AddUint64(k string, v uint64)   { m[k] = v }
+func (m fieldMap) AddUintptr(k string, v uintptr) { m[k] = v }
+func (m fieldMap) AddString(k string, v string)   { m[k] = v }
+
+func (m fieldMap) AddObject(k string, v interface{}) error {
+	m[k] = v
+	return nil
+}
+
+func (m fieldMap) AddMarshaler(k string, v zap.LogMarshaler) error {
+	nested := make(fieldMap)
+	m[k] = map[string]interface{}(nested)
+	return v.MarshalLog(nested)
+}
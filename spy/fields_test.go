@@ -0,0 +1,50 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package spy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/uber-go/zap"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldMap(t *testing.T) {
+	logger, sink := New(zap.DebugLevel)
+	logger.With(zap.String("user", "alice")).Info(
+		"hello",
+		zap.Int("count", 3),
+		zap.Error(errors.New("fail")),
+		zap.Nest("req", zap.String("path", "/"), zap.Bool("ok", true)),
+	)
+
+	logs := sink.Logs()
+	assert.Equal(t, 1, len(logs), "Expected one log.")
+	assert.Equal(t, map[string]interface{}{
+		"user":  "alice",
+		"count": 3,
+		"error": "fail",
+		"req":   map[string]interface{}{"path": "/", "ok": true},
+	}, logs[0].FieldMap(), "Unexpected field map.")
+	assert.Equal(t, map[string]interface{}{}, FieldMap(nil), "Expected an empty map for no fields.")
+}
@@ -26,7 +26,9 @@ import (
 	"github.com/uber-go/zap"
 )
 
-// A Log is an encoding-agnostic representation of a log message.
+// A Log is an encoding-agnostic representation of a log message. Its fields
+// include the logger's context, with duplicate keys resolved by
+// zap.MergeFields.
 type Log struct {
 	Level  zap.Level
 	Msg    string
@@ -74,7 +76,11 @@ type Logger struct {
 // returns the logger and its sink.
 //
 // Options can change things like log level and initial fields, but any output
-// related options will not be honored.
+// related options will not be honored. The logger respects the configured
+// level (or other LevelEnabler, like an AtomicLevel) just like a real logger:
+// Check returns nil and logging methods record nothing for disabled levels,
+// so spy loggers are faithful stand-ins for tests and benchmarks of disabled
+// logging.
 func New(options ...zap.Option) (*Logger, *Sink) {
 	s := &Sink{}
 	return &Logger{
@@ -128,8 +134,8 @@ func (l *Logger) Panic(msg string, fields ...zap.Field) {
 	l.log(zap.PanicLevel, msg, fields)
 }
 
-// Fatal logs at the Fatal level. Note that the spy logger doesn't actuall call
-// os.Exit.
+// Fatal logs at the Fatal level. Note that the spy logger doesn't actually call
+// os.Exit; to test the fatal paths of other loggers, see RecordExits.
 func (l *Logger) Fatal(msg string, fields ...zap.Field) {
 	l.log(zap.FatalLevel, msg, fields)
 }
@@ -151,8 +157,5 @@ func (l *Logger) log(lvl zap.Level, msg string, fields []zap.Field) {
 }
 
 func (l *Logger) allFields(added []zap.Field) []zap.Field {
-	all := make([]zap.Field, 0, len(added)+len(l.context))
-	all = append(all, l.context...)
-	all = append(all, added...)
-	return all
+	return zap.MergeFields(l.context, added)
 }
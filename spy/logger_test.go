@@ -0,0 +1,39 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package spy
+
+import (
+	"testing"
+
+	"github.com/uber-go/zap"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerMergesFields(t *testing.T) {
+	logger, sink := New(zap.DebugLevel)
+	logger.With(zap.String("user", "alice"), zap.Int("n", 1)).Info("hello", zap.String("user", "bob"))
+	assert.Equal(t, []Log{{
+		Level:  zap.InfoLevel,
+		Msg:    "hello",
+		Fields: []zap.Field{zap.Int("n", 1), zap.String("user", "bob")},
+	}}, sink.Logs(), "Expected log-site fields to replace context fields with the same key.")
+}
@@ -65,6 +65,12 @@ func (s *Syncer) Called() bool {
 	return s.called
 }
 
+// Reset forgets any previous calls to Sync, which makes it possible to reuse
+// the spy across multiple assertions.
+func (s *Syncer) Reset() {
+	s.called = false
+}
+
 // A Flusher is a spy for the Flush portion of zap.WriteFlusher.
 type Flusher struct {
 	err    error
@@ -88,6 +94,11 @@ func (f *Flusher) Called() bool {
 	return f.called
 }
 
+// Reset forgets any previous calls to Flush.
+func (f *Flusher) Reset() {
+	f.called = false
+}
+
 // WriteSyncer is a concrete type that implements zap.WriteSyncer.
 type WriteSyncer struct {
 	io.Writer
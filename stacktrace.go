@@ -20,7 +20,13 @@
 
 package zap
 
-import "runtime"
+import (
+	"runtime"
+	"strconv"
+)
+
+// Initial number of program counters to collect for structured stacks.
+const _initialStackDepth = 64
 
 // takeStacktrace attempts to use the provided byte slice to take a stacktrace.
 // If the provided slice isn't large enough, takeStacktrace will allocate
@@ -40,3 +46,60 @@ func takeStacktrace(buf []byte, includeAllGoroutines bool) string {
 	}
 	return string(buf[:n])
 }
+
+// A stackFrame is a single frame of a structured stacktrace.
+type stackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// stackFrames is a structured stacktrace. Reflection-based encoders (like
+// the JSON encoder) serialize it as an array of objects; encoders that format
+// objects with fmt use its compact String representation.
+type stackFrames []stackFrame
+
+func (fs stackFrames) String() string {
+	var buf []byte
+	for i, f := range fs {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+		buf = append(buf, f.Function...)
+		buf = append(buf, " ("...)
+		buf = append(buf, f.File...)
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(f.Line), 10)
+		buf = append(buf, ')')
+	}
+	return string(buf)
+}
+
+// takeStackFrames captures the current goroutine's stack, skipping the
+// supplied number of frames above its caller.
+func takeStackFrames(skip int) stackFrames {
+	// Skip runtime.Callers and takeStackFrames itself.
+	skip += 2
+	pcs := make([]uintptr, _initialStackDepth)
+	n := runtime.Callers(skip, pcs)
+	for n == len(pcs) {
+		// The stack may have been truncated, so try again with more room.
+		pcs = make([]uintptr, 2*len(pcs))
+		n = runtime.Callers(skip, pcs)
+	}
+
+	frames := runtime.CallersFrames(pcs[:n])
+	stack := make(stackFrames, 0, n)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, stackFrame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+		if !more {
+			break
+		}
+	}
+	return stack
+}
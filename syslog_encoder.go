@@ -0,0 +1,334 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// _syslogTimeFormat is RFC3339 with the microsecond precision allowed by
+	// RFC5424.
+	_syslogTimeFormat = "2006-01-02T15:04:05.999999Z07:00"
+	// _syslogMaxParamName is the maximum length of an SD-PARAM name.
+	_syslogMaxParamName = 32
+	// _syslogNil is the RFC5424 NILVALUE.
+	_syslogNil = "-"
+)
+
+var syslogPool = sync.Pool{New: func() interface{} {
+	return &syslogEncoder{
+		bytes: make([]byte, 0, _initialBufSize),
+	}
+}}
+
+// syslogEncoder is an Encoder implementation that writes RFC5424 syslog
+// messages, with the logging context encoded as a structured data element.
+type syslogEncoder struct {
+	bytes    []byte
+	prefix   string
+	sdID     string
+	facility int
+	hostname string
+	appName  string
+	procID   string
+}
+
+// NewSyslogEncoder creates an encoder that writes RFC5424 syslog messages,
+// one per line. Rather than appending the logging context to the free-form
+// message, the encoder adds it to a single SD-ELEMENT, so collectors can parse
+// the context as structured data. Nested fields are flattened into dotted
+// SD-PARAM names (e.g., "user.name"), and all values are encoded as escaped
+// strings. Line breaks in the message and in values are escaped as "\r" and
+// "\n".
+//
+// By default, messages use the "user" facility, the local hostname, the
+// process ID, no APP-NAME, and the SD-ID "zap@32473" (32473 is the private
+// enterprise number reserved for documentation).
+func NewSyslogEncoder(options ...SyslogOption) Encoder {
+	enc := syslogPool.Get().(*syslogEncoder)
+	enc.truncate()
+	enc.prefix = ""
+	enc.sdID = "zap@32473"
+	enc.facility = 1
+	enc.hostname = _syslogNil
+	if host, err := os.Hostname(); err == nil && host != "" {
+		enc.hostname = host
+	}
+	enc.appName = _syslogNil
+	enc.procID = strconv.Itoa(os.Getpid())
+	for _, opt := range options {
+		opt.apply(enc)
+	}
+	return enc
+}
+
+func (enc *syslogEncoder) Free() {
+	syslogPool.Put(enc)
+}
+
+func (enc *syslogEncoder) AddString(key, val string) {
+	enc.addKey(key)
+	enc.safeAddString(val)
+	enc.bytes = append(enc.bytes, '"')
+}
+
+func (enc *syslogEncoder) AddBool(key string, val bool) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendBool(enc.bytes, val)
+	enc.bytes = append(enc.bytes, '"')
+}
+
+func (enc *syslogEncoder) AddInt(key string, val int) {
+	enc.AddInt64(key, int64(val))
+}
+
+func (enc *syslogEncoder) AddInt64(key string, val int64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendInt(enc.bytes, val, 10)
+	enc.bytes = append(enc.bytes, '"')
+}
+
+func (enc *syslogEncoder) AddUint(key string, val uint) {
+	enc.AddUint64(key, uint64(val))
+}
+
+func (enc *syslogEncoder) AddUint64(key string, val uint64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendUint(enc.bytes, val, 10)
+	enc.bytes = append(enc.bytes, '"')
+}
+
+func (enc *syslogEncoder) AddUintptr(key string, val uintptr) {
+	enc.addKey(key)
+	enc.bytes = append(enc.bytes, "0x"...)
+	enc.bytes = strconv.AppendUint(enc.bytes, uint64(val), 16)
+	enc.bytes = append(enc.bytes, '"')
+}
+
+func (enc *syslogEncoder) AddFloat64(key string, val float64) {
+	enc.addKey(key)
+	enc.bytes = strconv.AppendFloat(enc.bytes, val, 'f', -1, 64)
+	enc.bytes = append(enc.bytes, '"')
+}
+
+// AddMarshaler flattens the marshaler's fields into the structured data
+// element, prefixing their names with the supplied key.
+func (enc *syslogEncoder) AddMarshaler(key string, obj LogMarshaler) error {
+	prefix := enc.prefix
+	enc.prefix = prefix + key + "."
+	err := obj.MarshalLog(enc)
+	enc.prefix = prefix
+	return err
+}
+
+func (enc *syslogEncoder) AddObject(key string, obj interface{}) error {
+	enc.AddString(key, fmt.Sprintf("%+v", obj))
+	return nil
+}
+
+func (enc *syslogEncoder) Clone() Encoder {
+	clone := syslogPool.Get().(*syslogEncoder)
+	clone.truncate()
+	clone.bytes = append(clone.bytes, enc.bytes...)
+	clone.prefix = enc.prefix
+	clone.sdID = enc.sdID
+	clone.facility = enc.facility
+	clone.hostname = enc.hostname
+	clone.appName = enc.appName
+	clone.procID = enc.procID
+	return clone
+}
+
+func (enc *syslogEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time.Time) error {
+	if sink == nil {
+		return errNilSink
+	}
+
+	final := syslogPool.Get().(*syslogEncoder)
+	final.truncate()
+	final.bytes = append(final.bytes, '<')
+	final.bytes = strconv.AppendInt(final.bytes, int64(enc.facility*8+syslogSeverity(lvl)), 10)
+	final.bytes = append(final.bytes, ">1 "...)
+	final.bytes = t.AppendFormat(final.bytes, _syslogTimeFormat)
+	final.bytes = append(final.bytes, ' ')
+	final.bytes = append(final.bytes, enc.hostname...)
+	final.bytes = append(final.bytes, ' ')
+	final.bytes = append(final.bytes, enc.appName...)
+	final.bytes = append(final.bytes, ' ')
+	final.bytes = append(final.bytes, enc.procID...)
+	// We don't use MSGIDs.
+	final.bytes = append(final.bytes, " - "...)
+	if len(enc.bytes) > 0 {
+		final.bytes = append(final.bytes, '[')
+		final.bytes = append(final.bytes, enc.sdID...)
+		final.bytes = append(final.bytes, enc.bytes...)
+		final.bytes = append(final.bytes, ']')
+	} else {
+		final.bytes = append(final.bytes, _syslogNil...)
+	}
+	if msg != "" {
+		final.bytes = append(final.bytes, ' ')
+		final.safeAddMsg(msg)
+	}
+	final.bytes = append(final.bytes, '\n')
+
+	expectedBytes := len(final.bytes)
+	n, err := sink.Write(final.bytes)
+	final.Free()
+	if err != nil {
+		return err
+	}
+	if n != expectedBytes {
+		return fmt.Errorf("incomplete write: only wrote %v of %v bytes", n, expectedBytes)
+	}
+	return nil
+}
+
+func (enc *syslogEncoder) truncate() {
+	enc.bytes = enc.bytes[:0]
+}
+
+// addKey appends a space, the SD-PARAM name, and the opening quote of the
+// value. Characters that aren't allowed in PARAM-NAMEs are replaced with
+// underscores, empty names are replaced with a single underscore, and names
+// are truncated to 32 bytes.
+func (enc *syslogEncoder) addKey(key string) {
+	enc.bytes = append(enc.bytes, ' ')
+	n := 0
+	for _, name := range [2]string{enc.prefix, key} {
+		for i := 0; i < len(name) && n < _syslogMaxParamName; i++ {
+			b := name[i]
+			if b <= ' ' || b >= utf8.RuneSelf || b == '=' || b == ']' || b == '"' {
+				b = '_'
+			}
+			enc.bytes = append(enc.bytes, b)
+			n++
+		}
+	}
+	if n == 0 {
+		enc.bytes = append(enc.bytes, '_')
+	}
+	enc.bytes = append(enc.bytes, '=', '"')
+}
+
+// safeAddString escapes a string as an SD-PARAM value; per RFC5424, the
+// characters '"', '\', and ']' must be escaped with a backslash. Like in the
+// MSG, carriage returns and line feeds are also escaped, so that values can't
+// break the entry across lines.
+func (enc *syslogEncoder) safeAddString(s string) {
+	for i := 0; i < len(s); i++ {
+		switch b := s[i]; b {
+		case '"', '\\', ']':
+			enc.bytes = append(enc.bytes, '\\', b)
+		case '\n':
+			enc.bytes = append(enc.bytes, '\\', 'n')
+		case '\r':
+			enc.bytes = append(enc.bytes, '\\', 'r')
+		default:
+			enc.bytes = append(enc.bytes, b)
+		}
+	}
+}
+
+// safeAddMsg appends the free-form MSG, escaping carriage returns and line
+// feeds so that each message stays on a single line.
+func (enc *syslogEncoder) safeAddMsg(msg string) {
+	for i := 0; i < len(msg); i++ {
+		switch b := msg[i]; b {
+		case '\n':
+			enc.bytes = append(enc.bytes, '\\', 'n')
+		case '\r':
+			enc.bytes = append(enc.bytes, '\\', 'r')
+		default:
+			enc.bytes = append(enc.bytes, b)
+		}
+	}
+}
+
+// syslogSeverity maps a Level to an RFC5424 severity.
+func syslogSeverity(lvl Level) int {
+	switch lvl {
+	case DebugLevel:
+		return 7
+	case InfoLevel:
+		return 6
+	case WarnLevel:
+		return 4
+	case ErrorLevel:
+		return 3
+	case PanicLevel, FatalLevel:
+		return 2
+	default:
+		if lvl < DebugLevel {
+			return 7
+		}
+		return 0
+	}
+}
+
+// A SyslogOption is used to set options for a syslog encoder.
+type SyslogOption interface {
+	apply(*syslogEncoder)
+}
+
+type syslogOptionFunc func(*syslogEncoder)
+
+func (opt syslogOptionFunc) apply(enc *syslogEncoder) {
+	opt(enc)
+}
+
+// SyslogSDID sets the SD-ID of the structured data element that holds the
+// logging context. Custom SD-IDs should take the form name@<private enterprise
+// number>.
+func SyslogSDID(id string) SyslogOption {
+	return syslogOptionFunc(func(enc *syslogEncoder) {
+		enc.sdID = id
+	})
+}
+
+// SyslogFacility sets the numeric syslog facility (0-23) used to compute each
+// message's priority.
+func SyslogFacility(facility int) SyslogOption {
+	return syslogOptionFunc(func(enc *syslogEncoder) {
+		enc.facility = facility
+	})
+}
+
+// SyslogAppName sets the APP-NAME included in each message.
+func SyslogAppName(name string) SyslogOption {
+	return syslogOptionFunc(func(enc *syslogEncoder) {
+		enc.appName = name
+	})
+}
+
+// SyslogHostname overrides the HOSTNAME included in each message.
+func SyslogHostname(name string) SyslogOption {
+	return syslogOptionFunc(func(enc *syslogEncoder) {
+		enc.hostname = name
+	})
+}
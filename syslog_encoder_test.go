@@ -0,0 +1,140 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/zap/spywrite"
+)
+
+func newSyslogEncoder(opts ...SyslogOption) *syslogEncoder {
+	return NewSyslogEncoder(opts...).(*syslogEncoder)
+}
+
+func TestSyslogEncoderFields(t *testing.T) {
+	tests := []struct {
+		desc     string
+		expected string
+		f        func(Encoder)
+	}{
+		{"string", ` k="v"`, func(e Encoder) { e.AddString("k", "v") }},
+		{"escaped string", ` k="a\"b\\c\]d"`, func(e Encoder) { e.AddString("k", `a"b\c]d`) }},
+		{"line breaks", ` k="a\r\nb"`, func(e Encoder) { e.AddString("k", "a\r\nb") }},
+		{"invalid name", ` a_b_c_d_e="v"`, func(e Encoder) { e.AddString(`a=b c]d"e`, "v") }},
+		{"empty name", ` _="v"`, func(e Encoder) { e.AddString("", "v") }},
+		{"long name", ` abcdefghijklmnopqrstuvwxyzabcdef="v"`, func(e Encoder) { e.AddString("abcdefghijklmnopqrstuvwxyzabcdefgh", "v") }},
+		{"bool", ` k="true"`, func(e Encoder) { e.AddBool("k", true) }},
+		{"int", ` k="-42"`, func(e Encoder) { e.AddInt("k", -42) }},
+		{"uint", ` k="42"`, func(e Encoder) { e.AddUint("k", 42) }},
+		{"uintptr", ` k="0xdeadbeef"`, func(e Encoder) { e.AddUintptr("k", 0xdeadbeef) }},
+		{"float64", ` k="1.5"`, func(e Encoder) { e.AddFloat64("k", 1.5) }},
+		{"marshaler", ` k.loggable="yes"`, func(e Encoder) {
+			assert.NoError(t, e.AddMarshaler("k", loggable{true}), "Unexpected error calling MarshalLog.")
+		}},
+		{"nested marshaler", ` a.b.name="phil" c="d"`, func(e Encoder) {
+			Nest("a", Marshaler("b", fakeUser{"phil"})).AddTo(e)
+			e.AddString("c", "d")
+		}},
+		{"object", ` k="map[loggable:yes\]"`, func(e Encoder) {
+			assert.NoError(t, e.AddObject("k", map[string]string{"loggable": "yes"}), "Unexpected error serializing a map.")
+		}},
+	}
+
+	for _, tt := range tests {
+		enc := newSyslogEncoder()
+		tt.f(enc)
+		assert.Equal(t, tt.expected, string(enc.bytes), "Unexpected encoder output after adding a %s.", tt.desc)
+		enc.Free()
+	}
+}
+
+func TestSyslogWriteEntry(t *testing.T) {
+	ts := time.Date(2016, time.June, 1, 12, 30, 15, 123456789, time.UTC)
+	enc := NewSyslogEncoder(SyslogHostname("myhost"), SyslogAppName("myapp"), SyslogSDID("meta@1234"))
+	pid := strconv.Itoa(os.Getpid())
+
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "no context", InfoLevel, ts), "Unexpected error writing entry.")
+	assert.Equal(t, "<14>1 2016-06-01T12:30:15.123456Z myhost myapp "+pid+" - - no context", sink.Stripped(),
+		"Unexpected output without context.")
+
+	sink.Reset()
+	enc.AddString("foo", "bar]")
+	require.NoError(t, enc.WriteEntry(sink, "with context", ErrorLevel, ts), "Unexpected error writing entry.")
+	assert.Equal(t, "<11>1 2016-06-01T12:30:15.123456Z myhost myapp "+pid+` - [meta@1234 foo="bar\]"] with context`, sink.Stripped(),
+		"Unexpected output with context.")
+
+	clone := enc.Clone()
+	sink.Reset()
+	require.NoError(t, clone.WriteEntry(sink, "", DebugLevel, ts), "Unexpected error writing entry.")
+	assert.Equal(t, "<15>1 2016-06-01T12:30:15.123456Z myhost myapp "+pid+` - [meta@1234 foo="bar\]"]`, sink.Stripped(),
+		"Unexpected output from cloned encoder.")
+
+	sink.Reset()
+	require.NoError(t, clone.WriteEntry(sink, "two\r\nlines", InfoLevel, ts), "Unexpected error writing entry.")
+	assert.Equal(t, "<14>1 2016-06-01T12:30:15.123456Z myhost myapp "+pid+` - [meta@1234 foo="bar\]"] two\r\nlines`, sink.Stripped(),
+		"Expected line breaks in the message to be escaped.")
+	assert.Equal(t, 1, len(sink.Lines()), "Expected a single line.")
+}
+
+func TestSyslogSeverities(t *testing.T) {
+	tests := []struct {
+		lvl      Level
+		expected int
+	}{
+		{DebugLevel - 1, 7},
+		{DebugLevel, 7},
+		{InfoLevel, 6},
+		{WarnLevel, 4},
+		{ErrorLevel, 3},
+		{PanicLevel, 2},
+		{FatalLevel, 2},
+		{FatalLevel + 1, 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, syslogSeverity(tt.lvl), "Unexpected syslog severity for %v.", tt.lvl)
+	}
+
+	enc := NewSyslogEncoder(SyslogFacility(16), SyslogHostname("h"))
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "msg", WarnLevel, epoch), "Unexpected error writing entry.")
+	assert.Contains(t, sink.String(), "<132>1 ", "Expected priority to include the facility.")
+}
+
+func TestSyslogWriteEntryFailure(t *testing.T) {
+	enc := NewSyslogEncoder()
+	assert.Equal(t, errNilSink, enc.WriteEntry(nil, "hello", InfoLevel, epoch), "Expected an error writing to a nil sink.")
+	assert.Error(t, enc.WriteEntry(spywrite.FailWriter{}, "hello", InfoLevel, epoch), "Expected an error when writing to sink fails.")
+	assert.Error(t, enc.WriteEntry(spywrite.ShortWriter{}, "hello", InfoLevel, epoch), "Expected an error on partial writes to sink.")
+}
+
+func TestSyslogLogger(t *testing.T) {
+	buf := &testBuffer{}
+	logger := New(NewSyslogEncoder(SyslogHostname("h")), Output(buf), Fields(String("foo", "bar")))
+	logger.Info("hello", Int("n", 1))
+	assert.Contains(t, buf.String(), `- [zap@32473 foo="bar" n="1"] hello`, "Unexpected syslog logger output.")
+}
@@ -41,6 +41,10 @@ package zap
 // An exception is made for FatalLevel and PanicLevel, where a CheckedMessage
 // is returned against the Tee itself. This is so that tlog.Check(PanicLevel,
 // ...).Write(...) is equivalent to tlog.Panic(...) (likewise for FatalLevel).
+//
+// Each sub-logger encodes the entry itself. When the sub-loggers would all
+// use the same encoder and differ only in their outputs, prefer TeeShared,
+// which encodes once.
 func Tee(logs ...Logger) Logger {
 	switch len(logs) {
 	case 0:
@@ -52,8 +56,52 @@ func Tee(logs ...Logger) Logger {
 	}
 }
 
+// TeeShared creates a Logger that encodes each entry once and writes the
+// resulting bytes to every one of the given outputs. It's the efficient
+// alternative to teeing several loggers that differ only in their
+// WriteSyncer: a Tee re-encodes the entry for every sub-logger, while
+// TeeShared pays for encoding once regardless of the fan-out.
+//
+// The returned logger is built from the supplied encoder and options, so all
+// outputs share a single level, set of hooks, and context. Any Output option
+// passed in is overridden. Use Tee when the sub-loggers need different
+// encoders or levels.
+func TeeShared(enc Encoder, outputs []WriteSyncer, options ...Option) Logger {
+	opts := make([]Option, 0, len(options)+1)
+	opts = append(opts, options...)
+	opts = append(opts, Output(MultiWriteSyncer(outputs...)))
+	return New(enc, opts...)
+}
+
 type multiLogger []Logger
 
+// Enabled returns true if any of the sub-loggers are enabled at the given
+// level.
+func (ml multiLogger) Enabled(lvl Level) bool {
+	for _, log := range ml {
+		if enabled(log, lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ml multiLogger) withMessageKey(key string) Logger {
+	clone := make(multiLogger, len(ml))
+	for i, log := range ml {
+		clone[i] = WithMessageKey(log, key)
+	}
+	return clone
+}
+
+func (ml multiLogger) zeroFields() Logger {
+	clone := make(multiLogger, len(ml))
+	for i, log := range ml {
+		clone[i] = ZeroFields(log)
+	}
+	return clone
+}
+
 func (ml multiLogger) Log(lvl Level, msg string, fields ...Field) {
 	ml.log(lvl, msg, fields)
 }
@@ -21,6 +21,8 @@
 package zap_test
 
 import (
+	"bytes"
+	"io/ioutil"
 	"testing"
 
 	"github.com/uber-go/zap"
@@ -143,3 +145,60 @@ func TestTee_Panic(t *testing.T) {
 // XXX: we cannot presently write `func TestTee_Fatal(t *testing.T)`,
 // because we can't have both a spy logger and an exit stub without a
 // dependency cycle.
+
+func TestTeeShared(t *testing.T) {
+	bufs := make([]bytes.Buffer, 3)
+	outputs := make([]zap.WriteSyncer, len(bufs))
+	for i := range bufs {
+		outputs[i] = zap.AddSync(&bufs[i])
+	}
+	log := zap.TeeShared(
+		zap.NewJSONEncoder(zap.NoTime()),
+		outputs,
+		zap.InfoLevel,
+		zap.Fields(zap.String("svc", "tee")),
+	)
+
+	log.Debug("not logged")
+	log.Info("shared", zap.Int("n", 1))
+
+	expected := `{"level":"info","msg":"shared","svc":"tee","n":1}` + "\n"
+	for i := range bufs {
+		assert.Equal(t, expected, bufs[i].String(), "Unexpected output in buffer %d.", i)
+	}
+}
+
+func discardOutputs(n int) []zap.WriteSyncer {
+	outputs := make([]zap.WriteSyncer, n)
+	for i := range outputs {
+		outputs[i] = zap.AddSync(ioutil.Discard)
+	}
+	return outputs
+}
+
+func BenchmarkTeeNaive(b *testing.B) {
+	outputs := discardOutputs(8)
+	logs := make([]zap.Logger, len(outputs))
+	for i, ws := range outputs {
+		logs[i] = zap.New(zap.NewJSONEncoder(), zap.DebugLevel, zap.Output(ws))
+	}
+	log := zap.Tee(logs...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Info("fan out", zap.Int("n", 42), zap.String("user", "jane"))
+		}
+	})
+}
+
+func BenchmarkTeeShared(b *testing.B) {
+	log := zap.TeeShared(zap.NewJSONEncoder(), discardOutputs(8), zap.DebugLevel)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Info("fan out", zap.Int("n", 42), zap.String("user", "jane"))
+		}
+	})
+}
@@ -23,7 +23,9 @@ package zap
 import (
 	"fmt"
 	"io"
+	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -38,6 +40,11 @@ type textEncoder struct {
 	bytes       []byte
 	timeFmt     string
 	firstNested bool
+	localTimes  []localTime
+	maxMsg      int
+	limit       fieldLimit
+	symbols     map[Level]string
+	colors      map[Level]string
 }
 
 // NewTextEncoder creates a line-oriented text encoder whose output is optimized
@@ -47,6 +54,10 @@ func NewTextEncoder(options ...TextOption) Encoder {
 	enc := textPool.Get().(*textEncoder)
 	enc.truncate()
 	enc.timeFmt = time.RFC3339
+	enc.localTimes = nil
+	enc.maxMsg = 0
+	enc.symbols = nil
+	enc.colors = nil
 	for _, opt := range options {
 		opt.apply(enc)
 	}
@@ -58,11 +69,17 @@ func (enc *textEncoder) Free() {
 }
 
 func (enc *textEncoder) AddString(key, val string) {
+	if !enc.limit.admit() {
+		return
+	}
 	enc.addKey(key)
 	enc.bytes = append(enc.bytes, val...)
 }
 
 func (enc *textEncoder) AddBool(key string, val bool) {
+	if !enc.limit.admit() {
+		return
+	}
 	enc.addKey(key)
 	enc.bytes = strconv.AppendBool(enc.bytes, val)
 }
@@ -72,6 +89,9 @@ func (enc *textEncoder) AddInt(key string, val int) {
 }
 
 func (enc *textEncoder) AddInt64(key string, val int64) {
+	if !enc.limit.admit() {
+		return
+	}
 	enc.addKey(key)
 	enc.bytes = strconv.AppendInt(enc.bytes, val, 10)
 }
@@ -81,26 +101,40 @@ func (enc *textEncoder) AddUint(key string, val uint) {
 }
 
 func (enc *textEncoder) AddUint64(key string, val uint64) {
+	if !enc.limit.admit() {
+		return
+	}
 	enc.addKey(key)
 	enc.bytes = strconv.AppendUint(enc.bytes, val, 10)
 }
 
 func (enc *textEncoder) AddUintptr(key string, val uintptr) {
+	if !enc.limit.admit() {
+		return
+	}
 	enc.addKey(key)
 	enc.bytes = append(enc.bytes, "0x"...)
 	enc.bytes = strconv.AppendUint(enc.bytes, uint64(val), 16)
 }
 
 func (enc *textEncoder) AddFloat64(key string, val float64) {
+	if !enc.limit.admit() {
+		return
+	}
 	enc.addKey(key)
 	enc.bytes = strconv.AppendFloat(enc.bytes, val, 'f', -1, 64)
 }
 
 func (enc *textEncoder) AddMarshaler(key string, obj LogMarshaler) error {
+	if !enc.limit.admit() {
+		return nil
+	}
 	enc.addKey(key)
 	enc.firstNested = true
 	enc.bytes = append(enc.bytes, '{')
+	enc.limit.depth++
 	err := obj.MarshalLog(enc)
+	enc.limit.depth--
 	enc.bytes = append(enc.bytes, '}')
 	enc.firstNested = false
 	return err
@@ -116,7 +150,12 @@ func (enc *textEncoder) Clone() Encoder {
 	clone.truncate()
 	clone.bytes = append(clone.bytes, enc.bytes...)
 	clone.timeFmt = enc.timeFmt
+	clone.localTimes = enc.localTimes
 	clone.firstNested = enc.firstNested
+	clone.maxMsg = enc.maxMsg
+	clone.limit = enc.limit
+	clone.symbols = enc.symbols
+	clone.colors = enc.colors
 	return clone
 }
 
@@ -129,12 +168,19 @@ func (enc *textEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time
 	final.truncate()
 	enc.addLevel(final, lvl)
 	enc.addTime(final, t)
-	enc.addMessage(final, msg)
+	truncMsg, truncated := truncateMessage(msg, enc.maxMsg)
+	enc.addMessage(final, truncMsg)
 
 	if len(enc.bytes) > 0 {
 		final.bytes = append(final.bytes, ' ')
 		final.bytes = append(final.bytes, enc.bytes...)
 	}
+	if enc.limit.dropped > 0 {
+		final.AddInt(_fieldsTruncatedKey, enc.limit.dropped)
+	}
+	if truncated {
+		final.AddInt(_truncatedLengthKey, len(msg))
+	}
 	final.bytes = append(final.bytes, '\n')
 
 	expectedBytes := len(final.bytes)
@@ -151,6 +197,7 @@ func (enc *textEncoder) WriteEntry(sink io.Writer, msg string, lvl Level, t time
 
 func (enc *textEncoder) truncate() {
 	enc.bytes = enc.bytes[:0]
+	enc.limit = fieldLimit{}
 }
 
 func (enc *textEncoder) addKey(key string) {
@@ -165,6 +212,16 @@ func (enc *textEncoder) addKey(key string) {
 }
 
 func (enc *textEncoder) addLevel(final *textEncoder, lvl Level) {
+	color, colored := enc.colors[lvl]
+	if colored {
+		final.bytes = append(final.bytes, "\x1b["...)
+		final.bytes = append(final.bytes, color...)
+		final.bytes = append(final.bytes, 'm')
+	}
+	if symbol, ok := enc.symbols[lvl]; ok {
+		final.bytes = append(final.bytes, symbol...)
+		final.bytes = append(final.bytes, ' ')
+	}
 	final.bytes = append(final.bytes, '[')
 	switch lvl {
 	case DebugLevel:
@@ -180,9 +237,16 @@ func (enc *textEncoder) addLevel(final *textEncoder, lvl Level) {
 	case FatalLevel:
 		final.bytes = append(final.bytes, 'F')
 	default:
-		final.bytes = strconv.AppendInt(final.bytes, int64(lvl), 10)
+		if name, ok := customLevelName(lvl); ok {
+			final.bytes = append(final.bytes, strings.ToUpper(name)...)
+		} else {
+			final.bytes = strconv.AppendInt(final.bytes, int64(lvl), 10)
+		}
 	}
 	final.bytes = append(final.bytes, ']')
+	if colored {
+		final.bytes = append(final.bytes, "\x1b[0m"...)
+	}
 }
 
 func (enc *textEncoder) addTime(final *textEncoder, t time.Time) {
@@ -191,6 +255,12 @@ func (enc *textEncoder) addTime(final *textEncoder, t time.Time) {
 	}
 	final.bytes = append(final.bytes, ' ')
 	final.bytes = t.AppendFormat(final.bytes, enc.timeFmt)
+	for _, lt := range enc.localTimes {
+		final.bytes = append(final.bytes, ' ')
+		final.bytes = append(final.bytes, lt.key...)
+		final.bytes = append(final.bytes, '=')
+		final.bytes = t.In(lt.loc).AppendFormat(final.bytes, enc.timeFmt)
+	}
 }
 
 func (enc *textEncoder) addMessage(final *textEncoder, msg string) {
@@ -221,3 +291,115 @@ func TextTimeFormat(layout string) TextOption {
 func TextNoTime() TextOption {
 	return TextTimeFormat("")
 }
+
+// TextLocalTime adds the entry time in the supplied location under the
+// provided key, right after the entry's timestamp. It uses the encoder's time
+// format, and it's omitted if timestamps are disabled. A nil location is
+// treated as UTC. The option may be used more than once to add several zones.
+func TextLocalTime(loc *time.Location, key string) TextOption {
+	lt := newLocalTime(loc, key)
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.localTimes = append(enc.localTimes, lt)
+	})
+}
+
+// TextMaxMessageLength truncates log messages longer than the supplied number
+// of bytes. Truncated messages end with an ellipsis, and the original length
+// is recorded under the "originalMsgLength" key. Zero or negative limits
+// disable truncation.
+func TextMaxMessageLength(max int) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.maxMsg = max
+	})
+}
+
+// TextMaxFields limits the number of fields encoded in each log entry. Once
+// the limit is reached, further fields are dropped and the number dropped is
+// recorded under the "fieldsTruncated" key. Fields added to the logger's
+// context with With count toward the limit (and are always encoded before the
+// fields passed at the log site), but fields nested inside a LogMarshaler
+// don't. Zero or negative limits disable the check.
+func TextMaxFields(max int) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.limit.max = max
+	})
+}
+
+// DefaultLevelSymbols returns the default mapping of levels to symbols for use
+// with TextLevelSymbols. Each call returns a new map, so callers may modify
+// the result.
+func DefaultLevelSymbols() map[Level]string {
+	return map[Level]string{
+		DebugLevel: "·",
+		InfoLevel:  "✔",
+		WarnLevel:  "⚠",
+		ErrorLevel: "✖",
+		PanicLevel: "✖",
+		FatalLevel: "✖",
+	}
+}
+
+// TextLevelSymbols prefixes each log entry with a symbol (e.g., a glyph like
+// ✔ or ⚠) chosen by the entry's level, which makes output easier to scan.
+// Levels missing from the map don't get a symbol. See DefaultLevelSymbols for
+// a reasonable starting point.
+func TextLevelSymbols(symbols map[Level]string) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		enc.symbols = copyLevelStrings(symbols)
+	})
+}
+
+// DefaultLevelColors returns the default mapping of levels to ANSI colors for
+// use with TextLevelColors. Each call returns a new map, so callers may modify
+// the result.
+func DefaultLevelColors() map[Level]string {
+	return map[Level]string{
+		DebugLevel: "35",   // magenta
+		InfoLevel:  "34",   // blue
+		WarnLevel:  "33",   // yellow
+		ErrorLevel: "31",   // red
+		PanicLevel: "1;31", // bold red
+		FatalLevel: "1;31", // bold red
+	}
+}
+
+// TextLevelColors colors the level (and symbol, if any) of each log entry.
+// Colors are specified as ANSI SGR parameters (e.g., "31" for red or "1;31"
+// for bold red), and levels missing from the map aren't colored.
+//
+// Since escape sequences are noise in files and pipes, colors are only
+// enabled if the supplied output is a terminal (more precisely, an *os.File
+// that's a character device). Typically, the output is os.Stdout or
+// os.Stderr.
+func TextLevelColors(colors map[Level]string, output io.Writer) TextOption {
+	return textOptionFunc(func(enc *textEncoder) {
+		if !isTerminal(output) {
+			enc.colors = nil
+			return
+		}
+		enc.colors = copyLevelStrings(colors)
+	})
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func copyLevelStrings(m map[Level]string) map[Level]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[Level]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -23,11 +23,14 @@ package zap
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/uber-go/zap/spywrite"
 )
 
@@ -141,6 +144,41 @@ func TestTextWriteEntry(t *testing.T) {
 	}
 }
 
+func TestTextMaxMessageLength(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextMaxMessageLength(4))
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[I] hell... originalMsgLength=5", sink.Stripped(), "Unexpected output for truncated message.")
+
+	sink.Reset()
+	enc.AddString("foo", "bar")
+	require.NoError(t, enc.Clone().WriteEntry(sink, "hi", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[I] hi foo=bar", sink.Stripped(), "Unexpected output for short message.")
+}
+
+func TestTextLocalTime(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	enc := NewTextEncoder(TextTimeFormat(time.RFC3339), TextLocalTime(tokyo, "tokyo"))
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "hello", InfoLevel, epoch.UTC()), "Unexpected error writing entry.")
+	assert.Equal(t, "[I] 1970-01-01T00:00:00Z tokyo=1970-01-01T09:00:00+09:00 hello", sink.Stripped(), "Unexpected output with local time.")
+
+	sink.Reset()
+	enc = NewTextEncoder(TextNoTime(), TextLocalTime(nil, "utc"))
+	require.NoError(t, enc.WriteEntry(sink, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[I] hello", sink.Stripped(), "Expected TextNoTime to omit local times.")
+}
+
+func TestTextMaxFields(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime(), TextMaxFields(1))
+	enc.AddString("foo", "bar")
+	enc.AddUintptr("dropped", 0xdeadbeef)
+	enc.AddBool("alsoDropped", true)
+	sink := &testBuffer{}
+	require.NoError(t, enc.WriteEntry(sink, "hello", InfoLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[I] hello foo=bar fieldsTruncated=2", sink.Stripped(), "Unexpected output when exceeding max fields.")
+}
+
 func TestTextWriteEntryLevels(t *testing.T) {
 	tests := []struct {
 		level    Level
@@ -169,6 +207,64 @@ func TestTextWriteEntryLevels(t *testing.T) {
 	}
 }
 
+func TestTextWriteEntryCustomLevel(t *testing.T) {
+	withCustomLevels(t, func() {
+		notice := FatalLevel + 1
+		require.NoError(t, RegisterLevel(notice, "notice"), "Unexpected error registering a custom level.")
+
+		sink := &testBuffer{}
+		enc := NewTextEncoder(TextNoTime())
+		require.NoError(t, enc.WriteEntry(sink, "Fake message.", notice, epoch), "Unexpected failure writing entry.")
+		assert.Equal(t, "[NOTICE] Fake message.", sink.Stripped(), "Expected the registered name in text output.")
+	})
+}
+
+func TestTextLevelSymbols(t *testing.T) {
+	symbols := DefaultLevelSymbols()
+	delete(symbols, DebugLevel)
+	enc := NewTextEncoder(TextNoTime(), TextLevelSymbols(symbols))
+	symbols[InfoLevel] = "changed"
+
+	sink := &testBuffer{}
+	for _, lvl := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel} {
+		require.NoError(t, enc.Clone().WriteEntry(sink, "msg", lvl, epoch), "Unexpected error writing entry.")
+	}
+	assert.Equal(t, []string{"[D] msg", "✔ [I] msg", "⚠ [W] msg", "✖ [E] msg"}, sink.Lines(),
+		"Unexpected output with level symbols.")
+}
+
+func TestTextLevelColors(t *testing.T) {
+	f, err := ioutil.TempFile("", "zap-colors")
+	require.NoError(t, err, "Failed to create temp file.")
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	require.NoError(t, err, "Failed to open null device.")
+	defer devNull.Close()
+
+	tests := []struct {
+		output   io.Writer
+		expected []string
+	}{
+		{&testBuffer{}, []string{"✔ [I] msg", "[D] msg"}},
+		{f, []string{"✔ [I] msg", "[D] msg"}},
+		{devNull, []string{"\x1b[34m✔ [I]\x1b[0m msg", "[D] msg"}},
+	}
+
+	for _, tt := range tests {
+		symbols, colors := DefaultLevelSymbols(), DefaultLevelColors()
+		delete(symbols, DebugLevel)
+		delete(colors, DebugLevel)
+		enc := NewTextEncoder(TextNoTime(), TextLevelSymbols(symbols), TextLevelColors(colors, tt.output))
+
+		sink := &testBuffer{}
+		require.NoError(t, enc.WriteEntry(sink, "msg", InfoLevel, epoch), "Unexpected error writing entry.")
+		require.NoError(t, enc.WriteEntry(sink, "msg", DebugLevel, epoch), "Unexpected error writing entry.")
+		assert.Equal(t, tt.expected, sink.Lines(), "Unexpected output with level colors and output %T.", tt.output)
+	}
+}
+
 func TestTextClone(t *testing.T) {
 	parent := &textEncoder{bytes: make([]byte, 0, 128)}
 	clone := parent.Clone()
@@ -0,0 +1,61 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "time"
+
+// Timers records a sequence of named durations, which is handy for simple
+// in-function profiling. Each call to Mark records the time elapsed since the
+// previous mark (or since the Timers were started), and Field groups all the
+// recorded durations into a single nested object.
+//
+// Timers aren't safe for concurrent use.
+type Timers struct {
+	last  time.Time
+	names []string
+	durs  []time.Duration
+}
+
+// StartTimers creates Timers, starting the clock for the first mark.
+func StartTimers() *Timers {
+	return &Timers{last: _timeNow()}
+}
+
+// Mark records the time elapsed since the previous mark under the supplied
+// name, and restarts the clock.
+func (t *Timers) Mark(name string) {
+	now := _timeNow()
+	t.names = append(t.names, name)
+	t.durs = append(t.durs, now.Sub(t.last))
+	t.last = now
+}
+
+// Field constructs a field that nests the recorded durations under the
+// supplied key, in the order they were marked. Like Duration, each duration
+// is represented as an integer number of nanoseconds. The field captures the
+// durations marked so far, so later marks don't affect it.
+func (t *Timers) Field(key string) Field {
+	fields := make([]Field, len(t.names))
+	for i := range t.names {
+		fields[i] = Duration(t.names[i], t.durs[i])
+	}
+	return Nest(key, fields...)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+// _validationErrorsKey is the key used by FieldErrors.
+const _validationErrorsKey = "validationErrors"
+
+// A ValidationError describes a problem with a single field of some input,
+// like an API request. Path identifies the field (e.g., "user.emails[0]");
+// an empty path refers to the input as a whole. ValidationErrors implement
+// the error interface.
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// FieldError constructs a ValidationError for the field at path.
+func FieldError(path, message string) ValidationError {
+	return ValidationError{Path: path, Message: message}
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// validationErrors is a list of field errors. Reflection-based encoders (like
+// the JSON encoder) serialize it as an array of {"path", "message"} objects;
+// encoders that format objects with fmt use its String representation.
+type validationErrors []ValidationError
+
+func (errs validationErrors) String() string {
+	var buf []byte
+	for i, e := range errs {
+		if i > 0 {
+			buf = append(buf, "; "...)
+		}
+		buf = append(buf, e.Error()...)
+	}
+	return string(buf)
+}
+
+// FieldErrors constructs a Field that stores a list of validation failures
+// under the key "validationErrors". The JSON encoder renders them as an array
+// of objects with "path" and "message" keys, which log platforms can index:
+//
+//	logger.Warn("invalid request", zap.FieldErrors(
+//		zap.FieldError("name", "required"),
+//		zap.FieldError("age", "must be positive"),
+//	))
+//
+// If there are no errors, the field is a no-op. The slice isn't copied, so it
+// must not be modified until the field is marshaled.
+func FieldErrors(errs ...ValidationError) Field {
+	if len(errs) == 0 {
+		return Skip()
+	}
+	return Object(_validationErrorsKey, validationErrors(errs))
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationErrorMessage(t *testing.T) {
+	assert.Equal(t, "name: required", FieldError("name", "required").Error(), "Unexpected error message.")
+	assert.Equal(t, "body is empty", FieldError("", "body is empty").Error(), "Expected empty paths to be omitted.")
+}
+
+func TestFieldErrorsField(t *testing.T) {
+	assertFieldJSON(t, ``, FieldErrors())
+	assertFieldJSON(t, ``, FieldErrors([]ValidationError(nil)...))
+	assertFieldJSON(
+		t,
+		`"validationErrors":[{"path":"name","message":"required"},{"path":"","message":"too large"}]`,
+		FieldErrors(FieldError("name", "required"), FieldError("", "too large")),
+	)
+	assertCanBeReused(t, FieldErrors(FieldError("name", "required")))
+}
+
+func TestFieldErrorsText(t *testing.T) {
+	enc := NewTextEncoder(TextNoTime())
+	FieldErrors(FieldError("name", "required"), FieldError("", "too large")).AddTo(enc)
+	sink := &testBuffer{}
+	assert.NoError(t, enc.WriteEntry(sink, "invalid", WarnLevel, epoch), "Unexpected error writing entry.")
+	assert.Equal(t, "[W] invalid validationErrors=name: required; too large", sink.Stripped(), "Unexpected text output.")
+}
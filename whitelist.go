@@ -0,0 +1,114 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+// Whitelist wraps a logger so that it drops every field whose key isn't in
+// the allowed set, which is useful for sinks (e.g., for compliance) that must
+// only persist approved data. Fields are filtered both at the log site and
+// when they're added with With; only top-level keys are checked, so allowed
+// nested objects are passed through whole. The structured context of an
+// allowed Error field (see FieldsError) is only logged if its "Fields" key
+// (e.g., "errorFields") is allowed too.
+//
+// Context that was added to the underlying logger before wrapping it (e.g.,
+// with the Fields option) has already been serialized, so it can't be
+// filtered.
+func Whitelist(log Logger, allowedKeys ...string) Logger {
+	allowed := make(map[string]struct{}, len(allowedKeys))
+	for _, k := range allowedKeys {
+		allowed[k] = struct{}{}
+	}
+	return &whitelist{log: log, allowed: allowed}
+}
+
+type whitelist struct {
+	log     Logger
+	allowed map[string]struct{}
+}
+
+func (w *whitelist) With(fields ...Field) Logger {
+	return &whitelist{
+		log:     w.log.With(w.filter(fields)...),
+		allowed: w.allowed,
+	}
+}
+
+func (w *whitelist) Check(lvl Level, msg string) *CheckedMessage {
+	return wrapCheckedMessage(w, w.log.Check(lvl, msg), func(cm *CheckedMessage, fields []Field) {
+		cm.Write(w.filter(fields)...)
+	})
+}
+
+func (w *whitelist) Log(lvl Level, msg string, fields ...Field) {
+	w.log.Log(lvl, msg, w.filter(fields)...)
+}
+
+func (w *whitelist) Debug(msg string, fields ...Field) {
+	w.log.Debug(msg, w.filter(fields)...)
+}
+
+func (w *whitelist) Info(msg string, fields ...Field) {
+	w.log.Info(msg, w.filter(fields)...)
+}
+
+func (w *whitelist) Warn(msg string, fields ...Field) {
+	w.log.Warn(msg, w.filter(fields)...)
+}
+
+func (w *whitelist) Error(msg string, fields ...Field) {
+	w.log.Error(msg, w.filter(fields)...)
+}
+
+func (w *whitelist) Panic(msg string, fields ...Field) {
+	w.log.Panic(msg, w.filter(fields)...)
+}
+
+func (w *whitelist) Fatal(msg string, fields ...Field) {
+	w.log.Fatal(msg, w.filter(fields)...)
+}
+
+func (w *whitelist) DFatal(msg string, fields ...Field) {
+	w.log.DFatal(msg, w.filter(fields)...)
+}
+
+func (w *whitelist) Close() error {
+	return Close(w.log)
+}
+
+func (w *whitelist) filter(fields []Field) []Field {
+	filtered := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		if _, ok := w.allowed[f.key]; !ok {
+			continue
+		}
+		if f.fieldType == errorType {
+			if _, ok := w.allowed[f.key+"Fields"]; !ok {
+				f.obj = errorWithoutFields{f.obj.(error)}
+			}
+		}
+		filtered = append(filtered, f)
+	}
+	return filtered
+}
+
+// errorWithoutFields hides an error's FieldsError implementation (and any
+// errors it wraps), so that the Error field only logs its message.
+type errorWithoutFields struct{ error }
@@ -0,0 +1,74 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhitelist(t *testing.T) {
+	inner, sink := spy.New(zap.DebugLevel)
+	log := zap.Whitelist(inner, "user", "status")
+
+	log.With(zap.String("user", "alice"), zap.String("ssn", "123-45-6789")).Info("login", zap.Int("status", 200), zap.String("password", "hunter2"))
+	log.Check(zap.WarnLevel, "checked").Write(zap.String("token", "secret"), zap.Nest("user", zap.String("email", "a@example.com")))
+	log.Error("error", zap.String("secret", "foo"))
+
+	assert.Equal(t, []spy.Log{
+		{Level: zap.InfoLevel, Msg: "login", Fields: []zap.Field{zap.String("user", "alice"), zap.Int("status", 200)}},
+		{Level: zap.WarnLevel, Msg: "checked", Fields: []zap.Field{zap.Nest("user", zap.String("email", "a@example.com"))}},
+		{Level: zap.ErrorLevel, Msg: "error", Fields: []zap.Field{}},
+	}, sink.Logs(), "Expected only allowed fields to be logged.")
+}
+
+func TestWhitelistCheckDisabled(t *testing.T) {
+	inner, _ := spy.New(zap.WarnLevel)
+	assert.Nil(t, zap.Whitelist(inner).Check(zap.InfoLevel, "disabled"), "Expected disabled levels to return nil.")
+}
+
+type codedError struct{ code int }
+
+func (e codedError) Error() string { return "failed" }
+
+func (e codedError) Fields() []zap.Field {
+	return []zap.Field{zap.Int("code", e.code), zap.String("secret", "hunter2")}
+}
+
+func TestWhitelistFieldsError(t *testing.T) {
+	buf := &bytes.Buffer{}
+	inner := zap.New(zap.NewJSONEncoder(zap.NoTime()), zap.Output(zap.AddSync(buf)))
+
+	zap.Whitelist(inner, "error").Info("denied", zap.Error(codedError{503}))
+	zap.Whitelist(inner, "error", "errorFields").Info("allowed", zap.Error(codedError{503}))
+
+	assert.Equal(t,
+		`{"level":"info","msg":"denied","error":"failed"}`+"\n"+
+			`{"level":"info","msg":"allowed","error":"failed","errorFields":{"code":503,"secret":"hunter2"}}`+"\n",
+		buf.String(),
+		"Expected an error's fields to be logged only if they're allowed.",
+	)
+}
@@ -22,8 +22,11 @@ package zap
 
 import (
 	"bytes"
+	"compress/gzip"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
 	"sync"
 )
 
@@ -80,11 +83,68 @@ func (s *lockedWriteSyncer) Write(bs []byte) (int, error) {
 
 func (s *lockedWriteSyncer) Sync() error {
 	s.Lock()
-	err := s.ws.Sync()
+	err := syncWriteSyncer(s.ws)
 	s.Unlock()
 	return err
 }
 
+// Close closes the wrapped WriteSyncer without syncing it, since closeSyncer
+// has already synced it through the lock.
+func (s *lockedWriteSyncer) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.ws.(*os.File); ok {
+		return nil
+	}
+	if c, ok := s.ws.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// closeSyncer syncs the WriteSyncer and then, if it implements io.Closer,
+// closes it. Files are only synced, since they're often shared (e.g.,
+// os.Stdout and os.Stderr).
+func closeSyncer(ws WriteSyncer) error {
+	if ws == nil {
+		return nil
+	}
+	var errs multiError
+	if err := syncWriteSyncer(ws); err != nil {
+		errs = append(errs, err)
+	}
+	if _, ok := ws.(*os.File); ok {
+		return errs.asError()
+	}
+	if c, ok := ws.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.asError()
+}
+
+// syncWriteSyncer syncs the WriteSyncer, ignoring the errors returned when
+// syncing files that don't support it, like pipes and terminals (which
+// os.Stdout and os.Stderr usually are).
+func syncWriteSyncer(ws WriteSyncer) error {
+	err := ws.Sync()
+	if err == nil {
+		return nil
+	}
+	f, ok := ws.(*os.File)
+	if !ok {
+		return err
+	}
+	if pathErr, ok := err.(*os.PathError); !ok || !isSyncUnsupported(pathErr.Err) {
+		return err
+	}
+	if info, statErr := f.Stat(); statErr == nil && !info.Mode().IsRegular() {
+		return nil
+	}
+	return err
+}
+
 type writerWrapper struct {
 	io.Writer
 }
@@ -101,6 +161,295 @@ func (f flusherWrapper) Sync() error {
 	return f.Flush()
 }
 
+// GzipWriteSyncer creates a WriteSyncer that gzip-compresses everything
+// written to it before passing it along to the supplied WriteSyncer. Invalid
+// compression levels fall back to gzip.DefaultCompression.
+//
+// Calling Sync flushes the compressed stream and then syncs the underlying
+// WriteSyncer. Since each flush ends the current compression block, frequent
+// syncing noticeably hurts the compression ratio. The returned WriteSyncer
+// also implements io.Closer; Close writes the gzip footer, and must be called
+// to produce a complete archive. It then syncs the underlying WriteSyncer,
+// and closes it if it's an io.Closer other than a file.
+func GzipWriteSyncer(ws WriteSyncer, level int) WriteSyncer {
+	gz, err := gzip.NewWriterLevel(ws, level)
+	if err != nil {
+		gz = gzip.NewWriter(ws)
+	}
+	return &gzipWriteSyncer{gz: gz, ws: ws}
+}
+
+type gzipWriteSyncer struct {
+	gz *gzip.Writer
+	ws WriteSyncer
+}
+
+func (g *gzipWriteSyncer) Write(bs []byte) (int, error) {
+	return g.gz.Write(bs)
+}
+
+func (g *gzipWriteSyncer) Sync() error {
+	if err := g.gz.Flush(); err != nil {
+		return err
+	}
+	return g.ws.Sync()
+}
+
+func (g *gzipWriteSyncer) Close() error {
+	if err := g.gz.Close(); err != nil {
+		return err
+	}
+	return closeSyncer(g.ws)
+}
+
+// LimitedWriteSyncer creates a WriteSyncer that drops any single write larger
+// than max bytes. Since loggers write each encoded entry with a single call to
+// Write, this caps the size of individual log entries; oversized entries are
+// dropped entirely (rather than truncated, which would produce malformed
+// records), and the returned error is reported to the logger's ErrorOutput.
+func LimitedWriteSyncer(ws WriteSyncer, max int) WriteSyncer {
+	return limitedWriteSyncer{ws: ws, max: max}
+}
+
+type limitedWriteSyncer struct {
+	ws  WriteSyncer
+	max int
+}
+
+func (l limitedWriteSyncer) Write(bs []byte) (int, error) {
+	if len(bs) > l.max {
+		return 0, fmt.Errorf("dropped %v-byte entry exceeding the limit of %v bytes", len(bs), l.max)
+	}
+	return l.ws.Write(bs)
+}
+
+func (l limitedWriteSyncer) Sync() error {
+	return l.ws.Sync()
+}
+
+func (l limitedWriteSyncer) Close() error {
+	return closeSyncer(l.ws)
+}
+
+// A ReplayWriteSyncer buffers writes in memory until it's activated, then
+// replays them to the real destination and forwards all subsequent writes.
+// It's useful for logging during application startup, before the
+// configuration that determines the log destination has been loaded.
+//
+// To bound memory usage if it's never activated, the ReplayWriteSyncer stops
+// buffering once it holds maxBytes of data; subsequent writes are dropped
+// (whole) until activation. ReplayWriteSyncers are safe for concurrent use.
+type ReplayWriteSyncer struct {
+	sync.Mutex
+
+	buf     bytes.Buffer
+	max     int
+	dropped int
+	ws      WriteSyncer
+}
+
+// NewReplayWriteSyncer creates an inactive ReplayWriteSyncer that buffers at
+// most maxBytes of data.
+func NewReplayWriteSyncer(maxBytes int) *ReplayWriteSyncer {
+	return &ReplayWriteSyncer{max: maxBytes}
+}
+
+// Write buffers the data if the ReplayWriteSyncer is inactive, and writes it
+// to the destination otherwise.
+func (r *ReplayWriteSyncer) Write(bs []byte) (int, error) {
+	r.Lock()
+	defer r.Unlock()
+	if r.ws != nil {
+		return r.ws.Write(bs)
+	}
+	if r.buf.Len()+len(bs) > r.max {
+		r.dropped++
+		return len(bs), nil
+	}
+	return r.buf.Write(bs)
+}
+
+// Sync syncs the destination. It's a no-op if the ReplayWriteSyncer is
+// inactive.
+func (r *ReplayWriteSyncer) Sync() error {
+	r.Lock()
+	defer r.Unlock()
+	if r.ws == nil {
+		return nil
+	}
+	return syncWriteSyncer(r.ws)
+}
+
+// Activate replays the buffered data to the supplied WriteSyncer, then
+// forwards all subsequent writes to it. It returns an error if the replay
+// fails, or if any writes were dropped because the buffer was full. Calling
+// Activate more than once switches destinations without replaying anything.
+func (r *ReplayWriteSyncer) Activate(ws WriteSyncer) error {
+	r.Lock()
+	defer r.Unlock()
+	r.ws = ws
+	var errs multiError
+	if r.buf.Len() > 0 {
+		if _, err := ws.Write(r.buf.Bytes()); err != nil {
+			errs = append(errs, err)
+		}
+		if err := syncWriteSyncer(ws); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if r.dropped > 0 {
+		errs = append(errs, fmt.Errorf("dropped %v writes exceeding the %v-byte replay buffer", r.dropped, r.max))
+	}
+	// Release the buffer's memory.
+	r.buf = bytes.Buffer{}
+	r.dropped = 0
+	return errs.asError()
+}
+
+// A TailWriteSyncer passes writes through to another WriteSyncer, while also
+// keeping a copy of the most recent output in a fixed-size in-memory ring
+// buffer. It's useful for including the last few kilobytes of logs in crash
+// reports. TailWriteSyncers are safe for concurrent use.
+type TailWriteSyncer struct {
+	sync.Mutex
+
+	ws   WriteSyncer
+	ring []byte
+	pos  int
+	full bool
+}
+
+// NewTailWriteSyncer creates a TailWriteSyncer that writes to the supplied
+// WriteSyncer and retains the last size bytes written.
+func NewTailWriteSyncer(ws WriteSyncer, size int) *TailWriteSyncer {
+	return &TailWriteSyncer{
+		ws:   ws,
+		ring: make([]byte, size),
+	}
+}
+
+// Write records the data in the ring buffer, then writes it to the underlying
+// WriteSyncer. Data is recorded even if the underlying write fails.
+func (t *TailWriteSyncer) Write(bs []byte) (int, error) {
+	t.Lock()
+	defer t.Unlock()
+	t.record(bs)
+	return t.ws.Write(bs)
+}
+
+// Sync syncs the underlying WriteSyncer.
+func (t *TailWriteSyncer) Sync() error {
+	t.Lock()
+	defer t.Unlock()
+	return t.ws.Sync()
+}
+
+// Close syncs the underlying WriteSyncer, and closes it if it's an io.Closer
+// other than a file. The tail remains available after closing.
+func (t *TailWriteSyncer) Close() error {
+	t.Lock()
+	defer t.Unlock()
+	return closeSyncer(t.ws)
+}
+
+// Tail returns a copy of the most recently written data, oldest first. Since
+// the ring buffer has a fixed size, the first entry in the tail is likely to
+// be incomplete.
+func (t *TailWriteSyncer) Tail() []byte {
+	t.Lock()
+	defer t.Unlock()
+	if !t.full {
+		return append([]byte(nil), t.ring[:t.pos]...)
+	}
+	tail := make([]byte, 0, len(t.ring))
+	tail = append(tail, t.ring[t.pos:]...)
+	return append(tail, t.ring[:t.pos]...)
+}
+
+func (t *TailWriteSyncer) record(bs []byte) {
+	size := len(t.ring)
+	if size == 0 {
+		return
+	}
+	if len(bs) >= size {
+		copy(t.ring, bs[len(bs)-size:])
+		t.pos, t.full = 0, true
+		return
+	}
+	n := copy(t.ring[t.pos:], bs)
+	if n < len(bs) {
+		t.pos = copy(t.ring, bs[n:])
+		t.full = true
+		return
+	}
+	t.pos += n
+	if t.pos == size {
+		t.pos, t.full = 0, true
+	}
+}
+
+// A HeaderWriteSyncer writes a header (e.g., a byte order mark or a row of
+// column names) to another WriteSyncer before the first write. It's useful
+// for exports to tools, like spreadsheets, that expect such headers.
+//
+// There's no file rotation built into this package. If the underlying
+// destination is rotated, call Reset to re-emit the header at the top of each
+// new file. HeaderWriteSyncers are safe for concurrent use.
+type HeaderWriteSyncer struct {
+	sync.Mutex
+
+	ws      WriteSyncer
+	header  []byte
+	written bool
+}
+
+// NewHeaderWriteSyncer creates a HeaderWriteSyncer that writes the supplied
+// header to ws before the first write.
+func NewHeaderWriteSyncer(ws WriteSyncer, header []byte) *HeaderWriteSyncer {
+	return &HeaderWriteSyncer{
+		ws:     ws,
+		header: append([]byte(nil), header...),
+	}
+}
+
+// Write writes the header if it hasn't been written yet, then the data. If
+// writing the header fails, the data isn't written, and the header is retried
+// on the next write.
+func (h *HeaderWriteSyncer) Write(bs []byte) (int, error) {
+	h.Lock()
+	defer h.Unlock()
+	if !h.written {
+		if _, err := h.ws.Write(h.header); err != nil {
+			return 0, err
+		}
+		h.written = true
+	}
+	return h.ws.Write(bs)
+}
+
+// Sync syncs the underlying WriteSyncer.
+func (h *HeaderWriteSyncer) Sync() error {
+	h.Lock()
+	defer h.Unlock()
+	return h.ws.Sync()
+}
+
+// Close syncs the underlying WriteSyncer, and closes it if it's an io.Closer
+// other than a file.
+func (h *HeaderWriteSyncer) Close() error {
+	h.Lock()
+	defer h.Unlock()
+	return closeSyncer(h.ws)
+}
+
+// Reset makes the HeaderWriteSyncer write the header again before the next
+// write (for example, after the underlying file has been rotated).
+func (h *HeaderWriteSyncer) Reset() {
+	h.Lock()
+	h.written = false
+	h.Unlock()
+}
+
 // MultiWriteSyncer creates a WriteSyncer that duplicates its writes
 // and sync calls, similarly to to io.MultiWriter.
 func MultiWriteSyncer(ws ...WriteSyncer) WriteSyncer {
@@ -129,6 +478,18 @@ func (ws multiWriteSyncer) Write(p []byte) (int, error) {
 	return nWritten, errs.asError()
 }
 
+// Close syncs and closes all the WriteSyncers, except for files, which are
+// only synced.
+func (ws multiWriteSyncer) Close() error {
+	var errs multiError
+	for _, w := range ws {
+		if err := closeSyncer(w); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.asError()
+}
+
 func (ws multiWriteSyncer) Sync() error {
 	return wrapMultiError(ws...)
 }
@@ -22,7 +22,11 @@ package zap
 
 import (
 	"bytes"
+	"compress/gzip"
 	"errors"
+	"io"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -162,6 +166,185 @@ func TestMultiWriteSyncerSync_AllCalled(t *testing.T) {
 	assert.True(t, second.Called(), "Expected call even with first failure")
 }
 
+func TestGzipWriteSyncer(t *testing.T) {
+	for _, level := range []int{gzip.BestSpeed, gzip.BestCompression, 42} {
+		sink := &spywrite.WriteSyncer{Writer: &bytes.Buffer{}}
+		ws := GzipWriteSyncer(sink, level)
+
+		logger := New(NewJSONEncoder(NoTime()), Output(ws))
+		logger.Info("foo")
+		require.NoError(t, ws.Sync(), "Unexpected error syncing gzip WriteSyncer.")
+		assert.True(t, sink.Called(), "Expected Sync to sync the underlying WriteSyncer.")
+		logger.Info("bar")
+		require.NoError(t, ws.(io.Closer).Close(), "Unexpected error closing gzip WriteSyncer.")
+
+		r, err := gzip.NewReader(sink.Writer.(*bytes.Buffer))
+		require.NoError(t, err, "Expected valid gzip output with level %v.", level)
+		out, err := ioutil.ReadAll(r)
+		require.NoError(t, err, "Unexpected error decompressing output.")
+		assert.Equal(t, `{"level":"info","msg":"foo"}`+"\n"+`{"level":"info","msg":"bar"}`+"\n", string(out),
+			"Unexpected decompressed output with level %v.", level)
+	}
+}
+
+func TestGzipWriteSyncerSyncFailure(t *testing.T) {
+	sink := &spywrite.WriteSyncer{Writer: spywrite.FailWriter{}}
+	ws := GzipWriteSyncer(sink, gzip.DefaultCompression)
+	ws.Write([]byte("foo"))
+	assert.Error(t, ws.Sync(), "Expected flush errors to propagate.")
+	assert.False(t, sink.Called(), "Didn't expect to sync after a failed flush.")
+}
+
+func TestLimitedWriteSyncer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	concrete := &spywrite.WriteSyncer{Writer: buf}
+	ws := LimitedWriteSyncer(concrete, 3)
+	requireWriteWorks(t, ws)
+
+	n, err := ws.Write([]byte("toolong"))
+	assert.Error(t, err, "Expected an error writing more than the limit.")
+	assert.Equal(t, 0, n, "Expected oversized writes to be dropped.")
+	assert.Equal(t, "foo", buf.String(), "Unexpected output after oversized write.")
+
+	require.NoError(t, ws.Sync(), "Unexpected error syncing a WriteSyncer.")
+	assert.True(t, concrete.Called(), "Expected to dispatch to underlying Sync method.")
+}
+
+func TestLimitedWriteSyncerLogger(t *testing.T) {
+	buf := &testBuffer{}
+	errBuf := &testBuffer{}
+	logger := New(NewJSONEncoder(NoTime()), Output(LimitedWriteSyncer(buf, 40)), ErrorOutput(errBuf))
+
+	logger.Info("short")
+	logger.Info("a much longer message that won't fit")
+	assert.Equal(t, `{"level":"info","msg":"short"}`, buf.Stripped(), "Expected only the short entry to be written.")
+	assert.Contains(t, errBuf.String(), "exceeding the limit of 40 bytes", "Expected dropped entry to be reported.")
+}
+
+func TestReplayWriteSyncer(t *testing.T) {
+	r := NewReplayWriteSyncer(1024)
+	logger := New(NewJSONEncoder(NoTime()), Output(r))
+	logger.Info("early")
+	assert.NoError(t, r.Sync(), "Unexpected error syncing an inactive ReplayWriteSyncer.")
+
+	buf := &bytes.Buffer{}
+	sink := &spywrite.WriteSyncer{Writer: buf}
+	require.NoError(t, r.Activate(sink), "Unexpected error activating ReplayWriteSyncer.")
+	assert.True(t, sink.Called(), "Expected replay to sync the destination.")
+	assert.Equal(t, `{"level":"info","msg":"early"}`+"\n", buf.String(), "Expected buffered entries to be replayed.")
+
+	logger.Info("late")
+	assert.Equal(t, `{"level":"info","msg":"early"}`+"\n"+`{"level":"info","msg":"late"}`+"\n", buf.String(),
+		"Expected writes after activation to be forwarded.")
+
+	sink.Reset()
+	require.NoError(t, r.Sync(), "Unexpected error syncing an active ReplayWriteSyncer.")
+	assert.True(t, sink.Called(), "Expected Sync to sync the destination once active.")
+}
+
+func TestReplayWriteSyncerFull(t *testing.T) {
+	r := NewReplayWriteSyncer(5)
+	for _, s := range []string{"foo", "bar", "b"} {
+		n, err := r.Write([]byte(s))
+		require.NoError(t, err, "Unexpected error writing to an inactive ReplayWriteSyncer.")
+		assert.Equal(t, len(s), n, "Unexpected number of bytes written.")
+	}
+
+	buf := &bytes.Buffer{}
+	err := r.Activate(AddSync(buf))
+	assert.Error(t, err, "Expected an error reporting dropped writes.")
+	assert.Contains(t, err.Error(), "dropped 1 writes", "Unexpected error reporting dropped writes.")
+	assert.Equal(t, "foob", buf.String(), "Expected writes that fit in the buffer to be replayed.")
+}
+
+func TestReplayWriteSyncerFailedReplay(t *testing.T) {
+	r := NewReplayWriteSyncer(1024)
+	r.Write([]byte("foo"))
+	assert.Error(t, r.Activate(AddSync(spywrite.FailWriter{})), "Expected replay errors to propagate.")
+}
+
+func TestReplayWriteSyncerPipe(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	require.NoError(t, err, "Failed to create pipe.")
+	defer pr.Close()
+	defer pw.Close()
+	go ioutil.ReadAll(pr)
+
+	r := NewReplayWriteSyncer(1024)
+	r.Write([]byte("foo"))
+	require.Error(t, pw.Sync(), "Expected syncing a pipe to fail.")
+	assert.NoError(t, r.Activate(pw), "Expected unsupported syncs of pipes to be ignored on replay.")
+	assert.NoError(t, r.Sync(), "Expected unsupported syncs of pipes to be ignored.")
+}
+
+func TestTailWriteSyncer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sink := &spywrite.WriteSyncer{Writer: buf}
+	tail := NewTailWriteSyncer(sink, 8)
+	assert.Equal(t, []byte{}, tail.Tail(), "Expected empty tail before any writes.")
+
+	tests := []struct {
+		write    string
+		expected string
+	}{
+		{"foo", "foo"},
+		{"bar", "foobar"},
+		{"ba", "foobarba"},
+		{"z", "oobarbaz"},
+		{"quux", "rbazquux"},
+		{"0123456789", "23456789"},
+		{"ab", "456789ab"},
+	}
+	for _, tt := range tests {
+		n, err := tail.Write([]byte(tt.write))
+		require.NoError(t, err, "Unexpected error writing to TailWriteSyncer.")
+		assert.Equal(t, len(tt.write), n, "Unexpected number of bytes written.")
+		assert.Equal(t, tt.expected, string(tail.Tail()), "Unexpected tail after writing %q.", tt.write)
+	}
+	assert.Equal(t, "foobarbazquux0123456789ab", buf.String(), "Expected all writes to pass through.")
+
+	require.NoError(t, tail.Sync(), "Unexpected error syncing TailWriteSyncer.")
+	assert.True(t, sink.Called(), "Expected Sync to sync the underlying WriteSyncer.")
+}
+
+func TestTailWriteSyncerFailedWrite(t *testing.T) {
+	tail := NewTailWriteSyncer(AddSync(spywrite.FailWriter{}), 8)
+	_, err := tail.Write([]byte("foo"))
+	assert.Error(t, err, "Expected write errors to propagate.")
+	assert.Equal(t, "foo", string(tail.Tail()), "Expected failed writes to be recorded.")
+}
+
+func TestTailWriteSyncerZeroSize(t *testing.T) {
+	tail := NewTailWriteSyncer(AddSync(&bytes.Buffer{}), 0)
+	tail.Write([]byte("foo"))
+	assert.Equal(t, []byte{}, tail.Tail(), "Expected zero-size tail to stay empty.")
+}
+
+func TestHeaderWriteSyncer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	header := []byte("level,msg\n")
+	h := NewHeaderWriteSyncer(AddSync(buf), header)
+	header[0] = 'X'
+	assert.Equal(t, "", buf.String(), "Expected header to wait for the first write.")
+
+	h.Write([]byte("info,foo\n"))
+	h.Write([]byte("info,bar\n"))
+	assert.Equal(t, "level,msg\ninfo,foo\ninfo,bar\n", buf.String(), "Expected header to be written once.")
+
+	buf.Reset()
+	h.Reset()
+	h.Write([]byte("info,baz\n"))
+	assert.Equal(t, "level,msg\ninfo,baz\n", buf.String(), "Expected header to be re-emitted after Reset.")
+}
+
+func TestHeaderWriteSyncerFailure(t *testing.T) {
+	h := NewHeaderWriteSyncer(AddSync(spywrite.FailWriter{}), []byte("header\n"))
+	n, err := h.Write([]byte("foo"))
+	assert.Error(t, err, "Expected header write errors to propagate.")
+	assert.Equal(t, 0, n, "Expected data not to be written when the header fails.")
+	assert.NoError(t, h.Sync(), "Unexpected error syncing.")
+}
+
 type syncSpy struct {
 	bytes.Buffer
 	spywrite.Syncer
@@ -0,0 +1,255 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zcloudwatch
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits imposed by the PutLogEvents API.
+const (
+	_maxBatchEvents = 10000
+	_maxBatchBytes  = 1048576
+	_eventOverhead  = 26
+	_maxEventBytes  = 262144 - _eventOverhead
+	_maxBatchSpan   = 24 * time.Hour
+)
+
+const (
+	// Stop buffering if CloudWatch is unavailable for long enough to
+	// accumulate this many events.
+	_maxBufferedEvents = 10 * _maxBatchEvents
+	_minBackoff        = 100 * time.Millisecond
+	_maxBackoff        = 30 * time.Second
+)
+
+var _timeNow = time.Now // for tests
+
+// An Event is a single CloudWatch log event.
+type Event struct {
+	// Timestamp is the event time in milliseconds since the epoch.
+	Timestamp int64
+	Message   string
+}
+
+// A Client sends a batch of events to a CloudWatch log stream, mirroring the
+// PutLogEvents API. The sequence token is nil for the first call to a new log
+// stream. On success, the client returns the token to use for the next batch.
+//
+// Clients should report throttling with a ThrottlingError and rejected
+// sequence tokens with an InvalidSequenceTokenError.
+type Client interface {
+	PutLogEvents(group, stream string, events []Event, sequenceToken *string) (nextSequenceToken *string, err error)
+}
+
+// A ThrottlingError indicates that CloudWatch rejected a batch because the
+// caller exceeded its rate limit.
+type ThrottlingError struct {
+	Err error
+}
+
+func (e ThrottlingError) Error() string {
+	return fmt.Sprintf("throttled by CloudWatch Logs: %v", e.Err)
+}
+
+// An InvalidSequenceTokenError indicates that CloudWatch rejected a batch
+// because the sequence token was stale. CloudWatch includes the expected token
+// in its response.
+type InvalidSequenceTokenError struct {
+	ExpectedSequenceToken *string
+	Err                   error
+}
+
+func (e InvalidSequenceTokenError) Error() string {
+	return fmt.Sprintf("invalid CloudWatch Logs sequence token: %v", e.Err)
+}
+
+// A WriteSyncer batches log entries and delivers them to a CloudWatch log
+// stream. Each call to Write is treated as a single event, which matches the
+// way zap loggers write entries.
+//
+// Batches are sent when they reach CloudWatch's size limits, when the oldest
+// buffered entry is older than the flush interval, and on Sync. When a request
+// fails (most often because CloudWatch throttled it), the WriteSyncer keeps the
+// batch, backs off exponentially, and returns the error so that the logger
+// reports it to its ErrorOutput. If CloudWatch is unavailable long enough for
+// 100,000 entries to accumulate, subsequent entries are dropped.
+//
+// WriteSyncers are safe for concurrent use.
+type WriteSyncer struct {
+	sync.Mutex
+
+	client   Client
+	group    string
+	stream   string
+	interval time.Duration
+
+	token        *string
+	pending      []Event
+	pendingBytes int
+	backoff      time.Duration
+	retryAt      time.Time
+}
+
+// New creates a WriteSyncer that delivers entries to the given log group and
+// stream, sending a batch at least once per flush interval while logging.
+func New(client Client, group, stream string, flushInterval time.Duration) *WriteSyncer {
+	return &WriteSyncer{
+		client:   client,
+		group:    group,
+		stream:   stream,
+		interval: flushInterval,
+	}
+}
+
+// Write buffers a single event, flushing the buffer if necessary. Events
+// larger than CloudWatch's limit are truncated, without splitting a UTF-8
+// encoded rune.
+func (w *WriteSyncer) Write(bs []byte) (int, error) {
+	n := len(bs)
+	bs = bytes.TrimRight(bs, "\n")
+	if len(bs) > _maxEventBytes {
+		bs = bs[:runeBoundary(bs, _maxEventBytes)]
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	if len(w.pending) >= _maxBufferedEvents {
+		return 0, fmt.Errorf("dropped CloudWatch Logs event: %v events already buffered", len(w.pending))
+	}
+	now := _timeNow()
+	w.pending = append(w.pending, Event{
+		Timestamp: now.UnixNano() / int64(time.Millisecond),
+		Message:   string(bs),
+	})
+	w.pendingBytes += len(bs) + _eventOverhead
+
+	// While backing off, the error is reported by Sync rather than by every
+	// Write.
+	if !now.Before(w.retryAt) && w.shouldFlush(now) {
+		if err := w.flush(now); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Sync sends all buffered events. If the WriteSyncer is backing off after a
+// failed request, it doesn't send anything, but returns an error if any events
+// are still buffered.
+func (w *WriteSyncer) Sync() error {
+	w.Lock()
+	defer w.Unlock()
+	return w.flush(_timeNow())
+}
+
+func (w *WriteSyncer) shouldFlush(now time.Time) bool {
+	if len(w.pending) >= _maxBatchEvents || w.pendingBytes >= _maxBatchBytes {
+		return true
+	}
+	oldest := time.Unix(0, w.pending[0].Timestamp*int64(time.Millisecond))
+	return now.Sub(oldest) >= w.interval
+}
+
+func (w *WriteSyncer) flush(now time.Time) error {
+	if now.Before(w.retryAt) {
+		// Still backing off; keep buffering.
+		if len(w.pending) > 0 {
+			return fmt.Errorf("can't send %v buffered CloudWatch Logs events: backing off until %v", len(w.pending), w.retryAt)
+		}
+		return nil
+	}
+	for len(w.pending) > 0 {
+		n := w.batchLen()
+		if err := w.put(w.pending[:n]); err != nil {
+			// Whatever the failure, don't retry on every Write.
+			w.backOff(now)
+			return err
+		}
+		w.backoff = 0
+		for _, e := range w.pending[:n] {
+			w.pendingBytes -= len(e.Message) + _eventOverhead
+		}
+		w.pending = w.pending[n:]
+	}
+	// Release the backing array.
+	w.pending = nil
+	return nil
+}
+
+// put sends a batch, retrying once if the sequence token was stale.
+func (w *WriteSyncer) put(batch []Event) error {
+	token, err := w.client.PutLogEvents(w.group, w.stream, batch, w.token)
+	if tokenErr, ok := err.(InvalidSequenceTokenError); ok {
+		w.token = tokenErr.ExpectedSequenceToken
+		token, err = w.client.PutLogEvents(w.group, w.stream, batch, w.token)
+	}
+	if err != nil {
+		return err
+	}
+	w.token = token
+	return nil
+}
+
+// batchLen returns the number of pending events that fit in a single
+// PutLogEvents call.
+func (w *WriteSyncer) batchLen() int {
+	first := w.pending[0].Timestamp
+	size := 0
+	for i, e := range w.pending {
+		size += len(e.Message) + _eventOverhead
+		span := time.Duration(e.Timestamp-first) * time.Millisecond
+		if i == _maxBatchEvents || size > _maxBatchBytes || span > _maxBatchSpan {
+			return i
+		}
+	}
+	return len(w.pending)
+}
+
+// runeBoundary returns the largest index no greater than max that doesn't
+// fall in the middle of a UTF-8 encoded rune. It gives up after utf8.UTFMax
+// bytes, so invalid UTF-8 is cut at max.
+func runeBoundary(bs []byte, max int) int {
+	for i := max; i > max-utf8.UTFMax && i > 0; i-- {
+		if utf8.RuneStart(bs[i]) {
+			return i
+		}
+	}
+	return max
+}
+
+func (w *WriteSyncer) backOff(now time.Time) {
+	switch {
+	case w.backoff == 0:
+		w.backoff = _minBackoff
+	case w.backoff < _maxBackoff:
+		w.backoff *= 2
+	}
+	if w.backoff > _maxBackoff {
+		w.backoff = _maxBackoff
+	}
+	w.retryAt = now.Add(w.backoff)
+}
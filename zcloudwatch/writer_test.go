@@ -0,0 +1,220 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zcloudwatch
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/uber-go/zap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	batches [][]Event
+	tokens  []*string
+	errs    []error
+}
+
+func (c *fakeClient) PutLogEvents(group, stream string, events []Event, token *string) (*string, error) {
+	c.tokens = append(c.tokens, token)
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	c.batches = append(c.batches, append([]Event(nil), events...))
+	next := strconv.Itoa(len(c.batches))
+	return &next, nil
+}
+
+func (c *fakeClient) messages() []string {
+	var msgs []string
+	for _, b := range c.batches {
+		for _, e := range b {
+			msgs = append(msgs, e.Message)
+		}
+	}
+	return msgs
+}
+
+func stubNow(t time.Time) func() {
+	prev := _timeNow
+	_timeNow = func() time.Time { return t }
+	return func() { _timeNow = prev }
+}
+
+func str(s string) *string { return &s }
+
+func TestWriteSyncerBatchesUntilSync(t *testing.T) {
+	defer stubNow(time.Unix(1, 0))()
+	client := &fakeClient{}
+	ws := New(client, "group", "stream", time.Minute)
+	logger := zap.New(zap.NewJSONEncoder(zap.NoTime()), zap.Output(ws))
+
+	logger.Info("one")
+	logger.Info("two")
+	assert.Empty(t, client.batches, "Expected entries to be buffered.")
+
+	require.NoError(t, ws.Sync(), "Unexpected error syncing.")
+	require.Len(t, client.batches, 1, "Expected a single batch.")
+	assert.Equal(t, []Event{
+		{Timestamp: 1000, Message: `{"level":"info","msg":"one"}`},
+		{Timestamp: 1000, Message: `{"level":"info","msg":"two"}`},
+	}, client.batches[0], "Unexpected batch contents.")
+
+	require.NoError(t, ws.Sync(), "Unexpected error syncing.")
+	assert.Len(t, client.batches, 1, "Expected no batch when nothing is buffered.")
+}
+
+func TestWriteSyncerFlushInterval(t *testing.T) {
+	restore := stubNow(time.Unix(1, 0))
+	client := &fakeClient{}
+	ws := New(client, "group", "stream", time.Second)
+	ws.Write([]byte("one\n"))
+	restore()
+
+	defer stubNow(time.Unix(2, 0))()
+	ws.Write([]byte("two\n"))
+	assert.Equal(t, []string{"one", "two"}, client.messages(), "Expected writes after the flush interval to flush.")
+}
+
+func TestWriteSyncerSequenceTokens(t *testing.T) {
+	client := &fakeClient{
+		errs: []error{nil, InvalidSequenceTokenError{ExpectedSequenceToken: str("expected"), Err: errors.New("stale")}},
+	}
+	ws := New(client, "group", "stream", time.Minute)
+	ws.Write([]byte("one"))
+	require.NoError(t, ws.Sync(), "Unexpected error syncing.")
+	ws.Write([]byte("two"))
+	require.NoError(t, ws.Sync(), "Expected stale sequence tokens to be retried.")
+	ws.Write([]byte("three"))
+	require.NoError(t, ws.Sync(), "Unexpected error syncing.")
+
+	assert.Equal(t, []*string{nil, str("1"), str("expected"), str("2")}, client.tokens, "Unexpected sequence tokens.")
+	assert.Equal(t, []string{"one", "two", "three"}, client.messages(), "Unexpected messages.")
+}
+
+func TestWriteSyncerThrottling(t *testing.T) {
+	restore := stubNow(time.Unix(1, 0))
+	client := &fakeClient{
+		errs: []error{ThrottlingError{errors.New("slow down")}, ThrottlingError{errors.New("slow down")}},
+	}
+	ws := New(client, "group", "stream", time.Minute)
+
+	ws.Write([]byte("one"))
+	err := ws.Sync()
+	require.Error(t, err, "Expected throttling errors to be returned.")
+	assert.Contains(t, err.Error(), "throttled", "Unexpected error message.")
+	err = ws.Sync()
+	require.Error(t, err, "Expected an error syncing with events buffered while backing off.")
+	assert.Contains(t, err.Error(), "1 buffered CloudWatch Logs events", "Unexpected error message.")
+	assert.Len(t, client.tokens, 1, "Expected no requests while backing off.")
+	restore()
+
+	restore = stubNow(time.Unix(1, 0).Add(_minBackoff))
+	assert.Error(t, ws.Sync(), "Expected second throttling error.")
+	assert.Equal(t, 2*_minBackoff, ws.backoff, "Expected exponential backoff.")
+	restore()
+
+	defer stubNow(time.Unix(2, 0))()
+	ws.Write([]byte("two"))
+	require.NoError(t, ws.Sync(), "Unexpected error after backoff.")
+	assert.Equal(t, []string{"one", "two"}, client.messages(), "Expected throttled events to be retried.")
+	assert.Equal(t, time.Duration(0), ws.backoff, "Expected backoff to reset after success.")
+}
+
+func TestWriteSyncerBacksOffOnOtherErrors(t *testing.T) {
+	restore := stubNow(time.Unix(1, 0))
+	client := &fakeClient{errs: []error{errors.New("connection reset")}}
+	ws := New(client, "group", "stream", 0)
+
+	_, err := ws.Write([]byte("one"))
+	assert.Error(t, err, "Expected the failed request to be reported.")
+	_, err = ws.Write([]byte("two"))
+	assert.NoError(t, err, "Expected no requests while backing off.")
+	assert.Len(t, client.tokens, 1, "Expected no requests while backing off.")
+	restore()
+
+	defer stubNow(time.Unix(2, 0))()
+	require.NoError(t, ws.Sync(), "Unexpected error after backoff.")
+	assert.Equal(t, []string{"one", "two"}, client.messages(), "Expected the failed batch to be retried.")
+}
+
+func TestWriteSyncerThrottlingReportedToErrorOutput(t *testing.T) {
+	client := &fakeClient{errs: []error{ThrottlingError{errors.New("slow down")}}}
+	ws := New(client, "group", "stream", 0)
+	errOut := &strings.Builder{}
+	logger := zap.New(zap.NewJSONEncoder(zap.NoTime()), zap.Output(ws), zap.ErrorOutput(zap.AddSync(errOut)))
+	logger.Info("one")
+	assert.Contains(t, errOut.String(), "throttled by CloudWatch Logs", "Expected throttling to be reported.")
+}
+
+func TestWriteSyncerBatchLimits(t *testing.T) {
+	client := &fakeClient{}
+	ws := New(client, "group", "stream", time.Hour)
+	for i := 0; i < _maxBatchEvents+1; i++ {
+		ws.Write([]byte("x"))
+	}
+	require.Len(t, client.batches, 1, "Expected a full batch to flush.")
+	assert.Len(t, client.batches[0], _maxBatchEvents, "Unexpected batch size.")
+
+	big := strings.Repeat("x", _maxEventBytes+10)
+	for i := 0; i < 4; i++ {
+		ws.Write([]byte(big))
+	}
+	// The fourth event pushes the buffer over the size limit, so we send two
+	// batches.
+	require.Len(t, client.batches, 3, "Expected buffer to flush when it exceeds the size limit.")
+	assert.Len(t, client.batches[1], 4, "Expected the first batch to include all the events that fit.")
+	assert.Len(t, client.batches[2], 1, "Expected the remaining event in a second batch.")
+	for _, e := range client.batches[1][1:] {
+		assert.Equal(t, _maxEventBytes, len(e.Message), "Expected oversized events to be truncated.")
+	}
+}
+
+func TestWriteSyncerTruncatesAtRuneBoundary(t *testing.T) {
+	client := &fakeClient{}
+	ws := New(client, "group", "stream", time.Hour)
+	// The three-byte rune straddles the size limit.
+	ws.Write([]byte(strings.Repeat("x", _maxEventBytes-1) + "€"))
+	require.NoError(t, ws.Sync(), "Unexpected error syncing.")
+
+	msg := client.messages()[0]
+	assert.Equal(t, _maxEventBytes-1, len(msg), "Expected the partial rune to be dropped.")
+	assert.True(t, utf8.ValidString(msg), "Expected truncated events to be valid UTF-8.")
+}
+
+func TestWriteSyncerBatchSpan(t *testing.T) {
+	client := &fakeClient{}
+	ws := New(client, "group", "stream", 48*time.Hour)
+	ws.pending = []Event{{Timestamp: 0}, {Timestamp: int64(25 * time.Hour / time.Millisecond)}}
+	require.NoError(t, ws.Sync(), "Unexpected error syncing.")
+	assert.Len(t, client.batches, 2, "Expected batches not to span more than 24 hours.")
+}
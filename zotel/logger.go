@@ -0,0 +1,225 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zotel
+
+import (
+	"io"
+	"time"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/zwrap"
+)
+
+// Keys of the fields that carry trace context. Rather than being included in
+// a record's attributes, string fields with these keys populate its TraceID
+// and SpanID.
+const (
+	TraceIDKey = "traceID"
+	SpanIDKey  = "spanID"
+)
+
+// OpenTelemetry severity numbers for each zap level. OpenTelemetry reserves a
+// range of four numbers for each severity; zap's levels map to the start of
+// each range, except for PanicLevel, which is a more severe error.
+const (
+	SeverityDebug = 5
+	SeverityInfo  = 9
+	SeverityWarn  = 13
+	SeverityError = 17
+	SeverityPanic = 19
+	SeverityFatal = 21
+)
+
+var _timeNow = time.Now // for tests
+
+// A Record is a log entry in the OpenTelemetry log data model.
+type Record struct {
+	// TimeUnixNano is the entry's time in nanoseconds since the epoch.
+	TimeUnixNano   int64
+	SeverityNumber int
+	// SeverityText is the zap level's name (e.g., "info").
+	SeverityText string
+	Body         string
+	// Attributes holds the logger's context and the fields added at the log
+	// site, rendered as by zwrap.KeyValueMap.
+	Attributes map[string]interface{}
+	TraceID    string
+	SpanID     string
+}
+
+// An Exporter delivers records to an OpenTelemetry pipeline. Export is called
+// synchronously by the logging goroutine, so it should be fast and must be
+// safe for concurrent use. If the Exporter also implements io.Closer, closing
+// the logger closes it.
+type Exporter interface {
+	Export(Record) error
+}
+
+// Severity returns the OpenTelemetry severity number for a zap level.
+func Severity(lvl zap.Level) int {
+	switch lvl {
+	case zap.DebugLevel:
+		return SeverityDebug
+	case zap.InfoLevel:
+		return SeverityInfo
+	case zap.WarnLevel:
+		return SeverityWarn
+	case zap.ErrorLevel:
+		return SeverityError
+	case zap.PanicLevel:
+		return SeverityPanic
+	case zap.FatalLevel:
+		return SeverityFatal
+	}
+	if lvl < zap.DebugLevel {
+		return SeverityDebug
+	}
+	return SeverityFatal
+}
+
+// Logger is a zap.Logger that converts each entry to a Record and passes it to
+// an Exporter. Errors returned by the Exporter are reported to the logger's
+// ErrorOutput. Like other loggers, Panic and Fatal still panic and exit.
+//
+// Options that configure the encoder or output (including Fields) aren't
+// honored; use With to add context instead. Hooks (AddCaller, AddStacks, etc.)
+// run as they do for loggers created by zap.New, and fields they add become
+// attributes.
+type Logger struct {
+	zap.Meta
+
+	exporter Exporter
+	context  []zap.Field
+}
+
+// New constructs a Logger that exports records to the supplied Exporter.
+func New(exporter Exporter, options ...zap.Option) *Logger {
+	return &Logger{
+		Meta:     zap.MakeMeta(zap.NullEncoder(), options...),
+		exporter: exporter,
+	}
+}
+
+// With creates a child logger that shares the parent's Exporter.
+func (l *Logger) With(fields ...zap.Field) zap.Logger {
+	context := make([]zap.Field, 0, len(l.context)+len(fields))
+	context = append(context, l.context...)
+	context = append(context, fields...)
+	return &Logger{
+		Meta:     l.Meta,
+		exporter: l.exporter,
+		context:  context,
+	}
+}
+
+// Check returns a CheckedMessage if logging a message at the specified level
+// is enabled.
+func (l *Logger) Check(lvl zap.Level, msg string) *zap.CheckedMessage {
+	return l.Meta.Check(l, lvl, msg)
+}
+
+// Log exports a message at the specified level.
+func (l *Logger) Log(lvl zap.Level, msg string, fields ...zap.Field) {
+	l.log(lvl, msg, fields)
+}
+
+// Debug exports a message at the Debug level.
+func (l *Logger) Debug(msg string, fields ...zap.Field) {
+	l.log(zap.DebugLevel, msg, fields)
+}
+
+// Info exports a message at the Info level.
+func (l *Logger) Info(msg string, fields ...zap.Field) {
+	l.log(zap.InfoLevel, msg, fields)
+}
+
+// Warn exports a message at the Warn level.
+func (l *Logger) Warn(msg string, fields ...zap.Field) {
+	l.log(zap.WarnLevel, msg, fields)
+}
+
+// Error exports a message at the Error level.
+func (l *Logger) Error(msg string, fields ...zap.Field) {
+	l.log(zap.ErrorLevel, msg, fields)
+}
+
+// Panic exports a message at the Panic level, then panics.
+func (l *Logger) Panic(msg string, fields ...zap.Field) {
+	l.log(zap.PanicLevel, msg, fields)
+	panic(msg)
+}
+
+// Fatal exports a message at the Fatal level, then calls zap.Exit(1).
+func (l *Logger) Fatal(msg string, fields ...zap.Field) {
+	l.log(zap.FatalLevel, msg, fields)
+	zap.Exit(1)
+}
+
+// DFatal behaves like Fatal if the logger is in development mode, and like
+// Error otherwise.
+func (l *Logger) DFatal(msg string, fields ...zap.Field) {
+	if l.Development {
+		l.Fatal(msg, fields...)
+		return
+	}
+	l.Error(msg, fields...)
+}
+
+// Close closes the Exporter if it implements io.Closer.
+func (l *Logger) Close() error {
+	if c, ok := l.exporter.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (l *Logger) log(lvl zap.Level, msg string, fields []zap.Field) {
+	if !l.Meta.Enabled(lvl) {
+		return
+	}
+	attrs := make(zwrap.KeyValueMap, len(l.context)+len(fields))
+	entry, ok := l.RunHooks(lvl, msg, zap.MergeFields(l.context, fields), attrs)
+	if !ok {
+		return
+	}
+	rec := Record{
+		TimeUnixNano:   _timeNow().UnixNano(),
+		SeverityNumber: Severity(entry.Level),
+		SeverityText:   entry.Level.String(),
+		Body:           entry.Message,
+		Attributes:     attrs,
+	}
+	rec.TraceID = takeString(attrs, TraceIDKey)
+	rec.SpanID = takeString(attrs, SpanIDKey)
+	if err := l.exporter.Export(rec); err != nil {
+		l.InternalError("export", err)
+	}
+}
+
+// takeString removes and returns the string attribute with the given key.
+// Non-string values are left in place.
+func takeString(attrs zwrap.KeyValueMap, key string) string {
+	s, ok := attrs[key].(string)
+	if ok {
+		delete(attrs, key)
+	}
+	return s
+}
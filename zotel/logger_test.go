@@ -0,0 +1,175 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zotel
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	sync.Mutex
+	records []Record
+	err     error
+	closed  bool
+}
+
+func (r *recorder) Export(rec Record) error {
+	r.Lock()
+	defer r.Unlock()
+	r.records = append(r.records, rec)
+	return r.err
+}
+
+func (r *recorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func stubNow(t time.Time) func() {
+	prev := _timeNow
+	_timeNow = func() time.Time { return t }
+	return func() { _timeNow = prev }
+}
+
+func TestLoggerExportsRecords(t *testing.T) {
+	defer stubNow(time.Unix(1, 500))()
+	exp := &recorder{}
+	log := New(exp, zap.InfoLevel).With(
+		zap.String(TraceIDKey, "4bf92f3577b34da6a3ce929d0e0e4736"),
+		zap.String("service", "api"),
+	)
+
+	log.Debug("disabled")
+	log.Warn("slow request", zap.String(SpanIDKey, "00f067aa0ba902b7"), zap.Int("status", 200))
+
+	require.Equal(t, 1, len(exp.records), "Expected a single exported record.")
+	assert.Equal(t, Record{
+		TimeUnixNano:   1000000500,
+		SeverityNumber: SeverityWarn,
+		SeverityText:   "warn",
+		Body:           "slow request",
+		Attributes:     map[string]interface{}{"service": "api", "status": 200},
+		TraceID:        "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:         "00f067aa0ba902b7",
+	}, exp.records[0], "Unexpected record.")
+}
+
+func TestLoggerNonStringTraceID(t *testing.T) {
+	exp := &recorder{}
+	New(exp).Info("hello", zap.Int(TraceIDKey, 42))
+	require.Equal(t, 1, len(exp.records), "Expected a single exported record.")
+	assert.Equal(t, "", exp.records[0].TraceID, "Expected non-string trace IDs to be ignored.")
+	assert.Equal(t, 42, exp.records[0].Attributes[TraceIDKey], "Expected non-string trace IDs to remain attributes.")
+}
+
+func TestLoggerHooks(t *testing.T) {
+	exp := &recorder{}
+	drop := zap.Hook(func(e *zap.Entry) error {
+		if e.Message == "dropped" {
+			return zap.ErrDropEntry
+		}
+		return nil
+	})
+	tag := zap.FieldHook(func(e *zap.Entry, fields []zap.Field) error {
+		zap.Int("fields", len(fields)).AddTo(e.Fields())
+		return nil
+	})
+	log := New(exp, drop, tag, zap.AddCaller()).With(zap.String("service", "api"))
+
+	log.Info("dropped")
+	_, _, line, _ := runtime.Caller(0)
+	log.Info("hello", zap.Int("n", 1))
+
+	require.Equal(t, 1, len(exp.records), "Expected hooks to be able to drop entries.")
+	rec := exp.records[0]
+	assert.Equal(t, fmt.Sprintf("logger_test.go:%d: hello", line+1), rec.Body, "Expected AddCaller to annotate the body.")
+	assert.Equal(t, map[string]interface{}{"service": "api", "n": 1, "fields": 2}, rec.Attributes, "Expected fields added by hooks to become attributes.")
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		lvl      zap.Level
+		expected int
+	}{
+		{zap.DebugLevel - 1, SeverityDebug},
+		{zap.DebugLevel, SeverityDebug},
+		{zap.InfoLevel, SeverityInfo},
+		{zap.WarnLevel, SeverityWarn},
+		{zap.ErrorLevel, SeverityError},
+		{zap.PanicLevel, SeverityPanic},
+		{zap.FatalLevel, SeverityFatal},
+		{zap.FatalLevel + 1, SeverityFatal},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Severity(tt.lvl), "Unexpected severity for level %v.", tt.lvl)
+	}
+}
+
+func TestLoggerExportErrors(t *testing.T) {
+	errOut := &bytes.Buffer{}
+	exp := &recorder{err: errors.New("unavailable")}
+	New(exp, zap.ErrorOutput(zap.AddSync(errOut))).Info("hello")
+	assert.Contains(t, errOut.String(), "export error: unavailable", "Expected export errors to be reported.")
+}
+
+func TestLoggerPanicFatal(t *testing.T) {
+	exp := &recorder{}
+	log := New(exp)
+	assert.Panics(t, func() { log.Panic("boom") }, "Expected Panic to panic.")
+
+	exits, restore := spy.RecordExits()
+	defer restore()
+	log.Fatal("fatal")
+	assert.Equal(t, []int{1}, exits.Codes(), "Expected Fatal to exit through zap.Exit.")
+
+	require.Equal(t, 2, len(exp.records), "Expected both entries to be exported.")
+	assert.Equal(t, SeverityPanic, exp.records[0].SeverityNumber, "Unexpected severity for Panic.")
+	assert.Equal(t, SeverityFatal, exp.records[1].SeverityNumber, "Unexpected severity for Fatal.")
+}
+
+func TestLoggerClose(t *testing.T) {
+	exp := &recorder{}
+	require.NoError(t, zap.Close(New(exp)), "Unexpected error closing logger.")
+	assert.True(t, exp.closed, "Expected the exporter to be closed.")
+}
+
+func TestLoggerCheck(t *testing.T) {
+	exp := &recorder{}
+	log := New(exp, zap.WarnLevel)
+	assert.Nil(t, log.Check(zap.InfoLevel, "disabled"), "Expected disabled levels to return nil.")
+	if cm := log.Check(zap.ErrorLevel, "enabled"); assert.True(t, cm.OK(), "Expected enabled levels to be OK.") {
+		cm.Write(zap.Bool("checked", true))
+	}
+	require.Equal(t, 1, len(exp.records), "Expected a single exported record.")
+	assert.Equal(t, map[string]interface{}{"checked": true}, exp.records[0].Attributes, "Unexpected attributes.")
+}
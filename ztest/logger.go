@@ -0,0 +1,84 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ztest
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/uber-go/zap"
+)
+
+// NewLogger constructs a logger that writes human-readable entries to the
+// test's log via t.Log, so output is interleaved with (and attributed to) the
+// test that produced it. By default, the logger writes all levels; the
+// supplied options are applied after the defaults, so they can override the
+// level and add context.
+func NewLogger(t testing.TB, options ...zap.Option) zap.Logger {
+	opts := make([]zap.Option, 0, 2+len(options))
+	opts = append(opts, zap.DebugLevel, zap.Output(NewWriter(t)))
+	opts = append(opts, options...)
+	return zap.New(zap.NewTextEncoder(zap.TextNoTime()), opts...)
+}
+
+// NewWriter constructs a WriteSyncer that writes each call to Write as a line
+// in the test's log. Once the test completes, the writer silently discards
+// output, so it's safe to use from goroutines that outlive the test.
+func NewWriter(t testing.TB) zap.WriteSyncer {
+	w := &writer{t: t}
+	if c, ok := t.(cleaner); ok {
+		c.Cleanup(w.stop)
+	}
+	return w
+}
+
+type cleaner interface {
+	Cleanup(func())
+}
+
+type writer struct {
+	sync.Mutex
+
+	t    testing.TB
+	done bool
+}
+
+func (w *writer) Write(bs []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.done {
+		return len(bs), nil
+	}
+	// The testing package adds its own newlines.
+	w.t.Log(string(bytes.TrimRight(bs, "\n")))
+	return len(bs), nil
+}
+
+func (w *writer) Sync() error {
+	return nil
+}
+
+func (w *writer) stop() {
+	w.Lock()
+	w.done = true
+	w.Unlock()
+}
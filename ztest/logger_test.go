@@ -0,0 +1,97 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ztest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/uber-go/zap"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTB records calls to Log and Cleanup. Embedding testing.TB satisfies the
+// interface's unexported method.
+type fakeTB struct {
+	testing.TB
+	sync.Mutex
+
+	logs     []string
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.Lock()
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+	f.Unlock()
+}
+
+func (f *fakeTB) Log(args ...interface{}) {
+	f.Lock()
+	f.logs = append(f.logs, fmt.Sprint(args...))
+	f.Unlock()
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeTB) finish() {
+	for _, fn := range f.cleanups {
+		fn()
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	tb := &fakeTB{}
+	logger := NewLogger(tb, zap.Fields(zap.String("test", "yes")))
+	logger.Debug("debug")
+	logger.With(zap.Int("n", 1)).Info("info")
+	assert.Equal(t, []string{
+		"[D] debug test=yes",
+		"[I] info test=yes n=1",
+	}, tb.logs, "Unexpected test log output.")
+}
+
+func TestNewLoggerOptions(t *testing.T) {
+	tb := &fakeTB{}
+	logger := NewLogger(tb, zap.WarnLevel)
+	logger.Info("info")
+	logger.Warn("warn")
+	assert.Equal(t, []string{"[W] warn"}, tb.logs, "Expected options to override the default level.")
+}
+
+func TestWriterAfterTestCompletes(t *testing.T) {
+	tb := &fakeTB{}
+	logger := NewLogger(tb)
+	logger.Info("before")
+	tb.finish()
+	assert.NotPanics(t, func() { logger.Info("after") }, "Unexpected panic logging after test completion.")
+	assert.Equal(t, []string{"[I] before"}, tb.logs, "Expected output after test completion to be discarded.")
+}
+
+func TestNewLoggerRealTest(t *testing.T) {
+	// Smoke test against a real *testing.T.
+	NewLogger(t).Info("Logged via t.Log.")
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ztest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/uber-go/zap"
+)
+
+// A StrictLogger is a Logger that fails the test if it writes an entry at or
+// above a threshold level, unless the entry was explicitly allowed. It catches
+// unexpected error logging in tests.
+//
+// Like the loggers returned by NewLogger, StrictLoggers write to the test's
+// log. Child loggers created with With share their parent's threshold and
+// allowed messages.
+//
+// Unexpected entries are recorded as they're written and reported when the
+// test cleans up, so it's safe to log from goroutines that outlive the test;
+// entries written after the test completes are ignored. If t doesn't support
+// Cleanup, failures are reported immediately instead, so the logger must not
+// be used once the test completes.
+type StrictLogger struct {
+	zap.Logger
+
+	allowed *allowlist
+}
+
+// NewStrictLogger constructs a StrictLogger that fails the test when it writes
+// unexpected entries at or above the supplied level. Options are handled as in
+// NewLogger.
+func NewStrictLogger(t testing.TB, threshold zap.Level, options ...zap.Option) *StrictLogger {
+	allowed := &allowlist{}
+	failures := &failures{t: t}
+	if c, ok := t.(cleaner); ok {
+		failures.deferred = true
+		c.Cleanup(failures.report)
+	}
+	hook := zap.Hook(func(e *zap.Entry) error {
+		if e.Level >= threshold && !allowed.matches(e.Message) {
+			failures.add(e.Level, e.Message)
+		}
+		return nil
+	})
+	opts := make([]zap.Option, 0, len(options)+1)
+	opts = append(opts, options...)
+	opts = append(opts, hook)
+	return &StrictLogger{
+		Logger:  NewLogger(t, opts...),
+		allowed: allowed,
+	}
+}
+
+// Allow permits entries whose messages contain the supplied substring. It's
+// safe to call concurrently with logging.
+func (l *StrictLogger) Allow(substr string) {
+	l.allowed.add(substr)
+}
+
+type allowlist struct {
+	sync.RWMutex
+
+	substrs []string
+}
+
+func (a *allowlist) add(substr string) {
+	a.Lock()
+	a.substrs = append(a.substrs, substr)
+	a.Unlock()
+}
+
+func (a *allowlist) matches(msg string) bool {
+	a.RLock()
+	defer a.RUnlock()
+	for _, s := range a.substrs {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// failures collects unexpected entries until the test cleans up.
+type failures struct {
+	sync.Mutex
+
+	t        testing.TB
+	deferred bool
+	done     bool
+	entries  []failure
+}
+
+type failure struct {
+	lvl zap.Level
+	msg string
+}
+
+func (f *failures) add(lvl zap.Level, msg string) {
+	f.Lock()
+	defer f.Unlock()
+	switch {
+	case f.done:
+	case f.deferred:
+		f.entries = append(f.entries, failure{lvl, msg})
+	default:
+		f.t.Errorf("Unexpected %v-level log: %s", lvl, msg)
+	}
+}
+
+func (f *failures) report() {
+	f.Lock()
+	defer f.Unlock()
+	f.done = true
+	for _, e := range f.entries {
+		f.t.Errorf("Unexpected %v-level log: %s", e.lvl, e.msg)
+	}
+	f.entries = nil
+}
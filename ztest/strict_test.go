@@ -0,0 +1,73 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ztest
+
+import (
+	"testing"
+
+	"github.com/uber-go/zap"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictLogger(t *testing.T) {
+	tb := &fakeTB{}
+	logger := NewStrictLogger(tb, zap.ErrorLevel)
+	logger.Allow("expected")
+
+	logger.Warn("just a warning")
+	logger.Error("an expected failure")
+	logger.With(zap.Int("n", 1)).Error("surprise!")
+	logger.Log(zap.PanicLevel, "another surprise")
+	assert.Empty(t, tb.errors, "Expected failures to be reported when the test cleans up.")
+
+	tb.finish()
+	assert.Equal(t, []string{
+		"Unexpected error-level log: surprise!",
+		"Unexpected panic-level log: another surprise",
+	}, tb.errors, "Unexpected test failures.")
+	assert.Equal(t, []string{
+		"[W] just a warning",
+		"[E] an expected failure",
+		"[E] surprise! n=1",
+		"[P] another surprise",
+	}, tb.logs, "Expected all entries to be written to the test log.")
+}
+
+func TestStrictLoggerThreshold(t *testing.T) {
+	tb := &fakeTB{}
+	logger := NewStrictLogger(tb, zap.WarnLevel, zap.InfoLevel)
+	logger.Debug("filtered")
+	logger.Info("fine")
+	logger.Warn("not fine")
+	tb.finish()
+	assert.Equal(t, []string{"Unexpected warn-level log: not fine"}, tb.errors, "Unexpected test failures.")
+	assert.Equal(t, []string{"[I] fine", "[W] not fine"}, tb.logs, "Expected options to apply.")
+}
+
+func TestStrictLoggerAfterCleanup(t *testing.T) {
+	tb := &fakeTB{}
+	logger := NewStrictLogger(tb, zap.ErrorLevel)
+	tb.finish()
+	logger.Error("too late")
+	assert.Empty(t, tb.errors, "Expected failures after the test completes to be ignored.")
+	assert.Empty(t, tb.logs, "Expected no output after the test completes.")
+}
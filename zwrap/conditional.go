@@ -0,0 +1,50 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zwrap
+
+import (
+	"os"
+
+	"github.com/uber-go/zap"
+)
+
+// EnableIf returns the supplied logger if the condition is true, and a logger
+// that discards all output otherwise. It's a convenient way to wire up debug
+// loggers that are only active in some builds: pass a constant defined in a
+// file with the appropriate build tag, and the compiler removes the unused
+// branch. Combined with zap.Tee, it lets a debug output be toggled
+// independently of the main one.
+//
+// Even when disabled, the returned logger's Panic and Fatal methods still
+// panic and exit.
+func EnableIf(enabled bool, l zap.Logger) zap.Logger {
+	if enabled {
+		return l
+	}
+	return zap.New(zap.NullEncoder(), zap.FatalLevel+1, zap.DiscardOutput)
+}
+
+// EnableIfEnv is like EnableIf, but enables the logger only if the named
+// environment variable is set to a non-empty value. The environment is read
+// once, when EnableIfEnv is called.
+func EnableIfEnv(name string, l zap.Logger) zap.Logger {
+	return EnableIf(os.Getenv(name) != "", l)
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zwrap
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/uber-go/zap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBufferedLogger() (zap.Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	return zap.New(zap.NewJSONEncoder(zap.NoTime()), zap.DebugLevel, zap.Output(zap.AddSync(buf))), buf
+}
+
+func TestEnableIf(t *testing.T) {
+	logger, buf := newBufferedLogger()
+	EnableIf(true, logger).Debug("enabled")
+	assert.Equal(t, `{"level":"debug","msg":"enabled"}`+"\n", buf.String(), "Expected enabled logger to write.")
+
+	buf.Reset()
+	disabled := EnableIf(false, logger)
+	disabled.With(zap.Int("n", 1)).Error("disabled")
+	assert.Nil(t, disabled.Check(zap.ErrorLevel, "disabled"), "Expected disabled logger to disable all levels.")
+	assert.Panics(t, func() { disabled.Panic("disabled") }, "Expected disabled logger to still panic.")
+	assert.Empty(t, buf.String(), "Expected disabled logger not to write.")
+}
+
+func TestEnableIfEnv(t *testing.T) {
+	const name = "ZWRAP_TEST_ENABLE_IF_ENV"
+	require.NoError(t, os.Unsetenv(name), "Failed to unset environment variable.")
+	defer os.Unsetenv(name)
+
+	logger, buf := newBufferedLogger()
+	EnableIfEnv(name, logger).Info("disabled")
+	assert.Empty(t, buf.String(), "Expected logger to be disabled without the environment variable.")
+
+	require.NoError(t, os.Setenv(name, "1"), "Failed to set environment variable.")
+	tee := zap.Tee(logger, EnableIfEnv(name, logger))
+	tee.Info("enabled")
+	assert.Equal(t, `{"level":"info","msg":"enabled"}`+"\n"+`{"level":"info","msg":"enabled"}`+"\n", buf.String(),
+		"Expected logger to be enabled with the environment variable.")
+}
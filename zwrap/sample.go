@@ -64,7 +64,8 @@ func (c *counters) Reset(key string) {
 // Sample returns a sampling logger. The logger maintains a separate bucket
 // for each message (e.g., "foo" in logger.Warn("foo")). In each tick, the
 // sampler will emit the first N logs in each bucket and every Mth log
-// therafter. Sampling loggers are safe for concurrent use.
+// therafter (or none, if M is zero or negative). Sampling loggers are safe for
+// concurrent use.
 //
 // Panic and Fatal logging are NOT sampled, and will always call the underlying
 // logger to panic() or terminate the process. HOWEVER Log-ing at PanicLevel or
@@ -75,44 +76,170 @@ func (c *counters) Reset(key string) {
 // applications to more easily control global I/O load.
 func Sample(zl zap.Logger, tick time.Duration, first, thereafter int) zap.Logger {
 	return &sampler{
-		Logger:     zl,
-		tick:       tick,
-		counts:     &counters{counts: make(map[string]*atomic.Uint64)},
-		first:      uint64(first),
-		thereafter: uint64(thereafter),
+		Logger: zl,
+		tick:   tick,
+		all:    newSampleRule(SampleConfig{First: first, Thereafter: thereafter}),
 	}
 }
 
-type sampler struct {
-	zap.Logger
+// SampleWithKeys returns a sampling logger like Sample, but whose buckets are
+// keyed by the message and the values of the named fields, so that, for
+// example, two tenants logging the same message don't share a sampling budget:
+//
+//	sampled := zwrap.SampleWithKeys(logger, time.Second, 100, 100, "tenant")
+//
+// The fields may be added with With or at the log site, which takes
+// precedence. Entries missing a field share a bucket with other entries
+// missing it. Values are compared by their string forms, as in SampleByKey.
+//
+// Each distinct combination of message and field values gets its own counter,
+// and counters are never freed, so memory use grows with the cardinality of
+// the keyed fields. Only key on fields with a bounded set of values, like a
+// tenant or endpoint, never on request or trace IDs.
+func SampleWithKeys(zl zap.Logger, tick time.Duration, first, thereafter int, keys ...string) zap.Logger {
+	return &sampler{
+		Logger: zl,
+		tick:   tick,
+		all:    newSampleRule(SampleConfig{First: first, Thereafter: thereafter}),
+		keys:   keys,
+		values: make([]string, len(keys)),
+	}
+}
+
+// SampleConfig holds the sampling thresholds for a single level: in each
+// tick, the first First logs of each message are emitted, then every
+// Thereafter-th log. If Thereafter is zero or negative, every log after the
+// first First is dropped. A negative First disables sampling for the level,
+// so that every log is emitted; NeverSample is a convenient way to write that.
+type SampleConfig struct {
+	First      int
+	Thereafter int
+}
+
+// NeverSample is a SampleConfig that emits every log. It's useful for
+// exempting levels (e.g., errors) from the default thresholds passed to
+// SampleByLevelWithDefault.
+var NeverSample = SampleConfig{First: -1}
+
+// SampleByLevel returns a sampling logger like Sample, but with separate
+// thresholds for each level. Levels without an entry in the map aren't
+// sampled. Each level keeps its own per-message counts, so a message logged
+// at both Debug and Info is counted separately at each level. DFatal is
+// sampled with the ErrorLevel thresholds.
+func SampleByLevel(zl zap.Logger, tick time.Duration, levels map[zap.Level]SampleConfig) zap.Logger {
+	rules := make(map[zap.Level]*sampleRule, len(levels))
+	for lvl, cfg := range levels {
+		rules[lvl] = newSampleRule(cfg)
+	}
+	return &sampler{
+		Logger: zl,
+		tick:   tick,
+		levels: rules,
+	}
+}
 
-	tick       time.Duration
+// SampleByLevelWithDefault returns a sampling logger like SampleByLevel, but
+// levels from Debug through Error that don't have an entry in the map use the
+// supplied default thresholds (each with its own counts). Use NeverSample to
+// exempt a level from the default:
+//
+//	zwrap.SampleByLevelWithDefault(logger, time.Second, zwrap.SampleConfig{First: 100, Thereafter: 100}, map[zap.Level]zwrap.SampleConfig{
+//		zap.DebugLevel: {First: 10, Thereafter: 1000},
+//		zap.ErrorLevel: zwrap.NeverSample,
+//	})
+//
+// Like the other samplers, it never samples Panic and Fatal logs.
+func SampleByLevelWithDefault(zl zap.Logger, tick time.Duration, def SampleConfig, levels map[zap.Level]SampleConfig) zap.Logger {
+	withDefault := make(map[zap.Level]SampleConfig, len(levels)+int(zap.ErrorLevel-zap.DebugLevel)+1)
+	for lvl := zap.DebugLevel; lvl <= zap.ErrorLevel; lvl++ {
+		withDefault[lvl] = def
+	}
+	for lvl, cfg := range levels {
+		withDefault[lvl] = cfg
+	}
+	return SampleByLevel(zl, tick, withDefault)
+}
+
+type sampleRule struct {
 	counts     *counters
 	first      uint64
-	thereafter uint64
+	thereafter uint64 // zero drops every log after the first
+}
+
+// newSampleRule returns nil if the config disables sampling.
+func newSampleRule(cfg SampleConfig) *sampleRule {
+	if cfg.First < 0 {
+		return nil
+	}
+	rule := &sampleRule{
+		counts: &counters{counts: make(map[string]*atomic.Uint64)},
+		first:  uint64(cfg.First),
+	}
+	if cfg.Thereafter > 0 {
+		rule.thereafter = uint64(cfg.Thereafter)
+	}
+	return rule
+}
+
+type sampler struct {
+	zap.Logger
+
+	tick time.Duration
+	// At most one of all and levels is set: all applies the same rule to
+	// every level, while levels only samples the levels it contains with a
+	// non-nil rule.
+	all    *sampleRule
+	levels map[zap.Level]*sampleRule
+
+	// Fields that join the message in the sampling key, and (in parallel) the
+	// values added with With.
+	keys   []string
+	values []string
 }
 
 func (s *sampler) With(fields ...zap.Field) zap.Logger {
 	return &sampler{
-		Logger:     s.Logger.With(fields...),
-		tick:       s.tick,
-		counts:     s.counts,
-		first:      s.first,
-		thereafter: s.thereafter,
+		Logger: s.Logger.With(fields...),
+		tick:   s.tick,
+		all:    s.all,
+		levels: s.levels,
+		keys:   s.keys,
+		values: s.keyValues(fields),
 	}
 }
 
+// Enabled reports whether the underlying logger writes entries at the level,
+// without spending any sampling budget. It implements zap.LevelEnabler.
+func (s *sampler) Enabled(lvl zap.Level) bool {
+	return levelEnabled(s.Logger, lvl)
+}
+
 func (s *sampler) Check(lvl zap.Level, msg string) *zap.CheckedMessage {
-	cm := s.Logger.Check(lvl, msg)
+	return s.CheckWithFlags(lvl, msg, 0)
+}
+
+// CheckWithFlags is like Check, but entries with the SkipSampling flag aren't
+// sampled. Other flags are passed to the underlying logger.
+func (s *sampler) CheckWithFlags(lvl zap.Level, msg string, flags zap.CheckFlags) *zap.CheckedMessage {
+	cm := zap.CheckWithFlags(s.Logger, lvl, msg, flags)
+	if flags&zap.SkipSampling != 0 {
+		return cm
+	}
 	switch lvl {
 	case zap.PanicLevel, zap.FatalLevel:
 		return cm
-	default:
-		if !cm.OK() || s.sampled(msg) {
-			return cm
+	}
+	if len(s.keys) > 0 {
+		if !cm.OK() {
+			return nil
 		}
-		return nil
+		// Sample in Write, once the log-site fields are known.
+		return zap.NewCheckedMessage(s, lvl, msg)
+	}
+	if !cm.OK() || s.sampled(lvl, msg) {
+		return cm
 	}
+	return nil
 }
 
 func (s *sampler) Log(lvl zap.Level, msg string, fields ...zap.Field) {
@@ -120,49 +247,103 @@ func (s *sampler) Log(lvl zap.Level, msg string, fields ...zap.Field) {
 	case zap.PanicLevel, zap.FatalLevel:
 		s.Logger.Log(lvl, msg, fields...)
 	default:
-		if cm := s.Logger.Check(lvl, msg); cm.OK() && s.sampled(msg) {
+		if cm := s.Logger.Check(lvl, msg); cm.OK() && s.sampled(lvl, s.bucket(msg, fields)) {
 			cm.Write(fields...)
 		}
 	}
 }
 
 func (s *sampler) Debug(msg string, fields ...zap.Field) {
-	if s.Logger.Check(zap.DebugLevel, msg) != nil && s.sampled(msg) {
+	if s.Logger.Check(zap.DebugLevel, msg) != nil && s.sampled(zap.DebugLevel, s.bucket(msg, fields)) {
 		s.Logger.Debug(msg, fields...)
 	}
 }
 
 func (s *sampler) Info(msg string, fields ...zap.Field) {
-	if s.Logger.Check(zap.InfoLevel, msg) != nil && s.sampled(msg) {
+	if s.Logger.Check(zap.InfoLevel, msg) != nil && s.sampled(zap.InfoLevel, s.bucket(msg, fields)) {
 		s.Logger.Info(msg, fields...)
 	}
 }
 
 func (s *sampler) Warn(msg string, fields ...zap.Field) {
-	if s.Logger.Check(zap.WarnLevel, msg) != nil && s.sampled(msg) {
+	if s.Logger.Check(zap.WarnLevel, msg) != nil && s.sampled(zap.WarnLevel, s.bucket(msg, fields)) {
 		s.Logger.Warn(msg, fields...)
 	}
 }
 
 func (s *sampler) Error(msg string, fields ...zap.Field) {
-	if s.Logger.Check(zap.ErrorLevel, msg) != nil && s.sampled(msg) {
+	if s.Logger.Check(zap.ErrorLevel, msg) != nil && s.sampled(zap.ErrorLevel, s.bucket(msg, fields)) {
 		s.Logger.Error(msg, fields...)
 	}
 }
 
 func (s *sampler) DFatal(msg string, fields ...zap.Field) {
-	if s.Logger.Check(zap.ErrorLevel, msg) != nil && s.sampled(msg) {
+	if s.Logger.Check(zap.ErrorLevel, msg) != nil && s.sampled(zap.ErrorLevel, s.bucket(msg, fields)) {
 		s.Logger.DFatal(msg, fields...)
 	}
 }
 
-func (s *sampler) sampled(msg string) bool {
-	n := s.counts.Inc(msg)
-	if n <= s.first {
+// Close closes the underlying logger. See zap.Close for details.
+func (s *sampler) Close() error {
+	return zap.Close(s.Logger)
+}
+
+// keyValues returns the sampler's keyed field values, updated with any values
+// in the supplied fields.
+func (s *sampler) keyValues(fields []zap.Field) []string {
+	if len(s.keys) == 0 {
+		return nil
+	}
+	values := make([]string, len(s.keys))
+	for i, key := range s.keys {
+		values[i] = s.values[i]
+		if v, ok := findKey(fields, key); ok {
+			values[i] = v
+		}
+	}
+	return values
+}
+
+// bucket returns the sampling bucket for an entry: the message, followed by
+// the values of any keyed fields.
+func (s *sampler) bucket(msg string, fields []zap.Field) string {
+	if len(s.keys) == 0 {
+		return msg
+	}
+	buf := []byte(msg)
+	for _, v := range s.keyValues(fields) {
+		buf = append(buf, 0)
+		buf = append(buf, v...)
+	}
+	return string(buf)
+}
+
+func (s *sampler) sampled(lvl zap.Level, bucket string) bool {
+	r := s.all
+	if r == nil {
+		if r = s.levels[lvl]; r == nil {
+			return true
+		}
+	}
+	n := r.counts.Inc(bucket)
+	if n <= r.first {
 		return true
 	}
-	if n == s.first+1 {
-		time.AfterFunc(s.tick, func() { s.counts.Reset(msg) })
+	if n == r.first+1 {
+		time.AfterFunc(s.tick, func() { r.counts.Reset(bucket) })
+	}
+	if r.thereafter == 0 {
+		return false
+	}
+	return (n-r.first)%r.thereafter == 0
+}
+
+// levelEnabled reports whether the logger writes entries at the level, if it's
+// a zap.LevelEnabler. Other loggers are assumed to be enabled, as in zap's
+// level predicates.
+func levelEnabled(log zap.Logger, lvl zap.Level) bool {
+	if le, ok := log.(zap.LevelEnabler); ok {
+		return le.Enabled(lvl)
 	}
-	return (n-s.first)%s.thereafter == 0
+	return true
 }
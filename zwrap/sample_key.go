@@ -0,0 +1,261 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zwrap
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+
+	"github.com/uber-go/zap"
+)
+
+// SampleByKey returns a logger that samples entries by hashing the value of a
+// designated field, like a trace ID. Entries whose values hash below the
+// supplied ratio are kept and the rest are dropped, so the decision is the
+// same for every entry that shares the value: if one entry of a trace is
+// sampled in, all of them are. A ratio of 0.1 keeps roughly one trace in ten.
+//
+// The field may be added with With (the decision is then made once, for the
+// child logger) or at the log site, which takes precedence. Strings, numbers,
+// and booleans are hashed by their string forms; values added with Object are
+// formatted with fmt.Sprint. Entries without the field, or whose field is a
+// nested object, aren't sampled and are always logged.
+//
+// Check can't see the log-site fields, so it only applies the decision made
+// when the field was added with With; checked entries without that decision
+// are always logged. Like Sample, Panic and Fatal logging are never sampled.
+func SampleByKey(zl zap.Logger, key string, ratio float64) zap.Logger {
+	return &keySampler{
+		Logger:    zl,
+		key:       key,
+		threshold: hashThreshold(ratio),
+	}
+}
+
+type keySampler struct {
+	zap.Logger
+
+	key       string
+	threshold uint64
+	// Set if the key was added with With.
+	decided bool
+	keep    bool
+}
+
+func hashThreshold(ratio float64) uint64 {
+	switch {
+	case ratio <= 0:
+		return 0
+	case ratio >= 1:
+		return math.MaxUint64
+	default:
+		// Guard against rounding up to 2^64, which overflows.
+		if t := ratio * math.MaxUint64; t < math.MaxUint64 {
+			return uint64(t)
+		}
+		return math.MaxUint64
+	}
+}
+
+func (s *keySampler) With(fields ...zap.Field) zap.Logger {
+	clone := *s
+	clone.Logger = s.Logger.With(fields...)
+	if keep, ok := s.decide(fields); ok {
+		clone.decided, clone.keep = true, keep
+	}
+	return &clone
+}
+
+// Enabled reports whether the underlying logger writes entries at the level.
+// It implements zap.LevelEnabler.
+func (s *keySampler) Enabled(lvl zap.Level) bool {
+	return levelEnabled(s.Logger, lvl)
+}
+
+func (s *keySampler) Check(lvl zap.Level, msg string) *zap.CheckedMessage {
+	switch lvl {
+	case zap.PanicLevel, zap.FatalLevel:
+		return s.Logger.Check(lvl, msg)
+	}
+	// The log-site fields aren't known yet, so only the decision made by With
+	// applies.
+	if s.decided && !s.keep {
+		return nil
+	}
+	return s.Logger.Check(lvl, msg)
+}
+
+// CheckWithFlags is like Check, but entries with the SkipSampling flag aren't
+// sampled and are checked against the underlying logger with the remaining
+// flags.
+func (s *keySampler) CheckWithFlags(lvl zap.Level, msg string, flags zap.CheckFlags) *zap.CheckedMessage {
+	if flags&zap.SkipSampling != 0 {
+		return zap.CheckWithFlags(s.Logger, lvl, msg, flags)
+	}
+	return s.Check(lvl, msg)
+}
+
+func (s *keySampler) Log(lvl zap.Level, msg string, fields ...zap.Field) {
+	if s.sampled(fields) {
+		s.Logger.Log(lvl, msg, fields...)
+	}
+}
+
+func (s *keySampler) Debug(msg string, fields ...zap.Field) {
+	if s.sampled(fields) {
+		s.Logger.Debug(msg, fields...)
+	}
+}
+
+func (s *keySampler) Info(msg string, fields ...zap.Field) {
+	if s.sampled(fields) {
+		s.Logger.Info(msg, fields...)
+	}
+}
+
+func (s *keySampler) Warn(msg string, fields ...zap.Field) {
+	if s.sampled(fields) {
+		s.Logger.Warn(msg, fields...)
+	}
+}
+
+func (s *keySampler) Error(msg string, fields ...zap.Field) {
+	if s.sampled(fields) {
+		s.Logger.Error(msg, fields...)
+	}
+}
+
+func (s *keySampler) DFatal(msg string, fields ...zap.Field) {
+	if s.sampled(fields) {
+		s.Logger.DFatal(msg, fields...)
+	}
+}
+
+// Close closes the underlying logger. See zap.Close for details.
+func (s *keySampler) Close() error {
+	return zap.Close(s.Logger)
+}
+
+func (s *keySampler) sampled(fields []zap.Field) bool {
+	if keep, ok := s.decide(fields); ok {
+		return keep
+	}
+	return !s.decided || s.keep
+}
+
+// decide reports whether an entry with the supplied fields should be kept,
+// and whether the fields include the sampling key at all.
+func (s *keySampler) decide(fields []zap.Field) (keep bool, ok bool) {
+	value, found := findKey(fields, s.key)
+	if !found {
+		return false, false
+	}
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	sum := mix(h.Sum64())
+	return sum < s.threshold || s.threshold == math.MaxUint64, true
+}
+
+// mix is MurmurHash3's 64-bit finalizer. FNV's high bits are poorly
+// distributed for short, similar inputs (like sequential IDs), so the hash is
+// mixed before being compared to the threshold.
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// findKey returns the string form of the last value added under the key by
+// the supplied fields, and whether there was such a value. Nested objects
+// don't have a string form.
+func findKey(fields []zap.Field, key string) (string, bool) {
+	finder := &keyFinder{key: key}
+	for _, f := range fields {
+		f.AddTo(finder)
+	}
+	return finder.value, finder.found
+}
+
+// keyFinder is a zap.KeyValue that records the string form of the last value
+// added under its key, ignoring all others.
+type keyFinder struct {
+	key   string
+	value string
+	found bool
+}
+
+func (k *keyFinder) set(key, value string) {
+	if key == k.key {
+		k.value, k.found = value, true
+	}
+}
+
+func (k *keyFinder) AddBool(key string, v bool) {
+	if key == k.key {
+		k.set(key, strconv.FormatBool(v))
+	}
+}
+
+func (k *keyFinder) AddFloat64(key string, v float64) {
+	if key == k.key {
+		k.set(key, strconv.FormatFloat(v, 'f', -1, 64))
+	}
+}
+
+func (k *keyFinder) AddInt(key string, v int) { k.AddInt64(key, int64(v)) }
+
+func (k *keyFinder) AddInt64(key string, v int64) {
+	if key == k.key {
+		k.set(key, strconv.FormatInt(v, 10))
+	}
+}
+
+func (k *keyFinder) AddUint(key string, v uint) { k.AddUint64(key, uint64(v)) }
+
+func (k *keyFinder) AddUint64(key string, v uint64) {
+	if key == k.key {
+		k.set(key, strconv.FormatUint(v, 10))
+	}
+}
+
+func (k *keyFinder) AddUintptr(key string, v uintptr) { k.AddUint64(key, uint64(v)) }
+
+func (k *keyFinder) AddString(key, v string) { k.set(key, v) }
+
+func (k *keyFinder) AddObject(key string, v interface{}) error {
+	if key == k.key {
+		k.set(key, fmt.Sprint(v))
+	}
+	return nil
+}
+
+func (k *keyFinder) AddMarshaler(key string, v zap.LogMarshaler) error {
+	// Nested objects aren't hashed, and there's no need to marshal them.
+	if key == k.key {
+		k.value, k.found = "", false
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zwrap
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/uber-go/zap"
+	"github.com/uber-go/zap/spy"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleByKeyConsistent(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	sampler := SampleByKey(base, "trace", 0.5)
+
+	for i := 0; i < 100; i++ {
+		trace := fmt.Sprintf("trace-%d", i)
+		sampler.Info("start", zap.String("trace", trace))
+		child := sampler.With(zap.String("trace", trace))
+		child.Info("middle")
+		if cm := child.Check(zap.InfoLevel, "end"); cm.OK() {
+			cm.Write()
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, log := range sink.Logs() {
+		counts[spy.FieldMap(log.Fields)["trace"].(string)]++
+	}
+	for trace, n := range counts {
+		assert.Equal(t, 3, n, "Expected all entries for %s to be kept together.", trace)
+	}
+	assert.True(t, len(counts) > 30 && len(counts) < 70, "Expected roughly half the traces to be kept, got %d.", len(counts))
+}
+
+func TestSampleByKeyRatios(t *testing.T) {
+	for _, tt := range []struct {
+		ratio    float64
+		expected int
+	}{
+		{0, 0},
+		{-1, 0},
+		{1, 10},
+		{2, 10},
+	} {
+		base, sink := spy.New(zap.DebugLevel)
+		sampler := SampleByKey(base, "trace", tt.ratio)
+		for i := 0; i < 10; i++ {
+			sampler.Info("hello", zap.Int("trace", i))
+		}
+		assert.Equal(t, tt.expected, len(sink.Logs()), "Unexpected number of entries kept with ratio %v.", tt.ratio)
+	}
+}
+
+func TestSampleByKeyUnsampled(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	sampler := SampleByKey(base, "trace", 0)
+
+	sampler.Info("no trace")
+	sampler.Info("nested", zap.Nest("trace", zap.String("id", "abc")))
+	sampler.With(zap.String("trace", "abc")).Panic("panic")
+	assert.Nil(t, sampler.With(zap.String("trace", "abc")).Check(zap.InfoLevel, "dropped"), "Expected Check to respect the With decision.")
+
+	var msgs []string
+	for _, log := range sink.Logs() {
+		msgs = append(msgs, log.Msg)
+	}
+	assert.Equal(t, []string{"no trace", "nested", "panic"}, msgs, "Expected entries without a hashable key and Panic entries to be kept.")
+}
+
+func TestSampleByKeyLogSitePrecedence(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	keepAll := SampleByKey(base, "trace", 1).With(zap.String("trace", "abc"))
+	keepAll.(*keySampler).threshold = 0
+
+	keepAll.Info("dropped by log-site field", zap.String("trace", "abc"))
+	keepAll.Info("kept by With decision")
+	assert.Equal(t, 1, len(sink.Logs()), "Expected log-site fields to take precedence over With.")
+}
+
+func TestSampleByKeyCheckWithFlags(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	dropped := SampleByKey(base, "trace", 0).With(zap.String("trace", "abc"))
+
+	if cm := zap.CheckWithFlags(dropped, zap.InfoLevel, "sampled", 0); cm.OK() {
+		cm.Write()
+	}
+	if cm := zap.CheckWithFlags(dropped, zap.InfoLevel, "kept", zap.SkipSampling); cm.OK() {
+		cm.Write()
+	}
+
+	logs := sink.Logs()
+	if assert.Equal(t, 1, len(logs), "Expected only the SkipSampling entry to be logged.") {
+		assert.Equal(t, "kept", logs[0].Msg, "Unexpected message.")
+	}
+}
+
+func TestSampleByKeyCheckWritesOnce(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	log := SampleByKey(Sample(base, time.Minute, 4, 0), "trace", 1)
+	for i := 0; i < 4; i++ {
+		if cm := log.Check(zap.InfoLevel, "checked"); cm.OK() {
+			cm.Write(zap.Int("trace", i))
+		}
+	}
+	assert.Equal(t, 4, len(sink.Logs()), "Expected the wrapped sampler to be charged once per entry.")
+}
@@ -21,6 +21,7 @@
 package zwrap
 
 import (
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -155,6 +156,137 @@ func TestSamplerTicks(t *testing.T) {
 	assert.Equal(t, expected, sink.Logs(), "Expected sleeping for a tick to reset sampler.")
 }
 
+func TestSampleByLevel(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	sampler := SampleByLevel(base, time.Minute, map[zap.Level]SampleConfig{
+		zap.DebugLevel: {First: 1, Thereafter: 100},
+		zap.InfoLevel:  {First: 2, Thereafter: 3},
+	})
+
+	for i := 1; i <= 6; i++ {
+		WithIter(sampler, i).Debug("sample")
+		WithIter(sampler, i).Info("sample")
+		WithIter(sampler, i).Warn("sample")
+	}
+
+	var debug, info, warn []spy.Log
+	for _, l := range sink.Logs() {
+		switch l.Level {
+		case zap.DebugLevel:
+			debug = append(debug, l)
+		case zap.InfoLevel:
+			info = append(info, l)
+		case zap.WarnLevel:
+			warn = append(warn, l)
+		}
+	}
+	assert.Equal(t, buildExpectation(zap.DebugLevel, 1), debug, "Unexpected debug logs.")
+	assert.Equal(t, buildExpectation(zap.InfoLevel, 1, 2, 5), info, "Unexpected info logs.")
+	assert.Equal(t, buildExpectation(zap.WarnLevel, 1, 2, 3, 4, 5, 6), warn, "Expected unconfigured levels to pass through.")
+}
+
+func TestSampleByLevelWithSharesCounters(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	sampler := SampleByLevel(base, time.Minute, map[zap.Level]SampleConfig{
+		zap.ErrorLevel: {First: 1, Thereafter: 100},
+	})
+
+	WithIter(sampler, 1).Error("sample")
+	WithIter(sampler, 2).DFatal("sample")
+	WithIter(sampler, 3).Log(zap.ErrorLevel, "sample")
+	assert.Equal(t, buildExpectation(zap.ErrorLevel, 1), sink.Logs(), "Expected child loggers and DFatal to share ErrorLevel counters.")
+}
+
+func TestSampleByLevelWithDefault(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	sampler := SampleByLevelWithDefault(base, time.Minute, SampleConfig{First: 2, Thereafter: 3}, map[zap.Level]SampleConfig{
+		zap.DebugLevel: {First: 1, Thereafter: 100},
+		zap.ErrorLevel: NeverSample,
+	})
+
+	for i := 1; i <= 6; i++ {
+		WithIter(sampler, i).Debug("sample")
+		WithIter(sampler, i).Info("sample")
+		WithIter(sampler, i).Warn("sample")
+		WithIter(sampler, i).Error("sample")
+	}
+
+	byLevel := make(map[zap.Level][]spy.Log)
+	for _, l := range sink.Logs() {
+		byLevel[l.Level] = append(byLevel[l.Level], l)
+	}
+	assert.Equal(t, buildExpectation(zap.DebugLevel, 1), byLevel[zap.DebugLevel], "Unexpected debug logs.")
+	assert.Equal(t, buildExpectation(zap.InfoLevel, 1, 2, 5), byLevel[zap.InfoLevel], "Expected info logs to use the default.")
+	assert.Equal(t, buildExpectation(zap.WarnLevel, 1, 2, 5), byLevel[zap.WarnLevel], "Expected warn logs to use the default, with separate counts.")
+	assert.Equal(t, buildExpectation(zap.ErrorLevel, 1, 2, 3, 4, 5, 6), byLevel[zap.ErrorLevel], "Expected error logs never to be sampled.")
+}
+
+func TestSampleByLevelNeverSample(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	sampler := SampleByLevel(base, time.Minute, map[zap.Level]SampleConfig{
+		zap.InfoLevel: NeverSample,
+	})
+	for i := 1; i <= 3; i++ {
+		WithIter(sampler, i).Info("sample")
+	}
+	assert.Equal(t, buildExpectation(zap.InfoLevel, 1, 2, 3), sink.Logs(), "Expected NeverSample to emit every log.")
+}
+
+func TestSampleWithKeys(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	sampler := SampleWithKeys(base, time.Minute, 1, 100, "tenant")
+	acme := sampler.With(zap.String("tenant", "acme"))
+
+	for i := 1; i <= 3; i++ {
+		acme.Info("sample", zap.Int("iter", i))
+		sampler.Info("sample", zap.String("tenant", "globex"), zap.Int("iter", i))
+		sampler.Info("sample", zap.Int("iter", i))
+		// The log-site field takes precedence over the context.
+		acme.Info("sample", zap.String("tenant", "initech"), zap.Int("iter", i))
+	}
+
+	counts := make(map[string]int)
+	for _, log := range sink.Logs() {
+		tenant, _ := log.FieldMap()["tenant"].(string)
+		counts[tenant]++
+	}
+	assert.Equal(t, map[string]int{"acme": 1, "globex": 1, "": 1, "initech": 1}, counts, "Expected each tenant to have its own sampling budget.")
+}
+
+func TestSampleWithKeysCheck(t *testing.T) {
+	base, sink := spy.New(zap.InfoLevel)
+	sampler := SampleWithKeys(base, time.Minute, 1, 100, "tenant")
+
+	assert.Nil(t, sampler.Check(zap.DebugLevel, "sample"), "Expected disabled levels to return nil.")
+	for _, tenant := range []string{"acme", "acme", "globex"} {
+		if cm := sampler.Check(zap.InfoLevel, "sample"); cm.OK() {
+			cm.Write(zap.String("tenant", tenant))
+		}
+	}
+
+	var tenants []string
+	for _, log := range sink.Logs() {
+		tenants = append(tenants, log.FieldMap()["tenant"].(string))
+	}
+	assert.Equal(t, []string{"acme", "globex"}, tenants, "Expected Check to sample using the log-site fields.")
+}
+
+func TestSamplerCheckWithFlags(t *testing.T) {
+	sampler, sink := fakeSampler(zap.DebugLevel, time.Minute, 1, 100, false)
+
+	for i := 1; i <= 3; i++ {
+		if cm := zap.CheckWithFlags(WithIter(sampler, i), zap.InfoLevel, "sample", 0); cm.OK() {
+			cm.Write()
+		}
+	}
+	for i := 4; i <= 5; i++ {
+		if cm := zap.CheckWithFlags(WithIter(sampler, i), zap.InfoLevel, "sample", zap.SkipSampling); cm.OK() {
+			cm.Write()
+		}
+	}
+	assert.Equal(t, buildExpectation(zap.InfoLevel, 1, 4, 5), sink.Logs(), "Expected SkipSampling to bypass the sampler.")
+}
+
 func TestSamplerCheck(t *testing.T) {
 	sampler, sink := fakeSampler(zap.InfoLevel, time.Millisecond, 1, 10, false)
 
@@ -205,3 +337,169 @@ func TestSamplerRaces(t *testing.T) {
 	close(start)
 	wg.Wait()
 }
+
+func TestSampleUnderWithLazy(t *testing.T) {
+	for _, wrap := range []func(zap.Logger) zap.Logger{
+		func(log zap.Logger) zap.Logger { return log.With(zap.String("child", "eager")) },
+		func(log zap.Logger) zap.Logger { return zap.WithLazy(log, zap.String("child", "lazy")) },
+	} {
+		base, sink := spy.New(zap.DebugLevel)
+		child := wrap(Sample(base, time.Minute, 2, 1000))
+		for i := 1; i <= 4; i++ {
+			child.Info("sample")
+		}
+		logs := sink.Logs()
+		if assert.Equal(t, 2, len(logs), "Expected the first two entries to be written.") {
+			assert.Equal(t, logs[0].Fields, logs[1].Fields, "Unexpected fields.")
+		}
+	}
+}
+
+func TestSampleWithoutThereafter(t *testing.T) {
+	for _, thereafter := range []int{0, -1} {
+		base, sink := spy.New(zap.DebugLevel)
+		sampler := SampleByLevel(base, time.Minute, map[zap.Level]SampleConfig{
+			zap.InfoLevel: {First: 1, Thereafter: thereafter},
+		})
+		for i := 1; i <= 3; i++ {
+			WithIter(sampler, i).Info("sample")
+		}
+		assert.Equal(t, buildExpectation(zap.InfoLevel, 1), sink.Logs(), "Expected logs after First to be dropped with Thereafter %v.", thereafter)
+	}
+
+	base, sink := spy.New(zap.DebugLevel)
+	sampler := Sample(base, time.Minute, 2, 0)
+	for i := 1; i <= 4; i++ {
+		WithIter(sampler, i).Info("sample")
+	}
+	assert.Equal(t, buildExpectation(zap.InfoLevel, 1, 2), sink.Logs(), "Expected Sample to drop logs after the first N.")
+}
+
+func TestSampleUnderRouteByFieldCheck(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	log := zap.RouteByField("tenant", nil, Sample(base, time.Minute, 2, 1000))
+	for i := 1; i <= 4; i++ {
+		if cm := log.Check(zap.InfoLevel, "sample"); cm.OK() {
+			cm.Write()
+		}
+	}
+	assert.Equal(t, 2, len(sink.Logs()), "Expected checking a route not to spend its sampling budget.")
+
+	// Level checks mustn't spend any bucket, including the empty message's.
+	base, sink = spy.New(zap.DebugLevel)
+	log = zap.RouteByField("tenant", nil, Sample(base, time.Minute, 1, 0))
+	for i := 0; i < 4; i++ {
+		msg := fmt.Sprint("message ", i)
+		if cm := log.Check(zap.InfoLevel, msg); assert.True(t, cm.OK(), "Expected Check to succeed for %q.", msg) {
+			cm.Write()
+		}
+	}
+	assert.Equal(t, 4, len(sink.Logs()), "Expected one entry per distinct message.")
+}
+
+func TestSamplerEnabled(t *testing.T) {
+	base, sink := spy.New(zap.InfoLevel)
+	sampler := Sample(base, time.Minute, 2, 0)
+	for i := 0; i < 4; i++ {
+		assert.True(t, zap.InfoEnabled(sampler), "Expected Info to be enabled.")
+		assert.False(t, zap.DebugEnabled(sampler), "Expected Debug to be disabled.")
+	}
+	sampler.Info("")
+	sampler.Info("")
+	sampler.Info("")
+	assert.Equal(t, 2, len(sink.Logs()), "Expected level checks not to spend the sampling budget.")
+}
+
+// assertCheckWritesOnce checks that a wrapper writes checked entries through
+// the sampler's CheckedMessage, rather than checking it and logging again, and
+// that its leveled methods also spend the sampling budget once per entry.
+func assertCheckWritesOnce(t *testing.T, desc string, wrap func(zap.Logger) zap.Logger) {
+	base, sink := spy.New(zap.DebugLevel)
+	log := wrap(Sample(base, time.Minute, 4, 0))
+	for i := 0; i < 4; i++ {
+		if cm := log.Check(zap.InfoLevel, "checked"); cm.OK() {
+			cm.Write(zap.Int("i", i))
+		}
+		log.Info("direct", zap.Int("i", i))
+	}
+	assert.Equal(t, 8, len(sink.Logs()), "Expected %s to spend the sampling budget once per entry.", desc)
+}
+
+func TestSampleUnderChainCheck(t *testing.T) {
+	assertCheckWritesOnce(t, "Chain", func(log zap.Logger) zap.Logger { return zap.Chain(log) })
+	assertCheckWritesOnce(t, "Enrich", func(log zap.Logger) zap.Logger {
+		return zap.Enrich(log, "i", func(string) []zap.Field { return nil })
+	})
+}
+
+func TestSampleUnderWhitelistCheck(t *testing.T) {
+	assertCheckWritesOnce(t, "Whitelist", func(log zap.Logger) zap.Logger { return zap.Whitelist(log, "i") })
+}
+
+func TestSampleUnderDedupeCheck(t *testing.T) {
+	assertCheckWritesOnce(t, "Dedupe", func(log zap.Logger) zap.Logger { return zap.Dedupe(log, time.Minute) })
+}
+
+func TestSampleUnderDedupHashCheck(t *testing.T) {
+	assertCheckWritesOnce(t, "WithDedupHash", zap.WithDedupHash)
+}
+
+func TestSampleUnderMessageCounter(t *testing.T) {
+	assertCheckWritesOnce(t, "CountMessages", func(log zap.Logger) zap.Logger { return zap.CountMessages(log, 10) })
+
+	base, _ := spy.New(zap.DebugLevel)
+	counter := zap.CountMessages(Sample(base, time.Minute, 2, 0), 10)
+	for i := 0; i < 4; i++ {
+		counter.Info("sampled")
+		if cm := counter.Check(zap.InfoLevel, "checked"); cm.OK() {
+			cm.Write()
+		}
+	}
+	assert.Equal(t, []zap.MessageCount{
+		{Msg: "checked", Count: 2},
+		{Msg: "sampled", Count: 2},
+	}, counter.TopMessages(-1), "Expected only entries the sampler kept to be counted.")
+}
+
+func TestSampleUnderLogOnce(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	once := zap.LogOnce(Sample(base, time.Millisecond, 4, 0))
+	for i := 0; i < 4; i++ {
+		if cm := once.Check(zap.InfoLevel, "checked"); cm.OK() {
+			cm.Write(zap.Once(fmt.Sprint("checked ", i)))
+		}
+		once.Info("direct", zap.Once(fmt.Sprint("direct ", i)))
+	}
+	assert.Equal(t, 8, len(sink.Logs()), "Expected LogOnce to spend the sampling budget once per entry.")
+
+	// A message dropped by the sampler isn't marked as seen, so it's logged
+	// once the sampler resets.
+	n := len(sink.Logs())
+	once.Info("direct", zap.Once("new"))
+	testutils.Sleep(5 * time.Millisecond)
+	once.Info("direct", zap.Once("new"))
+	assert.Equal(t, n+1, len(sink.Logs()), "Expected a sampled-out message to be logged later.")
+}
+
+func TestSampleUnderBreadcrumbs(t *testing.T) {
+	base, sink := spy.New(zap.DebugLevel)
+	crumbs := zap.Breadcrumbs(Sample(base, time.Minute, 4, 0), zap.InfoLevel, 10)
+	for i := 0; i < 4; i++ {
+		if cm := crumbs.Check(zap.DebugLevel, "held"); cm.OK() {
+			cm.Write(zap.Int("i", i))
+		}
+		if cm := crumbs.Check(zap.ErrorLevel, "failed"); cm.OK() {
+			cm.Write(zap.Int("i", i))
+		}
+	}
+	var msgs []string
+	for _, log := range sink.Logs() {
+		msgs = append(msgs, log.Msg)
+	}
+	assert.Equal(t, []string{
+		"held", "failed",
+		"held", "failed",
+		"held", "failed",
+		"held", "failed",
+	}, msgs, "Expected breadcrumbs to spend the sampling budget once per entry.")
+}
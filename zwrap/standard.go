@@ -48,8 +48,13 @@ type StandardLogger interface {
 
 // Standardize wraps a Logger to make it compatible with the standard library.
 // It takes the Logger itself, and the level to use for the StandardLogger's
-// Print family of methods. If the specified Level isn't Debug, Info, Warn, or
-// Error, Standardize returns ErrInvalidLevel.
+// Print family of methods (typically zap.InfoLevel). If the specified Level
+// isn't Debug, Info, Warn, or Error, Standardize returns ErrInvalidLevel.
+//
+// The wrapped logger is a low-structure escape hatch, intended to ease
+// migration from the standard library's log package: each message is the
+// formatted string, with no structured fields beyond the Logger's existing
+// context. New code should prefer the structured Logger methods.
 func Standardize(l zap.Logger, printAt zap.Level) (StandardLogger, error) {
 	s := stdLogger{
 		panic: l.Panic,